@@ -0,0 +1,175 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Adapter converts a value of one type into a value of another type. It is
+// registered by passing it to Initialize alongside the functions.
+type Adapter struct {
+	fn any
+	// name and location override those of fn, for adapters generated by the
+	// engine
+	name     string
+	location string
+	err      error
+	// converter is set for the adapters registered with Convert
+	converter bool
+}
+
+// Adapt returns an Adapter that lets the engine satisfy inputs of type To from
+// a value of type From. Unlike a function returning To, the adapter only runs
+// when a value of type To is not provided to the run, so To remains a valid
+// provided input.
+//
+// The engine inserts at most one adapter between a value and its consumer:
+// the From type of an adapter must not be the To type of another adapter.
+// Initialize returns an error if To is also produced by a function or by
+// another adapter, as the engine could not tell which value to use.
+func Adapt[From, To any](fn func(From) To) *Adapter {
+	return &Adapter{fn: fn}
+}
+
+// Convert returns an Adapter that lets the engine satisfy inputs of type To
+// from a value of type From with a conversion that may fail, such as parsing.
+// A conversion error fails the run like the error of a function.
+//
+// Unlike adapters registered with Adapt, converters can be chained: a value
+// is converted as many times as needed to reach the type of its consumer.
+// Converters are edges of the dependency graph, so Initialize returns an
+// error if converters form a cycle.
+func Convert[From, To any](fn func(From) (To, error)) *Adapter {
+	return &Adapter{fn: fn, converter: true}
+}
+
+// splitAdapters separates the Adapters from the functions passed to
+// Initialize.
+func splitAdapters(args []any) ([]any, []*Adapter) {
+	var (
+		fns      = make([]any, 0, len(args))
+		adapters []*Adapter
+	)
+	for _, arg := range args {
+		if a, ok := arg.(*Adapter); ok {
+			adapters = append(adapters, a)
+			continue
+		}
+		fns = append(fns, arg)
+	}
+	return fns, adapters
+}
+
+// types returns the unwrapped From and To types of an adapter.
+func (a *Adapter) types() (from reflect.Type, to reflect.Type) {
+	fnT := reflect.TypeOf(a.fn)
+	from, _ = unwrapOptional(fnT.In(0))
+	to, _ = unwrapOptional(fnT.Out(0))
+	return from, to
+}
+
+// ref returns the name used to refer to the adapter.
+func (a *Adapter) ref() string {
+	if a.name != "" {
+		return "adapter " + a.name
+	}
+	return "adapter " + referTo(reflect.ValueOf(a.fn))
+}
+
+// locate returns the source location of the adapter.
+func (a *Adapter) locate() string {
+	if a.location != "" {
+		return a.location
+	}
+	return locate(reflect.ValueOf(a.fn))
+}
+
+func validateAdapterNotNil(a *Adapter) error {
+	if a == nil || a.fn == nil || reflect.ValueOf(a.fn).IsNil() {
+		return errors.New("adapter function must not be nil")
+	}
+	return a.err
+}
+
+// validateAdapters checks that every adapted type has a single source and that
+// adapters other than converters are not chained.
+func validateAdapters(fns []any, adapters []*Adapter) []error {
+	producers := map[reflect.Type][]string{}
+	for _, fn := range fns {
+		fnV := reflect.ValueOf(fn)
+		for _, outT := range outputs(fnV.Type()) {
+			if !isValueType(outT) {
+				continue
+			}
+			outTU, _ := unwrapOptional(outT)
+			producers[outTU] = append(producers[outTU], referTo(fnV))
+		}
+	}
+
+	adapted := map[reflect.Type]*Adapter{}
+	for _, a := range adapters {
+		_, to := a.types()
+		adapted[to] = a
+	}
+
+	var errs []error
+	for _, a := range adapters {
+		from, to := a.types()
+		ref := a.ref()
+		if prev, ok := adapted[from]; ok && !a.converter && !prev.converter {
+			errs = append(errs, invalid(RuleChainedAdapter, "%s adapts type %s which is itself adapted, adapters can not be chained", ref, from))
+		}
+		if len(producers[to]) > 0 {
+			errs = append(errs, invalid(RuleAmbiguousAdapter, "%s is ambiguous, type %s is also provided by %s", ref, to, strings.Join(producers[to], " AND ")))
+		}
+		producers[to] = append(producers[to], ref)
+	}
+
+	return errs
+}
+
+// buildAdapterFunction returns the function running an adapter. The adapter
+// converts the From value once it is resolved unless the To value was provided
+// to the run.
+func buildAdapterFunction(a *Adapter) *function {
+	fnV := reflect.ValueOf(a.fn)
+	errPos := getPosOfType[error](outputs(fnV.Type()))
+
+	fn := &function{
+		name:     a.ref(),
+		location: a.locate(),
+		inputs:   inputs(fnV.Type()),
+		outputs:  outputs(fnV.Type()),
+		call:     caller(fnV),
+	}
+	fn.ins = planInputs(fn.inputs)
+	fn.outs = planOutputs(fn.outputs)
+
+	fn.run = func(ctx context.Context, rs *runState) error {
+		from, to := fn.ins[0], fn.outs[0]
+
+		if _, ok := rs.storage.loadSlot(to.signal); ok {
+			// To was provided, nothing to adapt
+			rs.resolve(fn)
+			rs.record(fn, StatusSkipped, nil)
+			return nil
+		}
+
+		v, ok := loadValue(rs.storage, from)
+		if !ok {
+			return rs.skip(fn, []reflect.Type{from.key})
+		}
+
+		outValues, decision, err := rs.attempt(ctx, fn, append(rs.args(fn), v), errPos)
+		if err != nil {
+			return rs.failed(ctx, fn, err, decision)
+		}
+		rs.store(fn, outValues)
+		rs.resolve(fn)
+		rs.record(fn, StatusSucceeded, nil)
+		return nil
+	}
+	return fn
+}
@@ -0,0 +1,184 @@
+package warp_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_EngineAdapt(t *testing.T) {
+	type (
+		userID    int
+		userKey   string
+		accountID string
+		user      struct{ key userKey }
+	)
+
+	t.Run("should adapt an available value into the type a function needs", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(key userKey) user { return user{key} },
+			Adapt(func(id userID) userKey { return userKey(strconv.Itoa(int(id))) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[user](context.Background(), ngn, userID(42))
+		assert.NoError(t, err)
+		assert.Equal(t, user{"42"}, out)
+	})
+
+	t.Run("should not adapt when the adapted type is provided", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			func(key userKey) user { return user{key} },
+			Adapt(func(id userID) userKey {
+				calls.Add(1)
+				return userKey(strconv.Itoa(int(id)))
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[user](context.Background(), ngn, userKey("<key>"), userID(42))
+		assert.NoError(t, err)
+		assert.Equal(t, user{"<key>"}, out)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("should adapt the output of a function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(id accountID) userID { return userID(len(id)) },
+			func(key userKey) user { return user{key} },
+			Adapt(func(id userID) userKey { return userKey(strconv.Itoa(int(id))) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[user](context.Background(), ngn, accountID("<acc>"))
+		assert.NoError(t, err)
+		assert.Equal(t, user{"5"}, out)
+	})
+
+	t.Run("should return an error if the adapted type is also produced by a function", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(id accountID) userKey { return userKey(id) },
+			Adapt(func(id userID) userKey { return "" }),
+		)
+
+		assertErrContains(t, err, "is ambiguous, type warp_test.userKey is also provided by")
+	})
+
+	t.Run("should return an error if two adapters produce the same type", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(key userKey) user { return user{key} },
+			Adapt(func(id userID) userKey { return "" }),
+			Adapt(func(id accountID) userKey { return "" }),
+		)
+
+		assertErrContains(t, err, "is ambiguous, type warp_test.userKey is also provided by adapter")
+	})
+
+	t.Run("should return an error if adapters are chained", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(key userKey) user { return user{key} },
+			Adapt(func(id userID) userKey { return "" }),
+			Adapt(func(id accountID) userID { return 0 }),
+		)
+
+		assertErrContains(t, err, "adapts type warp_test.userID which is itself adapted, adapters can not be chained")
+	})
+
+	t.Run("should return an error if an adapter is nil", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(key userKey) user { return user{key} },
+			Adapt[userID, userKey](nil),
+		)
+
+		assertErr(t, err, "input validation error: adapter function must not be nil")
+	})
+
+	t.Run("should return an error if an adapter is empty", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(key userKey) user { return user{key} },
+			&Adapter{},
+		)
+
+		assertErr(t, err, "input validation error: adapter function must not be nil")
+	})
+}
+
+func Test_Convert(t *testing.T) {
+	type (
+		rawID   string
+		orderID int
+		order   struct{ id orderID }
+		label   string
+	)
+
+	t.Run("should convert an available value into the type a function needs", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(id orderID) order { return order{id} },
+			Convert(func(raw rawID) (orderID, error) {
+				id, err := strconv.Atoi(string(raw))
+				return orderID(id), err
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[order](context.Background(), ngn, rawID("42"))
+		assert.NoError(t, err)
+		assert.Equal(t, order{42}, out)
+
+		_, err = Run[order](context.Background(), ngn, rawID("<nan>"))
+		assertErr(t, err, `strconv.Atoi: parsing "<nan>": invalid syntax`)
+	})
+
+	t.Run("should chain converters", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(id orderID) order { return order{id} },
+			Convert(func(l label) (rawID, error) { return rawID(strings.TrimPrefix(string(l), "#")), nil }),
+			Convert(func(raw rawID) (orderID, error) {
+				id, err := strconv.Atoi(string(raw))
+				return orderID(id), err
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[order](context.Background(), ngn, label("#42"))
+		assert.NoError(t, err)
+		assert.Equal(t, order{42}, out)
+	})
+
+	t.Run("should return an error if converters form a cycle", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(id orderID) order { return order{id} },
+			Convert(func(raw rawID) (orderID, error) { return 0, nil }),
+			Convert(func(id orderID) (rawID, error) { return "", nil }),
+		)
+		assertErrContains(t, err, "cyclic dependency detected")
+	})
+}
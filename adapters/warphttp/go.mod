@@ -0,0 +1,15 @@
+module github.com/dezlitz/warp/adapters/warphttp
+
+go 1.22.1
+
+require (
+	github.com/dezlitz/warp v0.0.0-20261016102849-9b4bb2412881
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
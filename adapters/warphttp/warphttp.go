@@ -0,0 +1,79 @@
+// Package warphttp serves warp engines over net/http.
+package warphttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/dezlitz/warp"
+)
+
+// StatusClientClosedRequest is the status of the requests whose client went
+// away before the run finished, as logged by nginx. Only the status is
+// written, for the access logs and the metrics of the server, as nobody reads
+// the response.
+const StatusClientClosedRequest = 499
+
+// Handler returns an http.Handler running the engine for Out once per request,
+// with the value decoded from the request by decode as the provided input and
+// the context of the request as the context of the run. The output of the run
+// is written to the response by encode.
+//
+// A request that decode fails on is answered with 400 Bad Request and the
+// error of decode. A failed run, or an encode returning an error before
+// writing to the response, is answered with 500 Internal Server Error, without
+// the error, which may hold details of the engine. Once encode has written to
+// the response, its error is left unanswered, as the response is already
+// under way. A run cancelled because the client went away is answered with
+// StatusClientClosedRequest.
+func Handler[In, Out any](
+	e *warp.Engine,
+	decode func(*http.Request) (In, error),
+	encode func(http.ResponseWriter, Out) error,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in, err := decode(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		out, err := warp.Run[Out](r.Context(), e, in)
+		if err != nil {
+			if errors.Is(err, context.Canceled) && errors.Is(r.Context().Err(), context.Canceled) {
+				w.WriteHeader(StatusClientClosedRequest)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		rw := &responseWriter{ResponseWriter: w}
+		if err := encode(rw, out); err != nil && !rw.wrote {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	})
+}
+
+// responseWriter records whether the response was started.
+type responseWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, so that an
+// http.ResponseController can reach its optional interfaces, such as
+// http.Flusher.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
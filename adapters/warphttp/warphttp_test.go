@@ -0,0 +1,110 @@
+package warphttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dezlitz/warp"
+	"github.com/dezlitz/warp/adapters/warphttp"
+)
+
+type (
+	name     string
+	greeting string
+)
+
+func Test_Handler(t *testing.T) {
+	ngn, err := warp.Initialize(func(n name) (greeting, error) {
+		if n == "" {
+			return "", errors.New("<no name>")
+		}
+		return greeting("hello " + n), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(r *http.Request) (name, error) {
+		if err := r.ParseForm(); err != nil {
+			return "", err
+		}
+		if !r.Form.Has("name") {
+			return "", errors.New("name is required")
+		}
+		return name(r.Form.Get("name")), nil
+	}
+	encode := func(w http.ResponseWriter, g greeting) error {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(map[string]string{"greeting": string(g)})
+	}
+	handler := warphttp.Handler(ngn, decode, encode)
+
+	t.Run("should write the output of the run", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=gopher", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"greeting":"hello gopher"}`, rec.Body.String())
+	})
+
+	t.Run("should answer a request that can not be decoded with a bad request", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "name is required", strings.TrimSpace(rec.Body.String()))
+	})
+
+	t.Run("should answer a failed run with an internal server error", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "<no name>")
+	})
+	t.Run("should not answer the error of an encode that wrote to the response", func(t *testing.T) {
+		t.Parallel()
+		handler := warphttp.Handler(ngn, decode, func(w http.ResponseWriter, g greeting) error {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(g))
+			return errors.New("<encode error>")
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=gopher", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "hello gopher", rec.Body.String())
+	})
+
+	t.Run("should answer the error of an encode that did not write with an internal server error", func(t *testing.T) {
+		t.Parallel()
+		handler := warphttp.Handler(ngn, decode, func(w http.ResponseWriter, g greeting) error {
+			return errors.New("<encode error>")
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=gopher", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "<encode error>")
+	})
+
+	t.Run("should not answer a run cancelled by the client as an internal server error", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=gopher", nil).WithContext(ctx))
+
+		assert.Equal(t, warphttp.StatusClientClosedRequest, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+}
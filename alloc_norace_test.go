@@ -0,0 +1,5 @@
+//go:build !race
+
+package warp_test
+
+const raceEnabled = false
@@ -0,0 +1,31 @@
+package warp
+
+import "reflect"
+
+// Typed is a value provided to a run with an explicit type, see As.
+type Typed struct {
+	typ reflect.Type
+	v   reflect.Value
+}
+
+// As provides v to a run as a value of type T. Provided values are otherwise
+// stored under their dynamic type, so As is the way to provide the value of
+// an interface input:
+//
+//	warp.Run[Receipt](ctx, e, warp.As[io.Writer](os.Stdout))
+func As[T any](v T) Typed {
+	return Typed{typ: reflect.TypeOf((*T)(nil)).Elem(), v: reflect.ValueOf(&v).Elem()}
+}
+
+// Type returns the type the value is provided as.
+func (t Typed) Type() reflect.Type {
+	return t.typ
+}
+
+// providedValue returns the type and the value of a provided input.
+func providedValue(in any) (reflect.Type, reflect.Value) {
+	if tv, ok := in.(Typed); ok {
+		return tv.typ, tv.v
+	}
+	return reflect.TypeOf(in), reflect.ValueOf(in)
+}
@@ -0,0 +1,39 @@
+package warp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_As(t *testing.T) {
+	type greeting string
+
+	ngn, err := Initialize(
+		func(s fmt.Stringer) greeting { return greeting("hello " + s.String()) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should provide the value as an interface input", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[greeting](context.Background(), ngn, As[fmt.Stringer](stringer("world")))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello world"), out)
+	})
+
+	t.Run("should store values not wrapped by As under their dynamic type", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[greeting](context.Background(), ngn, stringer("world"), WithRequireTarget())
+		assertErrContains(t, err, "fmt.Stringer")
+	})
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
@@ -0,0 +1,118 @@
+package warp
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WithMutationAudit makes every run deep hash the values it stores, as
+// WithImmutabilityCheck does, and hash the values a function was called with
+// again as soon as it returns, to name the function that mutated a value in
+// place rather than every function consuming it. The run does not fail: the
+// mutations are logged as warnings with logger, if not nil, and listed in
+// Report.Mutations.
+//
+// A consumer running at the same time as the function named can be the actual
+// culprit. Hashing is expensive: the audit is meant for tests, debug builds
+// and canaries, not production.
+func WithMutationAudit(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.mutationAudit = &mutationAudit{logger: logger}
+	}
+}
+
+// Mutation describes a stored value mutated during a run, see
+// WithMutationAudit.
+type Mutation struct {
+	// Type is the type the value is stored under.
+	Type reflect.Type
+	// Producer refers to the function that produced the value, or is
+	// "provided input".
+	Producer string
+	// MutatedBy refers to the function found to have mutated the value once
+	// it returned. It is empty if the value changed after every function
+	// consuming it had returned, as from a goroutine started by one of them.
+	MutatedBy string
+}
+
+// mutationAudit is the configuration of WithMutationAudit.
+type mutationAudit struct {
+	logger *slog.Logger
+}
+
+// runAudit collects the mutations of a run.
+type runAudit struct {
+	logger    *slog.Logger
+	mu        sync.Mutex
+	mutations []Mutation
+}
+
+func (a *mutationAudit) newRun() *runAudit {
+	if a == nil {
+		return nil
+	}
+	return &runAudit{logger: a.logger}
+}
+
+// audit records the mutations of the values fn was called with, once it has
+// returned. A run that is not audited records nothing.
+func (rs *runState) audit(fn *function) {
+	if rs.mutations == nil {
+		return
+	}
+	for _, in := range fn.ins {
+		if in.injected() || in.lazy {
+			continue
+		}
+		if producer, ok := rs.hashes.changed(in.key); ok {
+			rs.mutations.add(Mutation{Type: in.key, Producer: producer, MutatedBy: fn.name})
+		}
+	}
+}
+
+func (a *runAudit) add(m Mutation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mutations = append(a.mutations, m)
+}
+
+// finish records the mutations not attributed to a function yet, logs every
+// mutation of the run and records them in report. A run that is not audited
+// records nothing.
+func (a *runAudit) finish(ctx context.Context, hashes *valueHashes, report *runReport) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	unattributed := hashes.unattributed()
+	slices.SortFunc(unattributed, func(a, b Mutation) int {
+		return strings.Compare(a.Type.String(), b.Type.String())
+	})
+	a.mutations = append(a.mutations, unattributed...)
+	if a.logger != nil {
+		for _, m := range a.mutations {
+			a.logger.WarnContext(ctx, "warp: stored value mutated during the run",
+				slog.String("type", m.Type.String()),
+				slog.String("producer", m.Producer),
+				slog.String("mutated_by", m.MutatedBy),
+			)
+		}
+	}
+	report.recordMutations(a.mutations)
+}
+
+// recordMutations records the mutations of the run. A nil report records
+// nothing.
+func (r *runReport) recordMutations(mutations []Mutation) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutations = slices.Clone(mutations)
+}
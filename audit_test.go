@@ -0,0 +1,124 @@
+package warp_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithMutationAudit(t *testing.T) {
+	type (
+		ids     []int
+		total   int
+		sorted  []int
+		done    struct{}
+		release chan struct{}
+	)
+
+	t.Run("names the function that mutated a value without failing the run", func(t *testing.T) {
+		t.Parallel()
+		var logs bytes.Buffer
+		ngn, err := Initialize(
+			func(in ids) total {
+				var sum int
+				for _, id := range in {
+					sum += id
+				}
+				return total(sum)
+			},
+			func(in ids, _ total) sorted {
+				in[0], in[1] = in[1], in[0]
+				return sorted(in)
+			},
+			WithMutationAudit(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[sorted](context.Background(), ngn, ids{2, 1}, WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, sorted{1, 2}, out)
+		if assert.Len(t, report.Mutations, 1) {
+			m := report.Mutations[0]
+			assert.Equal(t, reflect.TypeFor[ids](), m.Type)
+			assert.Equal(t, "provided input", m.Producer)
+			assert.Equal(t, report.Functions[1].Name, m.MutatedBy)
+		}
+		assert.Contains(t, logs.String(), `msg="warp: stored value mutated during the run" type=warp_test.ids producer="provided input" mutated_by=`)
+	})
+
+	t.Run("reports the values mutated after their consumers returned", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in ids, r release) total {
+				go func() {
+					<-r
+					in[0] = 0
+					close(r)
+				}()
+				return 1
+			},
+			func(_ total, r release) done {
+				r <- struct{}{}
+				<-r
+				return done{}
+			},
+			WithMutationAudit(nil),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[done](context.Background(), ngn, ids{2, 1}, make(release), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, []Mutation{{Type: reflect.TypeFor[ids](), Producer: "provided input"}}, report.Mutations)
+	})
+
+	t.Run("still fails the run under WithImmutabilityCheck", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in ids) sorted {
+				in[0] = 0
+				return sorted(in)
+			},
+			WithMutationAudit(nil),
+			WithImmutabilityCheck(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[sorted](context.Background(), ngn, ids{2, 1}, WithReport(&report))
+		assertErrContains(t, err, "stored values were mutated during the run: warp_test.ids produced by provided input")
+		assert.Len(t, report.Mutations, 1)
+	})
+
+	t.Run("reports nothing if no value is mutated", func(t *testing.T) {
+		t.Parallel()
+		var logs strings.Builder
+		ngn, err := Initialize(
+			func(in ids) sorted { return append(sorted(nil), in...) },
+			WithMutationAudit(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[sorted](context.Background(), ngn, ids{2, 1}, WithReport(&report))
+		assert.NoError(t, err)
+		assert.Empty(t, report.Mutations)
+		assert.Empty(t, logs.String())
+	})
+}
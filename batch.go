@@ -0,0 +1,71 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// BatchOption configures RunBatch.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	workers int
+}
+
+// WithWorkers sets the maximum number of input sets RunBatch runs at the same
+// time. It defaults to GOMAXPROCS.
+func WithWorkers(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.workers = n
+	}
+}
+
+// RunBatch runs the engine once for every input set and returns the output of
+// type T of each run, in the same order as the input sets. Input sets are run
+// concurrently by a bounded pool of workers, see WithWorkers, each running
+// its share of the input sets one after the other. The runs share the
+// execution plan compiled by Initialize and reuse the state of the previous
+// runs, as Run does.
+//
+// If any run returns an error, the remaining runs are cancelled and the error
+// is returned, annotated with the position of the failing input set.
+func RunBatch[T any](ctx context.Context, e *Engine, inputs [][]any, opts ...BatchOption) ([]T, error) {
+	cfg := batchConfig{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.workers < 1 {
+		return nil, fmt.Errorf("batch workers must be positive, got %d", cfg.workers)
+	}
+
+	out := make([]T, len(inputs))
+	eg, ctx := errgroup.WithContext(ctx)
+	var next atomic.Int64
+	for range min(cfg.workers, len(inputs)) {
+		eg.Go(func() error {
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(inputs) {
+					return nil
+				}
+				if err := ctx.Err(); err != nil {
+					return fmt.Errorf("batch input %d: %w", i, err)
+				}
+				v, err := Run[T](ctx, e, inputs[i]...)
+				if err != nil {
+					return fmt.Errorf("batch input %d: %w", i, err)
+				}
+				out[i] = v
+			}
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return out, err
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunBatch(t *testing.T) {
+	type (
+		in  int
+		out int
+	)
+
+	t.Run("should return the outputs in input order", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) out { return out(i * 2) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		outs, err := RunBatch[out](context.Background(), ngn, [][]any{{in(1)}, {in(2)}, {in(3)}})
+		assert.NoError(t, err)
+		assert.Equal(t, []out{2, 4, 6}, outs)
+	})
+
+	t.Run("should not run more input sets concurrently than workers", func(t *testing.T) {
+		t.Parallel()
+		var running, peak atomic.Int32
+		ngn, err := Initialize(
+			func(i in) out {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return out(i)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inputs := make([][]any, 10)
+		for i := range inputs {
+			inputs[i] = []any{in(i)}
+		}
+		_, err = RunBatch[out](context.Background(), ngn, inputs, WithWorkers(2))
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, peak.Load(), int32(2))
+	})
+
+	t.Run("should return the error of a failed input set", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) (out, error) {
+				if i == 1 {
+					return 0, errors.New("<odd-error>")
+				}
+				return out(i), nil
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RunBatch[out](context.Background(), ngn, [][]any{{in(0)}, {in(1)}}, WithWorkers(1))
+		assertErr(t, err, "batch input 1: <odd-error>")
+	})
+
+	t.Run("should return an error if workers is not positive", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) out { return out(i) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RunBatch[out](context.Background(), ngn, nil, WithWorkers(0))
+		assertErr(t, err, "batch workers must be positive, got 0")
+	})
+}
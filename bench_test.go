@@ -0,0 +1,108 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	. "github.com/dezlitz/warp"
+)
+
+func BenchmarkRun(b *testing.B) {
+	type (
+		in   int
+		out1 int
+		out2 int
+		out3 int
+		out4 int
+	)
+
+	ngn, err := Initialize(
+		func(i in) out1 { return out1(i) },
+		func(o out1) out2 { return out2(o) },
+		func(o out1, i Optional[in]) out3 { return out3(o) },
+		func(ctx context.Context, o2 out2, o3 out3) (out4, error) { return out4(o2) + out4(o3), nil },
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Run[out4](ctx, ngn, in(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRunWide runs a wide graph, where a function completing makes many
+// functions runnable at once and a single function waits on all of them.
+func BenchmarkRunWide(b *testing.B) {
+	type (
+		in  int
+		out int
+	)
+	const width = 64
+
+	inT, outT := reflect.TypeOf(in(0)), reflect.TypeOf(out(0))
+	funcs := make([]any, 0, width+1)
+	sinkIns := make([]reflect.Type, 0, width)
+	for i := 1; i <= width; i++ {
+		leafT := reflect.ArrayOf(i, reflect.TypeOf(0))
+		sinkIns = append(sinkIns, leafT)
+		funcs = append(funcs, reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{inT}, []reflect.Type{leafT}, false),
+			func([]reflect.Value) []reflect.Value { return []reflect.Value{reflect.New(leafT).Elem()} },
+		).Interface())
+	}
+	funcs = append(funcs, reflect.MakeFunc(
+		reflect.FuncOf(sinkIns, []reflect.Type{outT}, false),
+		func(args []reflect.Value) []reflect.Value { return []reflect.Value{reflect.ValueOf(out(len(args)))} },
+	).Interface())
+
+	ngn, err := Initialize(funcs...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Run[out](ctx, ngn, in(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRunBatch(b *testing.B) {
+	type (
+		in   int
+		out1 int
+		out2 int
+	)
+
+	ngn, err := Initialize(
+		func(i in) out1 { return out1(i) },
+		func(o out1) out2 { return out2(o) },
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	inputs := make([][]any, 100)
+	for i := range inputs {
+		inputs[i] = []any{in(i)}
+	}
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := RunBatch[out2](ctx, ngn, inputs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package warp
+
+// BestEffort marks fn as non-critical: when it returns an error, the run
+// continues as if it had returned ErrSkip, without its outputs. The function
+// is reported as failed with its error, and as tolerated. Errors caused by the
+// cancellation of the run are not tolerated.
+//
+// The name Optional is taken by the Optional input type, hence BestEffort.
+func BestEffort(fn any) *Provider {
+	p := asProvider(fn)
+	p.bestEffort = true
+	return p
+}
@@ -0,0 +1,81 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_BestEffort(t *testing.T) {
+	type (
+		query   string
+		primary []string
+		extra   []string
+		results []string
+	)
+	search := func(q query) primary { return primary{"primary " + string(q)} }
+	merge := func(p primary, e Optional[extra]) results { return results(append(p, e.Val...)) }
+
+	t.Run("should continue the run without the outputs of the failed function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			search,
+			BestEffort(func(q query) (extra, error) { return nil, errors.New("<unavailable>") }),
+			merge,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[results](context.Background(), ngn, query("<q>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, results{"primary <q>"}, out)
+
+		assert.Equal(t, StatusFailed, report.Functions[1].Status)
+		assertErr(t, report.Functions[1].Err, "<unavailable>")
+		assert.True(t, report.Functions[1].Tolerated)
+		assert.False(t, report.Functions[0].Tolerated)
+	})
+
+	t.Run("should use the outputs of the function when it succeeds", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			search,
+			BestEffort(func(q query) (extra, error) { return extra{"extra " + string(q)}, nil }),
+			merge,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[results](context.Background(), ngn, query("<q>"))
+		assert.NoError(t, err)
+		assert.Equal(t, results{"primary <q>", "extra <q>"}, out)
+	})
+
+	t.Run("should not tolerate the cancellation of the run", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			search,
+			BestEffort(func(ctx context.Context, q query) (extra, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}),
+			merge,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = Run[results](ctx, ngn, query("<q>"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
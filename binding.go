@@ -0,0 +1,144 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// WithInterfaceBinding lets the engine satisfy an interface input that no
+// function produces from the output of the single function producing a type
+// implementing the interface, as if an adapter converting the one into the
+// other had been registered. A function accepting an io.Reader is then fed
+// the *bytes.Buffer produced by another function.
+//
+// Initialize returns an error naming the candidates and their providers if
+// several outputs implement the same interface input: registering a Bind
+// then chooses which one to use. Interface values provided to a
+// run with As take precedence over the bound outputs.
+func WithInterfaceBinding() Option {
+	return func(c *config) {
+		c.interfaceBinding = true
+	}
+}
+
+// bindInterfaces returns the adapters binding the interface inputs of fns and
+// adapters produced by neither to the single output of fns implementing them,
+// and an error per ambiguous interface input.
+func bindInterfaces(fns []any, adapters []*Adapter) ([]*Adapter, []error) {
+	type (
+		output struct {
+			typ      reflect.Type
+			producer reflect.Value
+		}
+		input struct {
+			typ      reflect.Type
+			consumer string
+		}
+	)
+	var (
+		produced = map[reflect.Type]bool{}
+		outs     []output
+		ins      []input
+	)
+	for _, fn := range fns {
+		fnV := reflect.ValueOf(fn)
+		for _, outT := range outputs(fnV.Type()) {
+			if !isValueType(outT) {
+				continue
+			}
+			outTU, _ := unwrapOptional(outT)
+			produced[outTU] = true
+			outs = append(outs, output{typ: outTU, producer: fnV})
+		}
+		for _, inT := range inputs(fnV.Type()) {
+			ins = append(ins, input{typ: inT, consumer: referTo(fnV)})
+		}
+	}
+	for _, a := range adapters {
+		from, to := a.types()
+		produced[to] = true
+		ins = append(ins, input{typ: from, consumer: a.ref()})
+	}
+
+	var (
+		out   []*Adapter
+		errs  []error
+		bound = map[reflect.Type]bool{}
+	)
+	for _, in := range ins {
+		inTU, _ := unwrapOptional(in.typ)
+		if inTU.Kind() != reflect.Interface || isType[context.Context](inTU) || produced[inTU] || bound[inTU] {
+			continue
+		}
+		bound[inTU] = true
+
+		var candidates []output
+		for _, o := range outs {
+			if o.typ.Implements(inTU) {
+				candidates = append(candidates, o)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			out = append(out, bindAdapter(inTU, candidates[0].typ, locate(candidates[0].producer)))
+		default:
+			names := make([]string, len(candidates))
+			for i, c := range candidates {
+				names[i] = fmt.Sprintf("%s provided by %s", c.typ, referTo(c.producer))
+			}
+			errs = append(errs, invalid(RuleAmbiguousBinding,
+				"interface input type %s of %s is ambiguous, it is implemented by %s: register warp.Bind[%s, %s]() to choose one",
+				inTU, in.consumer, strings.Join(names, " AND "), inTU, candidates[0].typ,
+			))
+		}
+	}
+	return out, errs
+}
+
+// Bind returns an Adapter declaring that the value of the concrete type T, as
+// produced by a function or provided to a run, also satisfies the inputs of
+// the interface type I. It gives precise control over the bindings, without
+// WithInterfaceBinding or to choose between the outputs implementing the same
+// interface:
+//
+//	warp.Initialize(fns, warp.Bind[io.Reader, *bytes.Buffer]())
+//
+// Initialize returns an error if I is not an interface type or if T does not
+// implement it.
+func Bind[I, T any]() *Adapter {
+	ifaceT, implT := reflect.TypeOf((*I)(nil)).Elem(), reflect.TypeOf((*T)(nil)).Elem()
+	if ifaceT.Kind() != reflect.Interface {
+		return &Adapter{fn: func(T) (i I) { return i }, err: fmt.Errorf("binding type %s is not an interface type", ifaceT)}
+	}
+
+	var location string
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = fmt.Sprintf("%s:%d", file, line)
+	}
+	a := bindAdapter(ifaceT, implT, location)
+	if !implT.Implements(ifaceT) {
+		a.err = fmt.Errorf("binding type %s does not implement %s", implT, ifaceT)
+	}
+	return a
+}
+
+// bindAdapter returns the adapter converting values of type implT into values
+// of the interface type ifaceT, located at the producer of implT.
+func bindAdapter(ifaceT, implT reflect.Type, location string) *Adapter {
+	fnT := reflect.FuncOf([]reflect.Type{implT}, []reflect.Type{ifaceT}, false)
+	fnV := reflect.MakeFunc(fnT, func(in []reflect.Value) []reflect.Value {
+		out := reflect.New(ifaceT).Elem()
+		out.Set(in[0])
+		return []reflect.Value{out}
+	})
+	return &Adapter{
+		fn:       fnV.Interface(),
+		name:     fmt.Sprintf("binding %s as %s", implT, ifaceT),
+		location: location,
+	}
+}
@@ -0,0 +1,157 @@
+package warp_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithInterfaceBinding(t *testing.T) {
+	type (
+		body    string
+		content string
+	)
+	produceBuffer := func(b body) *bytes.Buffer { return bytes.NewBufferString(string(b)) }
+	readAll := func(r io.Reader) (content, error) {
+		data, err := io.ReadAll(r)
+		return content(data), err
+	}
+
+	t.Run("should satisfy an interface input from the output implementing it", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(produceBuffer, readAll, WithInterfaceBinding())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[content](context.Background(), ngn, body("<body>"))
+		assert.NoError(t, err)
+		assert.Equal(t, content("<body>"), out)
+	})
+
+	t.Run("should satisfy an optional interface input", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			produceBuffer,
+			func(r Optional[io.Reader]) (content, error) {
+				data, err := io.ReadAll(r.Val)
+				return content(data), err
+			},
+			WithInterfaceBinding(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[content](context.Background(), ngn, body("<body>"))
+		assert.NoError(t, err)
+		assert.Equal(t, content("<body>"), out)
+	})
+
+	t.Run("should prefer the interface value provided to the run", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(produceBuffer, readAll, WithInterfaceBinding())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[content](context.Background(), ngn, body("<body>"), As[io.Reader](strings.NewReader("<provided>")))
+		assert.NoError(t, err)
+		assert.Equal(t, content("<provided>"), out)
+	})
+
+	t.Run("should not bind interface inputs by default", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(produceBuffer, readAll)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[content](context.Background(), ngn, body("<body>"), WithRequireTarget())
+		assertErrContains(t, err, "can not run: input io.Reader is not available")
+	})
+
+	t.Run("should return a validation error if several outputs implement the interface", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			produceBuffer,
+			func(b body) *strings.Reader { return strings.NewReader(string(b)) },
+			readAll,
+			WithInterfaceBinding(),
+		)
+		assertErrContains(t, err, "interface input type io.Reader of github.com/dezlitz/warp_test.Test_WithInterfaceBinding.func2(io.Reader) (warp_test.content, error) is ambiguous, "+
+			"it is implemented by *bytes.Buffer provided by github.com/dezlitz/warp_test.Test_WithInterfaceBinding.func1(warp_test.body) *bytes.Buffer "+
+			"AND *strings.Reader provided by github.com/dezlitz/warp_test.Test_WithInterfaceBinding.func7.1(warp_test.body) *strings.Reader: "+
+			"register warp.Bind[io.Reader, *bytes.Buffer]() to choose one")
+	})
+
+	t.Run("should resolve the ambiguity with a binding", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			produceBuffer,
+			func(b body) *strings.Reader { return strings.NewReader("<reader>") },
+			readAll,
+			Bind[io.Reader, *strings.Reader](),
+			WithInterfaceBinding(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[content](context.Background(), ngn, body("<body>"))
+		assert.NoError(t, err)
+		assert.Equal(t, content("<reader>"), out)
+	})
+}
+
+func Test_Bind(t *testing.T) {
+	type (
+		body    string
+		content string
+	)
+
+	t.Run("should satisfy the interface input from the bound type", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(b body) *bytes.Buffer { return bytes.NewBufferString(string(b)) },
+			func(r io.Reader) (content, error) {
+				data, err := io.ReadAll(r)
+				return content(data), err
+			},
+			Bind[io.Reader, *bytes.Buffer](),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[content](context.Background(), ngn, body("<body>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, content("<body>"), out)
+		assert.Equal(t, "adapter binding *bytes.Buffer as io.Reader", report.Functions[2].Name)
+	})
+
+	t.Run("should return a validation error if the type does not implement the interface", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(b body) content { return content(b) },
+			Bind[io.Reader, content](),
+		)
+		assertErrContains(t, err, "binding type warp_test.content does not implement io.Reader")
+	})
+
+	t.Run("should return a validation error if the bound type is not an interface", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(b body) content { return content(b) },
+			Bind[content, body](),
+		)
+		assertErrContains(t, err, "binding type warp_test.content is not an interface type")
+	})
+}
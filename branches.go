@@ -0,0 +1,193 @@
+package warp
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Branch declares a branch gated by a branching function, see Branched.
+type Branch struct {
+	typ      reflect.Type
+	optional reflect.Type
+}
+
+// BranchOf declares the branch made of the functions consuming values of type
+// T.
+func BranchOf[T any]() Branch {
+	return Branch{typ: reflect.TypeOf((*T)(nil)).Elem(), optional: reflect.TypeOf(Optional[T]{})}
+}
+
+// Branches collects the decisions of a branching function: every declared
+// branch must be either set, with SetBranch, or explicitly unset, with
+// UnsetBranch, before the function returns. Branches is not safe for
+// concurrent use.
+type Branches struct {
+	// values holds the value of every set branch
+	values     map[reflect.Type]reflect.Value
+	decided    map[reflect.Type]bool
+	undeclared []reflect.Type
+}
+
+// SetBranch sets the branch of type T to v: the functions consuming T run.
+func SetBranch[T any](b *Branches, v T) {
+	b.decide(reflect.TypeOf((*T)(nil)).Elem(), reflect.ValueOf(&v).Elem())
+}
+
+// UnsetBranch explicitly unsets the branch of type T: the functions requiring
+// T are skipped.
+func UnsetBranch[T any](b *Branches) {
+	b.decide(reflect.TypeOf((*T)(nil)).Elem(), reflect.Value{})
+}
+
+func (b *Branches) decide(t reflect.Type, v reflect.Value) {
+	if _, ok := b.decided[t]; !ok {
+		b.undeclared = append(b.undeclared, t)
+		return
+	}
+	b.decided[t] = true
+	if v.IsValid() {
+		b.values[t] = v
+	} else {
+		delete(b.values, t)
+	}
+}
+
+// validate returns an error if a declared branch was not decided or if an
+// undeclared branch was.
+func (b *Branches) validate(name string, branches []Branch) error {
+	if len(b.undeclared) > 0 {
+		return fmt.Errorf("branching function %s decided undeclared branches %s", name, joinTypes(b.undeclared))
+	}
+	var undecided []reflect.Type
+	for _, br := range branches {
+		if !b.decided[br.typ] {
+			undecided = append(undecided, br.typ)
+		}
+	}
+	if len(undecided) > 0 {
+		return fmt.Errorf("branching function %s neither set nor unset branches %s", name, joinTypes(undecided))
+	}
+	return nil
+}
+
+var branchesT = reflect.TypeOf((*Branches)(nil))
+
+// Branched registers a function gating several branches of the graph. fn must
+// accept a *Branches parameter and decide every declared branch on it before
+// returning. The engine sees fn as a function returning, after its own
+// outputs, an Optional[T] per branch of type T, so the functions of a branch
+// only run if it was set.
+//
+// The run fails if fn returns without deciding a branch or decides a branch
+// it did not declare, which catches the branches forgotten by functions gating
+// many of them. Run reports list the branches left unset in
+// FunctionReport.Unset.
+func Branched(fn any, branches ...Branch) *Provider {
+	fnV := reflect.ValueOf(fn)
+
+	p := &Provider{fn: fn}
+	if fn == nil || fnV.Kind() != reflect.Func {
+		p.err = errors.New("branching input must be a function")
+		return p
+	}
+	p.name, p.location = referTo(fnV), locate(fnV)
+
+	fnT := fnV.Type()
+	branchesPos := -1
+	for i, inT := range inputs(fnT) {
+		if inT == branchesT {
+			branchesPos = i
+		}
+	}
+	if branchesPos == -1 {
+		p.err = fmt.Errorf("branching function %s must accept a %s parameter", p.name, branchesT)
+		return p
+	}
+	if len(branches) == 0 {
+		p.err = fmt.Errorf("branching function %s must declare at least one branch", p.name)
+		return p
+	}
+	declared := map[reflect.Type]bool{}
+	for _, br := range branches {
+		if br.typ == nil {
+			p.err = fmt.Errorf("branching function %s declares an empty branch, use BranchOf", p.name)
+			return p
+		}
+		if declared[br.typ] {
+			p.err = fmt.Errorf("branching function %s declares branch %s twice", p.name, br.typ)
+			return p
+		}
+		declared[br.typ] = true
+	}
+
+	// The engine sees a function taking the other parameters of fn and
+	// returning the outputs of fn, an Optional per branch and an error
+	var viewIns, viewOuts []reflect.Type
+	for i, inT := range inputs(fnT) {
+		if i != branchesPos {
+			viewIns = append(viewIns, inT)
+		}
+	}
+	outs := outputs(fnT)
+	errPos := getPosOfType[error](outs)
+	for i, outT := range outs {
+		if i != errPos {
+			viewOuts = append(viewOuts, outT)
+		}
+	}
+	for _, br := range branches {
+		viewOuts = append(viewOuts, br.optional)
+	}
+	errT := reflect.TypeOf((*error)(nil)).Elem()
+	view := reflect.FuncOf(viewIns, append(viewOuts, errT), false)
+
+	p.fn = reflect.MakeFunc(view, func(args []reflect.Value) []reflect.Value {
+		b := &Branches{
+			values:  make(map[reflect.Type]reflect.Value, len(branches)),
+			decided: make(map[reflect.Type]bool, len(branches)),
+		}
+		for _, br := range branches {
+			b.decided[br.typ] = false
+		}
+
+		callArgs := make([]reflect.Value, 0, fnT.NumIn())
+		callArgs = append(callArgs, args[:branchesPos]...)
+		callArgs = append(callArgs, reflect.ValueOf(b))
+		callArgs = append(callArgs, args[branchesPos:]...)
+		outValues := fnV.Call(callArgs)
+
+		err := getError(outValues, errPos)
+		if err == nil {
+			err = b.validate(p.name, branches)
+		}
+
+		results := make([]reflect.Value, 0, len(viewOuts)+1)
+		for i, v := range outValues {
+			if i != errPos {
+				results = append(results, v)
+			}
+		}
+		for _, br := range branches {
+			v, ok := b.values[br.typ]
+			if ok && err == nil {
+				results = append(results, newOptional(br.optional, v))
+			} else {
+				results = append(results, reflect.Zero(br.optional))
+			}
+		}
+		errV := reflect.Zero(errT)
+		if err != nil {
+			errV = reflect.ValueOf(&err).Elem()
+		}
+		return append(results, errV)
+	}).Interface()
+
+	return p
+}
+
+// joinTypes lists types in an error message.
+func joinTypes(types []reflect.Type) string {
+	return strings.Join(sliceConvert(reflect.Type.String, types), ", ")
+}
@@ -0,0 +1,117 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Branched(t *testing.T) {
+	type (
+		order    string
+		express  string
+		standard string
+		pickup   string
+		label    string
+		summary  string
+	)
+
+	route := func(o order, b *Branches) (label, error) {
+		switch o {
+		case "<fail>":
+			return "", errors.New("<route-error>")
+		case "<forget>":
+			SetBranch(b, express(o))
+		case "<undeclared>":
+			SetBranch(b, express(o))
+			UnsetBranch[standard](b)
+			UnsetBranch[pickup](b)
+			UnsetBranch[summary](b)
+		default:
+			SetBranch(b, express(o))
+			UnsetBranch[standard](b)
+		}
+		return label(o), nil
+	}
+	fns := []any{
+		Branched(route, BranchOf[express](), BranchOf[standard]()),
+		func(l label, e Optional[express], s Optional[standard]) summary {
+			return summary(string(l) + "|" + string(e.Val) + "|" + string(s.Val))
+		},
+	}
+
+	t.Run("should gate the branches on the decisions of the function", func(t *testing.T) {
+		t.Parallel()
+		var ran []string
+		ngn, err := Initialize(append(fns,
+			func(e express) pickup { ran = append(ran, "express"); return pickup(e) },
+		)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[summary](context.Background(), ngn, order("<order>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, summary("<order>|<order>|"), out)
+		assert.Equal(t, []string{"express"}, ran)
+		if assert.Len(t, report.Functions, 3) {
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(standard(""))}, report.Functions[0].Unset)
+		}
+		assert.NotContains(t, report.Produced(), reflect.TypeOf(standard("")))
+		assert.Contains(t, report.Produced(), reflect.TypeOf(express("")))
+	})
+
+	t.Run("should fail if a branch is not decided", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(fns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[summary](context.Background(), ngn, order("<forget>"))
+		assertErrContains(t, err, "neither set nor unset branches warp_test.standard")
+	})
+
+	t.Run("should fail if an undeclared branch is decided", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(fns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[summary](context.Background(), ngn, order("<undeclared>"))
+		assertErrContains(t, err, "decided undeclared branches warp_test.pickup, warp_test.summary")
+	})
+
+	t.Run("should return the error of the function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(fns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[summary](context.Background(), ngn, order("<fail>"))
+		assertErr(t, err, "<route-error>")
+	})
+
+	t.Run("should return validation errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Branched(func(o order) label { return label(o) }, BranchOf[express]()))
+		assertErrContains(t, err, "must accept a *warp.Branches parameter")
+
+		_, err = Initialize(Branched(route))
+		assertErrContains(t, err, "must declare at least one branch")
+
+		_, err = Initialize(Branched(route, BranchOf[express](), BranchOf[express]()))
+		assertErrContains(t, err, "declares branch warp_test.express twice")
+
+		_, err = Initialize(Branched(nil))
+		assertErrContains(t, err, "branching input must be a function")
+	})
+}
@@ -0,0 +1,33 @@
+package warp
+
+import "sync/atomic"
+
+// WithMaxFailures aborts the run once more than n failures have been
+// tolerated, by BestEffort functions or by the OnError handler, so that best
+// effort graphs, such as a fan-out to many data sources, still fail when too
+// few sources answer. The failure exceeding the budget fails the run.
+func WithMaxFailures(n int) RunOption {
+	return func(c *runConfig) {
+		c.maxFailures = &n
+	}
+}
+
+// failureBudget counts the tolerated failures of a run. A nil budget is
+// unlimited.
+type failureBudget struct {
+	max      int
+	failures atomic.Int32
+}
+
+func newFailureBudget(max *int) *failureBudget {
+	if max == nil {
+		return nil
+	}
+	return &failureBudget{max: *max}
+}
+
+// exceeded counts a tolerated failure and reports whether the budget is now
+// exceeded.
+func (b *failureBudget) exceeded() bool {
+	return b != nil && int(b.failures.Add(1)) > b.max
+}
@@ -0,0 +1,77 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithMaxFailures(t *testing.T) {
+	type (
+		query   string
+		sourceA string
+		sourceB string
+		sourceC string
+		merged  []string
+	)
+	source := func(name string, fail bool) func(query) (string, error) {
+		return func(q query) (string, error) {
+			if fail {
+				return "", errors.New("<" + name + " unavailable>")
+			}
+			return name, nil
+		}
+	}
+	initialize := func(t *testing.T, failA, failB, failC bool) *Engine {
+		a, b, c := source("a", failA), source("b", failB), source("c", failC)
+		ngn, err := Initialize(
+			BestEffort(func(q query) (sourceA, error) { s, err := a(q); return sourceA(s), err }),
+			BestEffort(func(q query) (sourceB, error) { s, err := b(q); return sourceB(s), err }),
+			BestEffort(func(q query) (sourceC, error) { s, err := c(q); return sourceC(s), err }),
+			func(a Optional[sourceA], b Optional[sourceB], c Optional[sourceC]) merged {
+				var out merged
+				if a.IsSet {
+					out = append(out, string(a.Val))
+				}
+				if b.IsSet {
+					out = append(out, string(b.Val))
+				}
+				if c.IsSet {
+					out = append(out, string(c.Val))
+				}
+				return out
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	t.Run("should tolerate failures within the budget", func(t *testing.T) {
+		t.Parallel()
+		ngn := initialize(t, true, false, false)
+		out, err := Run[merged](context.Background(), ngn, query("<q>"), WithMaxFailures(1))
+		assert.NoError(t, err)
+		assert.Equal(t, merged{"b", "c"}, out)
+	})
+
+	t.Run("should abort the run once the budget is exceeded", func(t *testing.T) {
+		t.Parallel()
+		ngn := initialize(t, true, true, false)
+		_, err := Run[merged](context.Background(), ngn, query("<q>"), WithMaxFailures(1))
+		assertErrContains(t, err, "more than 1 tolerated failures: <")
+	})
+
+	t.Run("should tolerate every failure without a budget", func(t *testing.T) {
+		t.Parallel()
+		ngn := initialize(t, true, true, true)
+		out, err := Run[merged](context.Background(), ngn, query("<q>"))
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+	})
+}
@@ -0,0 +1,156 @@
+package warp
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Capabilities describes the features the types and functions of an engine
+// support. Generic adapters, such as HTTP handlers, queue consumers or
+// schedulers, check them up front to fail with a clear error instead of
+// failing on the first run.
+type Capabilities struct {
+	// Streaming holds the types of the streamed outputs, such as the Pages of
+	// paginated functions, sorted by name.
+	Streaming []reflect.Type
+	// Encodable holds the value types that can be encoded, with the codec
+	// registered with WithCodec or with JSONCodec, sorted by name. Only
+	// values of these types are kept in checkpoints.
+	Encodable []reflect.Type
+	// Remote holds the names of the functions whose inputs and outputs can
+	// all be encoded and that return no Cleanup, so that they could run in
+	// another process, in registration order.
+	Remote []string
+}
+
+// CanEncode reports whether values of type t can be encoded. Optional types are
+// unwrapped.
+func (c Capabilities) CanEncode(t reflect.Type) bool {
+	t, _ = unwrapOptional(t)
+	return slices.Contains(c.Encodable, t)
+}
+
+// RequireEncodable returns an error naming the types that can not be encoded.
+func (c Capabilities) RequireEncodable(types ...reflect.Type) error {
+	var missing []reflect.Type
+	for _, t := range types {
+		if !c.CanEncode(t) {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("engine has no codec for types %s", joinTypes(missing))
+	}
+	return nil
+}
+
+// Capabilities returns the capabilities of the engine.
+func (e *Engine) Capabilities() Capabilities {
+	var c Capabilities
+	if e == nil {
+		return c
+	}
+
+	seen := map[reflect.Type]bool{}
+	for _, fn := range e.funcs {
+		remote := getPosOfType[Cleanup](fn.outputs) == -1
+		for _, in := range fn.ins {
+			if in.injected() {
+				continue
+			}
+			remote = remote && !in.lazy && e.encodable(in.key)
+			seen[in.key] = true
+		}
+		for _, o := range fn.outs {
+			if o.key.Implements(reflect.TypeOf((*awaiter)(nil)).Elem()) && !slices.Contains(c.Streaming, o.key) {
+				c.Streaming = append(c.Streaming, o.key)
+			}
+			remote = remote && e.encodable(o.key)
+			seen[o.key] = true
+		}
+		if remote {
+			c.Remote = append(c.Remote, fn.name)
+		}
+	}
+
+	for t := range seen {
+		if e.encodable(t) {
+			c.Encodable = append(c.Encodable, t)
+		}
+	}
+	byName := func(a, b reflect.Type) int { return strings.Compare(typeName(a), typeName(b)) }
+	slices.SortFunc(c.Encodable, byName)
+	slices.SortFunc(c.Streaming, byName)
+	return c
+}
+
+// encodable reports whether values of type t can be encoded by the codecs of
+// the engine.
+func (e *Engine) encodable(t reflect.Type) bool {
+	if _, ok := e.codecs[t]; ok {
+		return true
+	}
+	return jsonEncodable(t, map[reflect.Type]bool{})
+}
+
+var (
+	jsonMarshalerT   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerT = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerT   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerT = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// jsonEncodable reports whether values of type t survive a round trip through
+// JSONCodec. visiting holds the types being checked, to stop on recursive
+// types.
+func jsonEncodable(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return true
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	ptrT := reflect.PointerTo(t)
+	if (t.Implements(jsonMarshalerT) && ptrT.Implements(jsonUnmarshalerT)) ||
+		(t.Implements(textMarshalerT) && ptrT.Implements(textUnmarshalerT)) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return jsonEncodable(t.Elem(), visiting)
+	case reflect.Map:
+		key := t.Key()
+		keyOK := key.Kind() == reflect.String || (key.Kind() >= reflect.Int && key.Kind() <= reflect.Uintptr) ||
+			(key.Implements(textMarshalerT) && reflect.PointerTo(key).Implements(textUnmarshalerT))
+		return keyOK && jsonEncodable(t.Elem(), visiting)
+	case reflect.Struct:
+		exported := 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Tag.Get("json") == "-" {
+				continue
+			}
+			if !jsonEncodable(f.Type, visiting) {
+				return false
+			}
+			exported++
+		}
+		// A struct without exported fields encodes as {} and loses its
+		// state, unless it is empty
+		return exported > 0 || t.NumField() == 0
+	default:
+		// Channels, functions, complex numbers, unsafe pointers and
+		// interfaces, which can not be decoded into their dynamic type
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Capabilities(t *testing.T) {
+	type (
+		in      string
+		conn    struct{ ch chan int }
+		events  struct{ Ch chan int }
+		secret  struct{ value string }
+		stamp   time.Time
+		summary struct {
+			Count int
+			At    time.Time
+		}
+		page   []int
+		cursor int
+	)
+
+	ngn, err := Initialize(
+		func(i in) (conn, Cleanup) { return conn{}, func(context.Context) error { return nil } },
+		func(i in) (events, secret) { return events{}, secret{} },
+		func(i in, s secret) summary { return summary{} },
+		func(i in) stamp { return stamp{} },
+		Paginate[page, cursor](func(i in, c Optional[cursor]) (page, Optional[cursor]) {
+			return nil, Optional[cursor]{}
+		}),
+		WithCodec[secret](JSONCodec),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caps := ngn.Capabilities()
+
+	t.Run("should report the streamed types", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, []reflect.Type{reflect.TypeOf(Pages[page]{})}, caps.Streaming)
+	})
+
+	t.Run("should report the encodable types", func(t *testing.T) {
+		t.Parallel()
+		assert.ElementsMatch(t, []reflect.Type{
+			reflect.TypeOf(in("")),
+			reflect.TypeOf(secret{}),
+			reflect.TypeOf(summary{}),
+		}, caps.Encodable)
+		assert.True(t, caps.CanEncode(reflect.TypeOf(Optional[in]{})))
+		assert.NoError(t, caps.RequireEncodable(reflect.TypeOf(in("")), reflect.TypeOf(summary{})))
+		assertErr(t, caps.RequireEncodable(reflect.TypeOf(in("")), reflect.TypeOf(conn{}), reflect.TypeOf(events{}), reflect.TypeOf(stamp{})),
+			"engine has no codec for types warp_test.conn, warp_test.events, warp_test.stamp")
+	})
+
+	t.Run("should report the functions that could run remotely", func(t *testing.T) {
+		t.Parallel()
+		if assert.Len(t, caps.Remote, 1) {
+			assert.Contains(t, caps.Remote[0], "(warp_test.in, warp_test.secret) warp_test.summary")
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package warp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Checkpoint is the state of a run: the values provided to it and the values
+// its functions produced, encoded with the codecs of the engine. A Checkpoint
+// marshals to JSON, so a run interrupted on one host, for instance by a
+// preemption or a deploy, can be resumed on another with Resume.
+type Checkpoint struct {
+	// Fingerprint identifies the engine the run was checkpointed on, see
+	// Engine.Fingerprint.
+	Fingerprint string `json:"fingerprint"`
+	// Target is the name of the type produced by the run.
+	Target string `json:"target"`
+	// Values holds the values of the run, sorted by type name.
+	Values []CheckpointValue `json:"values"`
+}
+
+// CheckpointValue is a value of a checkpointed run.
+type CheckpointValue struct {
+	// Type is the name of the type of the value, without any Optional
+	// wrapper.
+	Type string `json:"type"`
+	// Unset is true for an Optional value left unset, which has no data.
+	Unset bool `json:"unset,omitempty"`
+	// Data is the encoded value.
+	Data []byte `json:"data,omitempty"`
+}
+
+// WithCheckpoint fills cp with the checkpoint of the run once it has finished,
+// whether it succeeded or not. Values that can not be encoded, such as Pages,
+// are left out: their producers run again when the run is resumed. See
+// Capabilities.Encodable.
+func WithCheckpoint(cp *Checkpoint) RunOption {
+	return func(c *runConfig) {
+		c.checkpoint = cp
+	}
+}
+
+// withRestored stores the values restored from a checkpoint before the run
+// starts.
+func withRestored(values map[reflect.Type]reflect.Value) RunOption {
+	return func(c *runConfig) {
+		c.restored = values
+	}
+}
+
+// Resume resumes the run checkpointed in cp and returns its output of type T.
+// The functions whose outputs are all in the checkpoint do not run again, the
+// others run as in Run. Resume is typically called by a different process
+// than the one that took the checkpoint, with an engine initialized with the
+// same functions.
+//
+// An error is returned if the engine does not have the fingerprint of the
+// engine the checkpoint was taken on, if the checkpointed run did not produce
+// T, or if a value can not be decoded.
+func Resume[T any](ctx context.Context, e *Engine, cp Checkpoint, opts ...RunOption) (T, error) {
+	var out T
+	if e == nil || !e.initialized {
+		return out, categorize(ErrNotInitialized, "error resuming engine that has not been initialized")
+	}
+	if cp.Fingerprint != e.fingerprint {
+		return out, fmt.Errorf("error resuming checkpoint taken on a different engine: checkpoint fingerprint %s, engine fingerprint %s", cp.Fingerprint, e.fingerprint)
+	}
+	if target := typeName(reflect.TypeOf((*T)(nil)).Elem()); cp.Target != target {
+		return out, fmt.Errorf("error resuming checkpoint of a run producing %s as a run producing %s", cp.Target, target)
+	}
+
+	values, err := e.restore(cp)
+	if err != nil {
+		return out, err
+	}
+
+	args := make([]any, 0, len(opts)+1)
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	return run[T](ctx, e, e.schedule, append(args, withRestored(values)))
+}
+
+// Fingerprint identifies the functions of the engine and their signatures. Two
+// engines initialized with the same functions, in the same order, by the same
+// build have the same fingerprint.
+func (e *Engine) Fingerprint() string {
+	return e.fingerprint
+}
+
+// fingerprint returns the fingerprint of funcs.
+func fingerprint(funcs []*function) string {
+	h := sha256.New()
+	for _, fn := range funcs {
+		h.Write([]byte(fn.name))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// checkpoint encodes the values in storage.
+func (e *Engine) checkpoint(storage *valueStore, target reflect.Type) Checkpoint {
+	cp := Checkpoint{Fingerprint: e.fingerprint, Target: typeName(target)}
+	storage.Range(func(t reflect.Type, v reflect.Value) bool {
+		if !e.encodable(t) {
+			return true
+		}
+
+		cv := CheckpointValue{Type: typeName(t)}
+		if isOptional(v.Type()) {
+			if !v.FieldByName("IsSet").Bool() {
+				cv.Unset = true
+				cp.Values = append(cp.Values, cv)
+				return true
+			}
+			v = v.FieldByName("Val")
+		}
+		data, err := e.codecs.get(t).Encode(v.Interface())
+		if err != nil {
+			return true
+		}
+		cv.Data = data
+		cp.Values = append(cp.Values, cv)
+		return true
+	})
+	slices.SortFunc(cp.Values, func(a, b CheckpointValue) int {
+		return strings.Compare(a.Type, b.Type)
+	})
+	return cp
+}
+
+// restore decodes the values of cp.
+func (e *Engine) restore(cp Checkpoint) (map[reflect.Type]reflect.Value, error) {
+	out := make(map[reflect.Type]reflect.Value, len(cp.Values))
+	for _, cv := range cp.Values {
+		t, ok := e.types[cv.Type]
+		if !ok || t == nil {
+			return nil, fmt.Errorf("error resuming checkpoint: value of unknown type %s", cv.Type)
+		}
+		if cv.Unset {
+			if optT, ok := e.optionals[t]; ok {
+				out[t] = reflect.Zero(optT)
+			}
+			continue
+		}
+		ptr := reflect.New(t)
+		if err := e.codecs.get(t).Decode(cv.Data, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("error resuming checkpoint: decoding %s: %w", cv.Type, err)
+		}
+		out[t] = ptr.Elem()
+	}
+	return out, nil
+}
+
+// indexTypes maps the names of the value types consumed or produced by funcs to
+// the types, and the types to their Optional wrapper when funcs use one. Names
+// shared by several types, such as those of types local to different
+// functions, map to nil.
+func indexTypes(funcs []*function) (map[string]reflect.Type, map[reflect.Type]reflect.Type) {
+	var (
+		types     = map[string]reflect.Type{}
+		optionals = map[reflect.Type]reflect.Type{}
+	)
+	for _, fn := range funcs {
+		for _, t := range append(append([]reflect.Type(nil), fn.inputs...), fn.outputs...) {
+			if !isValueType(t) || isType[context.Context](t) || isType[Invoker](t) {
+				continue
+			}
+			key, ok := unwrapOptional(t)
+			if ok {
+				optionals[key] = t
+			}
+			name := typeName(key)
+			if known, ok := types[name]; ok && known != key {
+				types[name] = nil
+				continue
+			}
+			types[name] = key
+		}
+	}
+	return types, optionals
+}
+
+// typeName returns the name of t qualified by its full package path.
+func typeName(t reflect.Type) string {
+	if t.PkgPath() != "" && t.Name() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
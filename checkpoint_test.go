@@ -0,0 +1,140 @@
+package warp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Encode(v any) ([]byte, error) {
+	return []byte(strings.ToUpper(string(v.(checkpointUser)))), nil
+}
+
+func (upperCodec) Decode(data []byte, v any) error {
+	*v.(*checkpointUser) = checkpointUser(data)
+	return nil
+}
+
+type (
+	checkpointIn      string
+	checkpointUser    string
+	checkpointOrders  string
+	checkpointGated   string
+	checkpointSummary string
+)
+
+func Test_Checkpoint(t *testing.T) {
+	type engine struct {
+		*Engine
+		userCalls atomic.Int32
+		fail      atomic.Bool
+	}
+	newEngine := func(t *testing.T, opts ...any) *engine {
+		e := &engine{}
+		ngn, err := Initialize(append([]any{
+			func(i checkpointIn) (checkpointUser, Optional[checkpointGated]) {
+				e.userCalls.Add(1)
+				return checkpointUser(i), Optional[checkpointGated]{}
+			},
+			func(u checkpointUser) (checkpointOrders, error) {
+				if e.fail.Load() {
+					return "", errors.New("<preempted>")
+				}
+				return checkpointOrders(u + "<orders>"), nil
+			},
+			func(o checkpointOrders, g Optional[checkpointGated]) checkpointSummary {
+				return checkpointSummary(o) + checkpointSummary(g.Val)
+			},
+		}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Engine = ngn
+		return e
+	}
+
+	t.Run("should resume a checkpointed run on another engine", func(t *testing.T) {
+		t.Parallel()
+		first := newEngine(t)
+		first.fail.Store(true)
+		var cp Checkpoint
+		_, err := Run[checkpointSummary](context.Background(), first.Engine, checkpointIn("<in>"), WithCheckpoint(&cp))
+		assertErr(t, err, "<preempted>")
+
+		data, err := json.Marshal(cp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var restored Checkpoint
+		if err := json.Unmarshal(data, &restored); err != nil {
+			t.Fatal(err)
+		}
+
+		second := newEngine(t)
+		assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+		var report Report
+		out, err := Resume[checkpointSummary](context.Background(), second.Engine, restored, WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, checkpointSummary("<in><orders>"), out)
+		assert.Equal(t, int32(0), second.userCalls.Load())
+		if assert.Len(t, report.Functions, 3) {
+			assert.Equal(t, StatusSkipped, report.Functions[0].Status)
+			assert.Equal(t, StatusSucceeded, report.Functions[1].Status)
+		}
+	})
+
+	t.Run("should encode values with the registered codecs", func(t *testing.T) {
+		t.Parallel()
+		ngn := newEngine(t, WithCodec[checkpointUser](upperCodec{}))
+		var cp Checkpoint
+		_, err := Run[checkpointSummary](context.Background(), ngn.Engine, checkpointIn("<in>"), WithCheckpoint(&cp))
+		assert.NoError(t, err)
+
+		assert.Equal(t, []CheckpointValue{
+			{Type: "github.com/dezlitz/warp_test.checkpointGated", Unset: true},
+			{Type: "github.com/dezlitz/warp_test.checkpointIn", Data: []byte(`"\u003cin\u003e"`)},
+			{Type: "github.com/dezlitz/warp_test.checkpointOrders", Data: []byte(`"\u003cin\u003e\u003corders\u003e"`)},
+			{Type: "github.com/dezlitz/warp_test.checkpointSummary", Data: []byte(`"\u003cin\u003e\u003corders\u003e"`)},
+			{Type: "github.com/dezlitz/warp_test.checkpointUser", Data: []byte("<IN>")},
+		}, cp.Values)
+
+		out, err := Resume[checkpointSummary](context.Background(), ngn.Engine, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, checkpointSummary("<in><orders>"), out)
+	})
+
+	t.Run("should return an error for a checkpoint of another engine", func(t *testing.T) {
+		t.Parallel()
+		ngn := newEngine(t)
+		other, err := Initialize(func(i checkpointIn) checkpointSummary { return checkpointSummary(i) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cp Checkpoint
+		_, err = Run[checkpointSummary](context.Background(), other, checkpointIn("<in>"), WithCheckpoint(&cp))
+		assert.NoError(t, err)
+
+		_, err = Resume[checkpointSummary](context.Background(), ngn.Engine, cp)
+		assertErrContains(t, err, "error resuming checkpoint taken on a different engine")
+	})
+
+	t.Run("should return an error for a checkpoint of another target", func(t *testing.T) {
+		t.Parallel()
+		ngn := newEngine(t)
+		var cp Checkpoint
+		_, err := Run[checkpointOrders](context.Background(), ngn.Engine, checkpointIn("<in>"), WithCheckpoint(&cp))
+		assert.NoError(t, err)
+
+		_, err = Resume[checkpointSummary](context.Background(), ngn.Engine, cp)
+		assertErr(t, err, "error resuming checkpoint of a run producing github.com/dezlitz/warp_test.checkpointOrders as a run producing github.com/dezlitz/warp_test.checkpointSummary")
+	})
+}
@@ -0,0 +1,57 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Cleanup is a function that releases resources acquired by an engine
+// function. A function may return a Cleanup alongside its outputs; the engine
+// calls it once the run has finished, whether or not the run succeeded.
+//
+// Cleanups are invoked in reverse dependency order, so the cleanup of a
+// function always runs before the cleanups of the functions it depends on.
+// A Cleanup is only registered when the function that returned it succeeded
+// and the returned value is not nil.
+type Cleanup func(context.Context) error
+
+// cleanups collects the Cleanup values returned during a single run in the
+// order the functions completed.
+type cleanups struct {
+	mu  sync.Mutex
+	fns []Cleanup
+}
+
+func (c *cleanups) add(fn Cleanup) {
+	if fn == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fns = append(c.fns, fn)
+}
+
+// run invokes the collected cleanups in reverse completion order. A function
+// only starts once all its inputs are stored, so reversing the completion
+// order always tears down dependants before their dependencies. All cleanups
+// are invoked even if some of them fail; the errors are joined. They are
+// invoked with ctx stripped of its cancellation, as the run is over.
+func (c *cleanups) run(ctx context.Context) error {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+	if len(fns) == 0 {
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,122 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_EngineCleanup(t *testing.T) {
+	type (
+		conn   struct{ name string }
+		tx     struct{ conn conn }
+		result string
+		dsn    string
+	)
+
+	t.Run("should invoke cleanups in reverse dependency order after the run", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mu    sync.Mutex
+			order []string
+		)
+		record := func(name string) Cleanup {
+			return func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				return nil
+			}
+		}
+
+		ngn, err := Initialize(
+			func(tx tx) (result, error) { return result(tx.conn.name), nil },
+			func(in dsn) (conn, Cleanup, error) { return conn{string(in)}, record("conn"), nil },
+			func(c conn) (tx, Cleanup) { return tx{c}, record("tx") },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := Run[result](ctx, ngn, dsn("db"))
+		assert.NoError(t, err)
+		assert.Equal(t, result("db"), out)
+		assert.Equal(t, []string{"tx", "conn"}, order)
+	})
+
+	t.Run("should invoke cleanups of completed functions when the run fails", func(t *testing.T) {
+		t.Parallel()
+		var called bool
+		ngn, err := Initialize(
+			func(in dsn) (conn, Cleanup) {
+				return conn{string(in)}, func(context.Context) error {
+					called = true
+					return nil
+				}
+			},
+			func(conn) (tx, Cleanup, error) {
+				return tx{}, func(context.Context) error {
+					t.Error("cleanup of a failed function must not be invoked")
+					return nil
+				}, errors.New("<begin-error>")
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[tx](context.Background(), ngn, dsn("db"))
+		assertErr(t, err, "<begin-error>")
+		assert.True(t, called)
+	})
+
+	t.Run("should join cleanup errors with the run result", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in dsn) (conn, Cleanup) {
+				return conn{string(in)}, func(context.Context) error { return errors.New("<close-error>") }
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[conn](context.Background(), ngn, dsn("db"))
+		assertErr(t, err, "<close-error>")
+	})
+
+	t.Run("should return an error if a function returns more than one cleanup", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(dsn) (conn, Cleanup, Cleanup) { return conn{}, nil, nil },
+		)
+
+		assertErrContains(t, err, "must have no more than 1 Cleanup return type")
+	})
+
+	t.Run("should return an error if a function accepts a cleanup", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(Cleanup) conn { return conn{} },
+		)
+
+		assertErrContains(t, err, "must not have input param(s) of type Cleanup")
+	})
+
+	t.Run("should return an error if a function only returns a cleanup", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(dsn) Cleanup { return nil },
+		)
+
+		assertErrContains(t, err, "must have at least 1 return value type (excluding error)")
+	})
+}
@@ -0,0 +1,101 @@
+package warp
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time to the engine. The engine measures the durations of
+// the runs and of the functions, waits for the function timeouts of
+// WithLoadShedding, the backoffs and the circuit breakers of Resilient
+// functions, the backoffs of WithRetryLimit and the latencies of WithFaults
+// with its Clock, so that tests of these features can advance a fake clock
+// instead of sleeping. Register it with WithClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel receiving the current time once d has
+	// elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock sets the Clock of the engine, the system clock by default.
+func WithClock(c Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = clock{c}
+	}
+}
+
+// clock is the Clock of an engine, the system clock if c is nil.
+type clock struct {
+	c Clock
+}
+
+func (c clock) now() time.Time {
+	if c.c == nil {
+		return time.Now()
+	}
+	return c.c.Now()
+}
+
+func (c clock) since(t time.Time) time.Duration {
+	return c.now().Sub(t)
+}
+
+// sleep waits for d and reports whether ctx was still live once done.
+func (c clock) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	if c.c == nil {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-c.c.After(d):
+		return ctx.Err() == nil
+	}
+}
+
+// withTimeout returns a copy of ctx cancelled with context.DeadlineExceeded
+// once d has elapsed.
+func (c clock) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if c.c == nil {
+		return context.WithTimeout(ctx, d)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := c.c.After(d)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-timer:
+			cancel(context.DeadlineExceeded)
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+type clockKey struct{}
+
+// withClock returns a copy of ctx carrying c, for the functions of the run
+// to tell the time with, see clockFrom.
+func withClock(ctx context.Context, c clock) context.Context {
+	if c.c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// clockFrom returns the clock carried by ctx, the system clock if none.
+func clockFrom(ctx context.Context) clock {
+	c, _ := ctx.Value(clockKey{}).(clock)
+	return c
+}
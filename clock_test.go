@@ -0,0 +1,112 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+// fakeClock is a Clock whose time only moves when waited on: After advances
+// it by the duration waited for, so that waits return immediately.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func Test_WithClock(t *testing.T) {
+	type (
+		query string
+		quote string
+	)
+	// failing returns a function failing its first n calls
+	failing := func(n int32, calls *atomic.Int32) func(query) (quote, error) {
+		return func(q query) (quote, error) {
+			if calls.Add(1) <= n {
+				return "", errors.New("<unavailable>")
+			}
+			return quote(q), nil
+		}
+	}
+
+	t.Run("should wait for the backoffs with the clock", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		ngn, err := Initialize(Resilient(failing(2, &calls), Retry(3, time.Hour)), WithClock(clock))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		started := time.Now()
+		out, err := Run[quote](context.Background(), ngn, query("<q>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, quote("<q>"), out)
+		assert.Less(t, time.Since(started), time.Minute)
+		assert.Equal(t, 3*time.Hour, report.Functions[0].Duration)
+		assert.Equal(t, 3*time.Hour, report.Duration)
+		assert.Equal(t, 3*time.Hour, ngn.Stats()[report.Functions[0].Name].P99)
+	})
+
+	t.Run("should close the circuit once the cooldown has elapsed on the clock", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		ngn, err := Initialize(Resilient(failing(1, &calls), CircuitBreaker(1, time.Hour)), WithClock(clock))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[quote](context.Background(), ngn, query("<q>"))
+		assertErr(t, err, "failed after 1 attempts: <unavailable>")
+		_, err = Run[quote](context.Background(), ngn, query("<q>"))
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		clock.advance(time.Hour)
+		out, err := Run[quote](context.Background(), ngn, query("<q>"))
+		assert.NoError(t, err)
+		assert.Equal(t, quote("<q>"), out)
+	})
+
+	t.Run("should delay the faulty functions with the clock", func(t *testing.T) {
+		t.Parallel()
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		ngn, err := Initialize(func(q query) quote { return quote(q) }, WithClock(clock))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[quote](context.Background(), ngn, query("<q>"), WithReport(&report), WithFaults(FaultOf[quote](nil, time.Hour)))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour, report.Duration)
+	})
+}
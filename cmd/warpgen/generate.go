@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const warpPath = "github.com/dezlitz/warp"
+
+// generate type-checks the package in dir and returns the source of the file
+// registering static calls for the functions it passes to warp.Initialize.
+// The file named output is ignored while type-checking, as it is about to be
+// replaced.
+func generate(dir, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+	files, err := parseDir(fset, dir, output)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(files[0].Name.Name, fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Path() == warpPath {
+		return nil, errors.New("can not generate static calls for the warp package itself")
+	}
+
+	g := &generator{pkg: pkg, info: info, imports: map[string]string{}}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && g.isWarpFunc(call.Fun, "Initialize") {
+				for _, arg := range call.Args {
+					g.collect(arg)
+				}
+			}
+			return true
+		})
+	}
+
+	return g.render()
+}
+
+func parseDir(fset *token.FileSet, dir, output string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == output || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+type generator struct {
+	pkg        *types.Package
+	info       *types.Info
+	imports    map[string]string // import path -> package name used in the generated file
+	signatures []*types.Signature
+	seen       map[string]bool
+}
+
+// isWarpFunc reports whether expr refers to the named function of the warp
+// package.
+func (g *generator) isWarpFunc(expr ast.Expr, name string) bool {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	case *ast.IndexExpr:
+		return g.isWarpFunc(e.X, name)
+	case *ast.IndexListExpr:
+		return g.isWarpFunc(e.X, name)
+	default:
+		return false
+	}
+
+	fn, ok := g.info.Uses[ident].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == warpPath && (name == "" || fn.Name() == name)
+}
+
+// collect records the signature of expr if it is a function value. Arguments
+// of calls to the warp package, such as warp.Singleton(fn), are searched for
+// function values too.
+func (g *generator) collect(expr ast.Expr) {
+	if sig, ok := g.info.Types[expr].Type.Underlying().(*types.Signature); ok {
+		g.record(sig)
+		return
+	}
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		g.collect(e.X)
+	case *ast.CallExpr:
+		if g.isWarpFunc(e.Fun, "") {
+			for _, arg := range e.Args {
+				g.collect(arg)
+			}
+		}
+	}
+}
+
+func (g *generator) record(sig *types.Signature) {
+	if sig.Variadic() || sig.TypeParams() != nil || sig.Results().Len() == 0 || !g.nameable(sig) {
+		return
+	}
+
+	key := g.signatureString(sig)
+	if g.seen == nil {
+		g.seen = map[string]bool{}
+	}
+	if g.seen[key] {
+		return
+	}
+	g.seen[key] = true
+	g.signatures = append(g.signatures, sig)
+}
+
+// nameable reports whether every type in t can be referred to from the package
+// scope of the generated file.
+func (g *generator) nameable(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil {
+			if obj.Pkg() == g.pkg {
+				if obj.Parent() != g.pkg.Scope() {
+					return false
+				}
+			} else if !obj.Exported() {
+				return false
+			}
+		}
+		args := t.TypeArgs()
+		for i := 0; i < args.Len(); i++ {
+			if !g.nameable(args.At(i)) {
+				return false
+			}
+		}
+		return true
+	case *types.Alias:
+		return g.nameable(types.Unalias(t))
+	case *types.Pointer:
+		return g.nameable(t.Elem())
+	case *types.Slice:
+		return g.nameable(t.Elem())
+	case *types.Array:
+		return g.nameable(t.Elem())
+	case *types.Chan:
+		return g.nameable(t.Elem())
+	case *types.Map:
+		return g.nameable(t.Key()) && g.nameable(t.Elem())
+	case *types.Signature:
+		return g.nameableTuple(t.Params()) && g.nameableTuple(t.Results())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if f := t.Field(i); !f.Exported() && f.Pkg() != g.pkg || !g.nameable(f.Type()) {
+				return false
+			}
+		}
+		return true
+	case *types.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			if m := t.Method(i); !m.Exported() && m.Pkg() != g.pkg || !g.nameable(m.Type()) {
+				return false
+			}
+		}
+		return true
+	case *types.Basic:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *generator) nameableTuple(tuple *types.Tuple) bool {
+	for i := 0; i < tuple.Len(); i++ {
+		if !g.nameable(tuple.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// qualifier names the packages referred to by the generated file, recording
+// the imports it needs.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	if name, ok := g.imports[pkg.Path()]; ok {
+		return name
+	}
+
+	name := pkg.Name()
+	for i := 2; g.importNameTaken(name); i++ {
+		name = fmt.Sprintf("%s%d", pkg.Name(), i)
+	}
+	g.imports[pkg.Path()] = name
+	return name
+}
+
+func (g *generator) importNameTaken(name string) bool {
+	for _, used := range g.imports {
+		if used == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) render() ([]byte, error) {
+	var body bytes.Buffer
+	warp := g.qualifier(types.NewPackage(warpPath, "warp"))
+
+	fmt.Fprintf(&body, "func init() {\n")
+	fmt.Fprintf(&body, "\t%s.RegisterStatic(func(fn any) %s.StaticCall {\n", warp, warp)
+	fmt.Fprintf(&body, "\t\tswitch warpFn := fn.(type) {\n")
+	for _, sig := range g.signatures {
+		g.renderCase(&body, sig)
+	}
+	fmt.Fprintf(&body, "\t\t}\n\t\treturn nil\n\t})\n}\n")
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by warpgen. DO NOT EDIT.\n\npackage %s\n\n", g.pkg.Name())
+
+	// Standard library imports first, then the others
+	var std, other []string
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	fmt.Fprintf(&src, "import (\n")
+	for i, paths := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 && len(other) > 0 {
+			fmt.Fprintf(&src, "\n")
+		}
+		for _, path := range paths {
+			if name := g.imports[path]; name != filepath.Base(path) {
+				fmt.Fprintf(&src, "\t%s %q\n", name, path)
+			} else {
+				fmt.Fprintf(&src, "\t%q\n", path)
+			}
+		}
+	}
+	fmt.Fprintf(&src, ")\n\n")
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// signatureString returns the function type of sig without parameter names,
+// so that signatures differing only by names share a single case.
+func (g *generator) signatureString(sig *types.Signature) string {
+	tuple := func(t *types.Tuple) []string {
+		out := make([]string, t.Len())
+		for i := range out {
+			out[i] = types.TypeString(t.At(i).Type(), g.qualifier)
+		}
+		return out
+	}
+
+	s := "func(" + strings.Join(tuple(sig.Params()), ", ") + ")"
+	switch results := tuple(sig.Results()); len(results) {
+	case 1:
+		s += " " + results[0]
+	default:
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
+
+func (g *generator) renderCase(w *bytes.Buffer, sig *types.Signature) {
+	params, results := sig.Params(), sig.Results()
+
+	fmt.Fprintf(w, "\t\tcase %s:\n", g.signatureString(sig))
+	fmt.Fprintf(w, "\t\t\treturn func(warpArgs []any) []any {\n")
+
+	args := make([]string, params.Len())
+	for i := range args {
+		args[i] = fmt.Sprintf("a%d", i)
+		fmt.Fprintf(w, "\t\t\t\t%s, _ := warpArgs[%d].(%s)\n", args[i], i, types.TypeString(params.At(i).Type(), g.qualifier))
+	}
+
+	rets := make([]string, results.Len())
+	for i := range rets {
+		rets[i] = fmt.Sprintf("r%d", i)
+	}
+	fmt.Fprintf(w, "\t\t\t\t%s := warpFn(%s)\n", strings.Join(rets, ", "), strings.Join(args, ", "))
+	fmt.Fprintf(w, "\t\t\t\treturn []any{%s}\n", strings.Join(rets, ", "))
+	fmt.Fprintf(w, "\t\t\t}\n")
+}
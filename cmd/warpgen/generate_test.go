@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func Test_Generate(t *testing.T) {
+	dir := filepath.Join("testdata", "example")
+	got, err := generate(dir, "warp_static.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join(dir, "warp_static.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Fatalf("generated source mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,43 @@
+// Command warpgen generates static calls for the functions passed to
+// warp.Initialize, so the engine can call them without reflection.
+//
+// Add the following directive to a package that initializes an engine:
+//
+//	//go:generate go run github.com/dezlitz/warp/cmd/warpgen
+//
+// warpgen type-checks the package, collects the signature of every function
+// passed to warp.Initialize (directly or wrapped by helpers such as
+// warp.Singleton) and writes a file registering a warp.StaticFactory for
+// them. The engine API does not change: engines initialized after the
+// generated init function has run call the functions directly.
+//
+// Functions whose signature refers to types declared inside a function body,
+// or to unexported types of another package, can not be named at package
+// level and keep being called through reflection.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	var (
+		dir    = flag.String("dir", ".", "directory of the package to generate static calls for")
+		output = flag.String("o", "warp_static.go", "name of the generated file, relative to -dir")
+	)
+	flag.Parse()
+
+	src, err := generate(*dir, *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warpgen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(filepath.Join(*dir, *output), src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "warpgen: %v\n", err)
+		os.Exit(1)
+	}
+}
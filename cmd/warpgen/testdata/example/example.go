@@ -0,0 +1,27 @@
+package example
+
+import (
+	"context"
+	"io"
+
+	"github.com/dezlitz/warp"
+)
+
+type (
+	Request  string
+	Response string
+	session  struct{ id string }
+)
+
+func newSession(r Request) (*session, error) { return &session{id: string(r)}, nil }
+
+func NewEngine() (*warp.Engine, error) {
+	type local string
+
+	return warp.Initialize(
+		newSession,
+		warp.Singleton(func(context.Context) (io.Reader, warp.Cleanup) { return nil, nil }),
+		warp.Tag(func(s *session, r io.Reader) Response { return Response(s.id) }, "serve"),
+		func(Response) local { return "" },
+	)
+}
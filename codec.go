@@ -0,0 +1,51 @@
+package warp
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Codec encodes the values of a type to bytes and back, for instance to hand
+// a checkpointed run over to another process, see WithCheckpoint.
+type Codec interface {
+	// Encode returns the encoding of v.
+	Encode(v any) ([]byte, error)
+	// Decode decodes data into the value pointed to by v.
+	Decode(data []byte, v any) error
+}
+
+// JSONCodec encodes values as JSON. It is the codec of the types without a
+// codec registered with WithCodec.
+var JSONCodec Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec registers c as the codec of the values of type T, in place of
+// JSONCodec.
+func WithCodec[T any](c Codec) Option {
+	return func(cfg *config) {
+		if cfg.codecs == nil {
+			cfg.codecs = map[reflect.Type]Codec{}
+		}
+		cfg.codecs[reflect.TypeOf((*T)(nil)).Elem()] = c
+	}
+}
+
+// codecs is the codec registry of an engine.
+type codecs map[reflect.Type]Codec
+
+// get returns the codec of t.
+func (cs codecs) get(t reflect.Type) Codec {
+	if c, ok := cs[t]; ok && c != nil {
+		return c
+	}
+	return JSONCodec
+}
@@ -0,0 +1,48 @@
+package warp
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithColdFunctionLog periodically logs the functions that have not run since
+// the engine was initialized, as reported by Engine.ColdFunctions. Nothing is
+// logged once every function has run. Logging stops when the engine is closed.
+func WithColdFunctionLog(logger *slog.Logger, interval time.Duration) Option {
+	return func(c *config) {
+		c.coldLogger = logger
+		c.coldInterval = interval
+	}
+}
+
+// ColdFunctions returns the functions that have never been called by any run of
+// the engine, in registration order. Functions that stay cold in a long lived
+// service usually point to dead configuration: their inputs are never provided
+// or an upstream function never produces them.
+func (e *Engine) ColdFunctions() []string {
+	var out []string
+	for _, fn := range e.funcs {
+		if !fn.called.Load() {
+			out = append(out, fn.name)
+		}
+	}
+	return out
+}
+
+// logColdFunctions logs the cold functions every interval until done is
+// closed.
+func (e *Engine) logColdFunctions(logger *slog.Logger, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if cold := e.ColdFunctions(); len(cold) > 0 {
+				logger.Warn("warp: functions have never run", slog.Any("functions", cold))
+			}
+		}
+	}
+}
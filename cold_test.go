@@ -0,0 +1,82 @@
+package warp_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func Test_EngineColdFunctions(t *testing.T) {
+	type (
+		in1  string
+		in2  string
+		out1 string
+		out2 string
+	)
+
+	t.Run("should report functions that have never run", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in in1) out1 { return out1(in) },
+			func(in in2) out2 { return out2(in) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, ngn.ColdFunctions(), 2)
+
+		_, err = Run[out1](context.Background(), ngn, in1("<in1>"))
+		assert.NoError(t, err)
+
+		cold := ngn.ColdFunctions()
+		if assert.Len(t, cold, 1) {
+			assert.Contains(t, cold[0], "(warp_test.in2) warp_test.out2")
+		}
+
+		_, err = Run[out2](context.Background(), ngn, in2("<in2>"))
+		assert.NoError(t, err)
+		assert.Empty(t, ngn.ColdFunctions())
+	})
+
+	t.Run("should periodically log cold functions until closed", func(t *testing.T) {
+		t.Parallel()
+		var buf syncBuffer
+		ngn, err := Initialize(
+			func(in in1) out1 { return out1(in) },
+			WithColdFunctionLog(slog.New(slog.NewTextHandler(&buf, nil)), 10*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(buf.String(), "warp: functions have never run")
+		}, time.Second, 10*time.Millisecond)
+		assert.NoError(t, ngn.Close())
+	})
+}
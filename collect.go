@@ -0,0 +1,54 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithCollect makes a run whose target is a slice, such as Run[[]Validator],
+// return the values of every output of the run assignable to the element type
+// of the slice, the outputs implementing the Validator interface, in the order
+// their functions were registered, instead of the output of a function
+// producing the slice. Outputs that are not available, because their functions
+// were skipped or did not set them, are left out. Values converted by adapters
+// or interface bindings are not collected twice.
+//
+// The run fails if its target is not a slice or if no output is assignable to
+// its element type. See also RunOne.
+func WithCollect() RunOption {
+	return func(c *runConfig) {
+		c.collect = true
+	}
+}
+
+// collected returns the output types of s assignable to the element type of
+// target, in registration order.
+func (e *Engine) collected(s *schedule, target reflect.Type) ([]reflect.Type, error) {
+	if target.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("collected output type %s must be a slice", target)
+	}
+
+	var out []reflect.Type
+	for _, fn := range s.funcs {
+		for _, outT := range fn.valueOutputs() {
+			if !e.adapted[outT] && outT.AssignableTo(target.Elem()) {
+				out = append(out, outT)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, categorize(ErrTargetNotProducible, "no function output type is assignable to %s", target.Elem())
+	}
+	return out, nil
+}
+
+// collect returns the values of the types stored in storage, as a T.
+func collect[T any](storage *valueStore, types []reflect.Type) T {
+	out := reflect.MakeSlice(reflect.TypeOf((*T)(nil)).Elem(), 0, len(types))
+	for _, t := range types {
+		if v, ok := loadValue(storage, valuePlan(t)); ok {
+			out = reflect.Append(out, v)
+		}
+	}
+	return out.Interface().(T)
+}
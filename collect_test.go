@@ -0,0 +1,85 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type validator interface {
+	Validate() error
+}
+
+type (
+	emailValidator struct{}
+	phoneValidator struct{ err error }
+	ageValidator   struct{}
+)
+
+func (emailValidator) Validate() error   { return nil }
+func (v phoneValidator) Validate() error { return v.err }
+func (ageValidator) Validate() error     { return nil }
+
+func Test_WithCollect(t *testing.T) {
+	type (
+		form     string
+		verified bool
+	)
+	ngn, err := Initialize(
+		func(f form) emailValidator { return emailValidator{} },
+		func(f form) phoneValidator { return phoneValidator{errors.New("<invalid phone>")} },
+		func(f form, v verified) ageValidator { return ageValidator{} },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should collect the outputs implementing the interface", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[[]validator](context.Background(), ngn, form("<form>"), verified(true), WithCollect())
+		assert.NoError(t, err)
+		assert.Equal(t, []validator{emailValidator{}, phoneValidator{errors.New("<invalid phone>")}, ageValidator{}}, out)
+	})
+
+	t.Run("should leave out the outputs that are not available", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[[]validator](context.Background(), ngn, form("<form>"), WithCollect())
+		assert.NoError(t, err)
+		assert.Equal(t, []validator{emailValidator{}, phoneValidator{errors.New("<invalid phone>")}}, out)
+	})
+
+	t.Run("should not collect without WithCollect", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[[]validator](context.Background(), ngn, form("<form>"))
+		assertErr(t, err, "output type []warp_test.validator does not match any provided input types")
+	})
+
+	t.Run("should not collect the values converted by interface bindings twice", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(f form) emailValidator { return emailValidator{} },
+			func(v validator) verified { return v.Validate() == nil },
+			WithInterfaceBinding(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[[]validator](context.Background(), ngn, form("<form>"), WithCollect())
+		assert.NoError(t, err)
+		assert.Equal(t, []validator{emailValidator{}}, out)
+	})
+
+	t.Run("should fail if the target can not be collected", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[validator](context.Background(), ngn, form("<form>"), WithCollect())
+		assertErr(t, err, "collected output type warp_test.validator must be a slice")
+
+		_, err = Run[[]error](context.Background(), ngn, form("<form>"), WithCollect())
+		assertErr(t, err, "no function output type is assignable to error")
+	})
+}
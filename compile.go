@@ -0,0 +1,48 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Runner runs an engine for a single target type T. It is created by Compile.
+type Runner[T any] struct {
+	e        *Engine
+	schedule *schedule
+}
+
+// Compile returns a Runner producing T. The functions that do not contribute
+// to T, directly or through their outputs, are pruned once at compile time and
+// the remaining ones are sorted in dependency order, so every Runner.Run only
+// schedules the functions it needs.
+//
+// An error is returned if the engine has not been initialized or if no
+// function produces T.
+func Compile[T any](e *Engine) (Runner[T], error) {
+	if e == nil || !e.initialized {
+		return Runner[T]{}, categorize(ErrNotInitialized, "error compiling engine that has not been initialized")
+	}
+
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	producer, ok := e.producers[target]
+	if !ok {
+		return Runner[T]{}, categorize(ErrTargetNotProducible, "output type %s does not match any function output types", target)
+	}
+
+	return Runner[T]{
+		e:        e,
+		schedule: newSchedule(sortFunctions(e.withUpstream([]*function{producer}), e.producers), e.numSignals),
+	}, nil
+}
+
+// Run runs the compiled functions and returns the output of type T, with the
+// same semantics as Run. Functions pruned by Compile never run, even if their
+// inputs are provided.
+func (r Runner[T]) Run(ctx context.Context, provided ...any) (T, error) {
+	if r.e == nil {
+		var out T
+		return out, errors.New("error running runner that has not been compiled")
+	}
+	return run[T](ctx, r.e, r.schedule, provided)
+}
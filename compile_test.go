@@ -0,0 +1,71 @@
+package warp_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Compile(t *testing.T) {
+	type (
+		in      string
+		user    string
+		profile string
+		metrics string
+	)
+
+	t.Run("should only run the functions needed to produce the target", func(t *testing.T) {
+		t.Parallel()
+		var metricsCalls atomic.Int32
+		ngn, err := Initialize(
+			func(u user) profile { return profile(u + "<profile>") },
+			func(i in) user { return user(i + "<user>") },
+			func(u user) metrics {
+				metricsCalls.Add(1)
+				return metrics(u)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runner, err := Compile[profile](ngn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for range 2 {
+			out, err := runner.Run(context.Background(), in("<in>"))
+			assert.NoError(t, err)
+			assert.Equal(t, profile("<in><user><profile>"), out)
+		}
+		assert.Equal(t, int32(0), metricsCalls.Load())
+	})
+
+	t.Run("should return an error if no function produces the target", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) user { return user(i) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Compile[profile](ngn)
+		assertErrContains(t, err, "output type warp_test.profile does not match any function output types")
+	})
+
+	t.Run("should return an error if the engine is not initialized", func(t *testing.T) {
+		t.Parallel()
+		_, err := Compile[profile](nil)
+		assertErr(t, err, "error compiling engine that has not been initialized")
+
+		var runner Runner[profile]
+		_, err = runner.Run(context.Background())
+		assertErr(t, err, "error running runner that has not been compiled")
+	})
+}
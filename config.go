@@ -0,0 +1,144 @@
+package warp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Factory builds a function to register with an engine from the options found
+// in the engine configuration.
+type Factory func(opts FactoryOptions) (any, error)
+
+// FactoryOptions holds the options of a function in the engine configuration.
+type FactoryOptions map[string]any
+
+// Decode decodes the options into v, which should be a pointer to a struct
+// with json tags. Options that do not match any field of v are an error.
+func (o FactoryOptions) Decode(v any) error {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Registry holds named factories so the shape of an engine can be described
+// by an EngineConfig instead of code.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: map[string]Factory{}}
+}
+
+// Register adds a named factory to the registry. It returns an error if the
+// name is empty or already registered, or if the factory is nil.
+func (r *Registry) Register(name string, f Factory) error {
+	if name == "" {
+		return errors.New("factory name must not be empty")
+	}
+	if f == nil {
+		return fmt.Errorf("factory %q must not be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("factory %q already registered", name)
+	}
+	r.factories[name] = f
+	return nil
+}
+
+// Names returns the names of the registered factories, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// EngineConfig describes the shape of an engine in terms of registered
+// factories.
+// It can be decoded from JSON, see ParseConfig, or from YAML using the yaml
+// tags.
+type EngineConfig struct {
+	Functions []FunctionConfig `json:"functions" yaml:"functions"`
+}
+
+// FunctionConfig describes a single function of an engine.
+type FunctionConfig struct {
+	// Factory is the name of the registered factory building the function.
+	Factory string `json:"factory" yaml:"factory"`
+	// Options are passed to the factory.
+	Options FactoryOptions `json:"options,omitempty" yaml:"options,omitempty"`
+	// Tags label the function, see Tag.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Singleton marks the function as singleton-scoped, see Singleton.
+	Singleton bool `json:"singleton,omitempty" yaml:"singleton,omitempty"`
+}
+
+// ParseConfig decodes a JSON engine configuration. Unknown fields are an error.
+func ParseConfig(r io.Reader) (EngineConfig, error) {
+	var cfg EngineConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return EngineConfig{}, fmt.Errorf("error parsing engine config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Initialize builds the functions described by cfg with the registered
+// factories and initializes an engine with them. Any extra functions or
+// Options are passed to Initialize as well.
+//
+// All configuration errors are reported together: unknown factories and
+// failing factories. The resulting engine is then validated as any other.
+func (r *Registry) Initialize(cfg EngineConfig, extra ...any) (*Engine, error) {
+	var (
+		fns  = make([]any, 0, len(cfg.Functions)+len(extra))
+		errs []error
+	)
+
+	r.mu.RLock()
+	for i, fc := range cfg.Functions {
+		factory, ok := r.factories[fc.Factory]
+		if !ok {
+			errs = append(errs, fmt.Errorf("function %d: unknown factory %q", i, fc.Factory))
+			continue
+		}
+
+		fn, err := factory(fc.Options)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("function %d: factory %q: %w", i, fc.Factory, err))
+			continue
+		}
+
+		p := asProvider(fn)
+		p.tags = append(p.tags, fc.Tags...)
+		p.singleton = p.singleton || fc.Singleton
+		fns = append(fns, p)
+	}
+	r.mu.RUnlock()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("engine config validation error: %w", err)
+	}
+
+	return Initialize(append(fns, extra...)...)
+}
@@ -0,0 +1,98 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Registry(t *testing.T) {
+	type (
+		name     string
+		greeting string
+		shout    string
+	)
+
+	newRegistry := func(t *testing.T) *Registry {
+		r := NewRegistry()
+		err := errors.Join(
+			r.Register("greet", func(opts FactoryOptions) (any, error) {
+				var o struct {
+					Prefix string `json:"prefix"`
+				}
+				if err := opts.Decode(&o); err != nil {
+					return nil, err
+				}
+				return func(n name) greeting { return greeting(o.Prefix + string(n)) }, nil
+			}),
+			r.Register("shout", func(FactoryOptions) (any, error) {
+				return func(g greeting) shout { return shout(strings.ToUpper(string(g))) }, nil
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	t.Run("should initialize an engine from a JSON config", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig(strings.NewReader(`{
+			"functions": [
+				{"factory": "greet", "options": {"prefix": "hello "}},
+				{"factory": "shout", "tags": ["loud"]}
+			]
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ngn, err := newRegistry(t).Initialize(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := RunTagged[shout](context.Background(), ngn, "loud", name("<name>"))
+		assert.NoError(t, err)
+		assert.Equal(t, shout("HELLO <NAME>"), out)
+	})
+
+	t.Run("should return an error for unknown config fields", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig(strings.NewReader(`{"functions": [{"factory": "greet", "limit": 1}]}`))
+		assertErrContains(t, err, `unknown field "limit"`)
+	})
+
+	t.Run("should return all config errors together", func(t *testing.T) {
+		t.Parallel()
+		_, err := newRegistry(t).Initialize(EngineConfig{
+			Functions: []FunctionConfig{
+				{Factory: "greet", Options: FactoryOptions{"suffix": "!"}},
+				{Factory: "whisper"},
+			},
+		})
+		assertErrContains(t, err, `function 0: factory "greet": json: unknown field "suffix"`)
+		assertErrContains(t, err, `function 1: unknown factory "whisper"`)
+	})
+
+	t.Run("should validate the configured engine", func(t *testing.T) {
+		t.Parallel()
+		_, err := newRegistry(t).Initialize(EngineConfig{
+			Functions: []FunctionConfig{{Factory: "greet"}, {Factory: "greet"}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return an error when registering a name twice", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		assertErr(t, r.Register("greet", func(FactoryOptions) (any, error) { return nil, nil }),
+			`factory "greet" already registered`)
+		assert.Equal(t, []string{"greet", "shout"}, r.Names())
+	})
+}
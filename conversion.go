@@ -0,0 +1,87 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Conversion decides which function output a run returns when no function
+// produces its target type T itself.
+type Conversion int
+
+const (
+	// ConvertNone only returns an output of type T. This is the default.
+	ConvertNone Conversion = iota
+	// ConvertAssignable also returns an output assignable to T, such as an
+	// output implementing the interface T.
+	ConvertAssignable
+	// ConvertAll also returns an output convertible to T, converted, such as
+	// an output of a named string type for a string T. Integers are not
+	// converted to strings.
+	ConvertAll
+)
+
+// WithConversion sets the Conversion of the run. Whatever the Conversion, an
+// output of type T is returned if there is one, and the run fails if several
+// outputs are candidates.
+func WithConversion(c Conversion) RunOption {
+	return func(cfg *runConfig) {
+		cfg.conversion = c
+	}
+}
+
+// returned returns the output types a run of s returns for its target type,
+// see WithCollect and WithConversion.
+func (e *Engine) returned(s *schedule, target reflect.Type, cfg *runConfig) ([]reflect.Type, error) {
+	if cfg.collect {
+		return e.collected(s, target)
+	}
+	outT, err := e.source(target, cfg.conversion)
+	if err != nil {
+		return nil, err
+	}
+	return []reflect.Type{outT}, nil
+}
+
+// source returns the output type the run returns for its target type under
+// the conversion c.
+func (e *Engine) source(target reflect.Type, c Conversion) (reflect.Type, error) {
+	if c < ConvertNone || c > ConvertAll {
+		return nil, fmt.Errorf("unknown conversion %d", c)
+	}
+	if _, ok := e.producers[target]; ok || c == ConvertNone {
+		return target, nil
+	}
+
+	var candidates []reflect.Type
+	for outT := range e.producers {
+		if outT.AssignableTo(target) || (c == ConvertAll && convertible(outT, target)) {
+			candidates = append(candidates, outT)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return target, nil
+	case 1:
+		return candidates[0], nil
+	}
+	names := sliceConvert(reflect.Type.String, candidates)
+	slices.Sort(names)
+	return nil, categorize(ErrTargetNotProducible, "output type %s matches several function output types: %s", target, strings.Join(names, ", "))
+}
+
+// convertible reports whether values of type from can be converted to type
+// to, except integers to strings, which Go converts to the UTF-8 encoding of
+// a code point rather than to their decimal representation.
+func convertible(from, to reflect.Type) bool {
+	switch from.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if to.Kind() == reflect.String {
+			return false
+		}
+	}
+	return from.ConvertibleTo(to)
+}
@@ -0,0 +1,73 @@
+package warp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type greeting string
+
+func (g greeting) String() string { return string(g) }
+
+func Test_WithConversion(t *testing.T) {
+	type (
+		name  string
+		count int
+		size  int
+	)
+	ngn, err := Initialize(
+		func(n name) greeting { return greeting("hello " + n) },
+		func(n name) count { return count(len(n)) },
+		func(n name) size { return size(len(n)) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should only return outputs of the target type by default", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[string](context.Background(), ngn, name("<n>"))
+		assertErr(t, err, "output type string does not match any provided input types")
+	})
+
+	t.Run("should return an output assignable to the target", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[fmt.Stringer](context.Background(), ngn, name("<n>"), WithConversion(ConvertAssignable))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello <n>"), out)
+
+		_, err = Run[string](context.Background(), ngn, name("<n>"), WithConversion(ConvertAssignable))
+		assertErr(t, err, "output type string does not match any provided input types")
+	})
+
+	t.Run("should return an output convertible to the target", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[string](context.Background(), ngn, name("<n>"), WithConversion(ConvertAll))
+		assert.NoError(t, err)
+		assert.Equal(t, "hello <n>", out)
+	})
+
+	t.Run("should fail if several outputs can be converted to the target", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[int64](context.Background(), ngn, name("<n>"), WithConversion(ConvertAll))
+		assertErr(t, err, "output type int64 matches several function output types: warp_test.count, warp_test.size")
+	})
+
+	t.Run("should prefer the output of the target type", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[greeting](context.Background(), ngn, name("<n>"), WithConversion(ConvertAll))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello <n>"), out)
+	})
+
+	t.Run("should fail with an unknown conversion", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[greeting](context.Background(), ngn, name("<n>"), WithConversion(Conversion(42)))
+		assertErr(t, err, "unknown conversion 42")
+	})
+}
@@ -0,0 +1,52 @@
+package warp
+
+import (
+	"context"
+	"runtime"
+)
+
+// WithCooperativeExecution makes the functions of the runs of the engine yield
+// the processor before they are called, so that the functions of compute
+// bound graphs interleave with the other goroutines of the process, such as
+// request handlers, instead of monopolizing the Ps once their inputs are
+// ready.
+//
+// maxConcurrent bounds the number of functions of a run executing at once,
+// which splits wide layers of the graph into chunks. It is unbounded if zero
+// or negative.
+//
+// The time a function waits to be scheduled once its inputs are available is
+// reported in FunctionEvent.Delay, with or without this option.
+func WithCooperativeExecution(maxConcurrent int) Option {
+	return func(c *config) {
+		c.cooperative = &cooperation{maxConcurrent: maxConcurrent}
+	}
+}
+
+// cooperation is the cooperative execution policy of an engine.
+type cooperation struct {
+	maxConcurrent int
+}
+
+// slots returns the gate bounding the functions of a run executing at once,
+// nil if they are not bounded.
+func (c *cooperation) slots() *gate {
+	if c == nil || c.maxConcurrent <= 0 {
+		return nil
+	}
+	return newGate(c.maxConcurrent)
+}
+
+// enter waits for the function of the given precedence to be allowed to
+// execute and returns the func to call once it has returned. It gives up once
+// ctx is done, returning its error.
+func (c *cooperation) enter(ctx context.Context, slots *gate, p precedence) (exit func(), err error) {
+	if c == nil {
+		return func() {}, nil
+	}
+	if err := slots.acquire(ctx, p); err != nil {
+		return func() {}, err
+	}
+	runtime.Gosched()
+	return slots.release, nil
+}
@@ -0,0 +1,96 @@
+package warp_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type delayListener struct {
+	NopListener
+	mu     sync.Mutex
+	delays map[string]time.Duration
+}
+
+func (l *delayListener) OnFunctionEnd(_ context.Context, ev FunctionEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.delays[ev.Name] = ev.Delay
+}
+
+func Test_WithCooperativeExecution(t *testing.T) {
+	type (
+		in  int
+		a   int
+		b   int
+		c   int
+		d   int
+		out int
+	)
+
+	t.Run("should bound the functions executing at once", func(t *testing.T) {
+		t.Parallel()
+		var active, peak atomic.Int32
+		work := func() {
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			active.Add(-1)
+		}
+		ngn, err := Initialize(
+			func(i in) a { work(); return a(i) },
+			func(i in) b { work(); return b(i) },
+			func(i in) c { work(); return c(i) },
+			func(i in) d { work(); return d(i) },
+			func(a a, b b, c c, d d) out { return out(int(a) + int(b) + int(c) + int(d)) },
+			WithCooperativeExecution(2),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Run[out](context.Background(), ngn, in(1))
+		assert.NoError(t, err)
+		assert.Equal(t, out(4), res)
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("should report the scheduling delay of the functions", func(t *testing.T) {
+		t.Parallel()
+		l := &delayListener{delays: map[string]time.Duration{}}
+		ngn, err := Initialize(
+			func(i in) a { time.Sleep(20 * time.Millisecond); return a(i) },
+			func(i in) b { time.Sleep(20 * time.Millisecond); return b(i) },
+			func(a a, b b) out { return out(int(a) + int(b)) },
+			WithCooperativeExecution(1),
+			WithListener(l),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[out](context.Background(), ngn, in(1))
+		assert.NoError(t, err)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		assert.Len(t, l.delays, 3)
+		var waited bool
+		for _, delay := range l.delays {
+			// one of the two first functions waits for the other to return
+			waited = waited || delay >= 20*time.Millisecond
+		}
+		assert.True(t, waited)
+	})
+}
@@ -0,0 +1,39 @@
+package warp
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// WithDeadlineReport logs the partial report of every run that ends because
+// its context deadline was exceeded: the functions that succeeded, failed or
+// were cancelled, and the functions that were still waiting, with the inputs
+// they were waiting on. Timeout incidents then come with their diagnostics
+// instead of requiring a reproduction. Values are not logged.
+func WithDeadlineReport(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.deadlineLogger = logger
+	}
+}
+
+// logDeadlineReport logs the report of a run whose deadline was exceeded.
+func logDeadlineReport(logger *slog.Logger, target reflect.Type, r Report) {
+	byStatus := map[Status][]string{}
+	for _, fr := range r.Functions {
+		byStatus[fr.Status] = append(byStatus[fr.Status], fr.Name)
+	}
+	waiting := map[string][]string{}
+	for _, w := range r.Waiting() {
+		waiting[w.Name] = sliceConvert(reflect.Type.String, w.WaitingOn)
+	}
+
+	logger.Warn("warp: run deadline exceeded",
+		slog.String("target", target.String()),
+		slog.Duration("duration", r.Duration),
+		slog.Any("succeeded", byStatus[StatusSucceeded]),
+		slog.Any("failed", byStatus[StatusFailed]),
+		slog.Any("cancelled", byStatus[StatusCancelled]),
+		slog.Any("skipped", byStatus[StatusSkipped]),
+		slog.Any("waiting", waiting),
+	)
+}
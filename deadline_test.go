@@ -0,0 +1,93 @@
+package warp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithDeadlineReport(t *testing.T) {
+	type (
+		in      string
+		user    string
+		orders  string
+		summary string
+	)
+
+	fns := func(opts ...any) []any {
+		return append([]any{
+			func(i in) user { return user(i) },
+			func(ctx context.Context, u user) (orders, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			func(u user, o orders) summary { return summary(u) },
+		}, opts...)
+	}
+
+	t.Run("should log the partial report of a run whose deadline was exceeded", func(t *testing.T) {
+		t.Parallel()
+		var buf syncBuffer
+		ngn, err := Initialize(fns(WithDeadlineReport(slog.New(slog.NewJSONHandler(&buf, nil))))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var report Report
+		_, err = Run[summary](ctx, ngn, in("<in>"), WithReport(&report))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		if waiting := report.Waiting(); assert.Len(t, waiting, 1) {
+			assert.Contains(t, waiting[0].Name, "warp_test.summary")
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(orders(""))}, waiting[0].WaitingOn)
+		}
+
+		var entry struct {
+			Msg       string
+			Target    string
+			Succeeded []string
+			Cancelled []string
+			Waiting   map[string][]string
+		}
+		if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "warp: run deadline exceeded", entry.Msg)
+		assert.Equal(t, "warp_test.summary", entry.Target)
+		if assert.Len(t, entry.Succeeded, 1) {
+			assert.Contains(t, entry.Succeeded[0], "warp_test.user")
+		}
+		if assert.Len(t, entry.Cancelled, 1) {
+			assert.Contains(t, entry.Cancelled[0], "warp_test.orders")
+		}
+		for _, on := range entry.Waiting {
+			assert.Equal(t, []string{"warp_test.orders"}, on)
+		}
+		assert.Len(t, entry.Waiting, 1)
+	})
+
+	t.Run("should not log runs ending otherwise", func(t *testing.T) {
+		t.Parallel()
+		var buf syncBuffer
+		ngn, err := Initialize(fns(WithDeadlineReport(slog.New(slog.NewJSONHandler(&buf, nil))))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err = Run[summary](ctx, ngn, in("<in>"))
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,124 @@
+package warp
+
+import "reflect"
+
+// WithDeepCopy makes every function receive its own deep copy of the values
+// it is called with, instead of the value shared with the other functions
+// consuming it. A function mutating its inputs in place then no longer races
+// with the functions running at the same time, nor changes what the
+// functions running after it see.
+//
+// Pointers, slices, maps, arrays, interfaces and the exported fields of
+// structs are copied, keeping the pointers to a same value pointing to a same
+// copy. A pointer into a value, such as to a field of a struct, points to a
+// copy of its own. The unexported fields of structs, the values pointed to by
+// pointers to structs without exported fields, such as a *sync.Mutex or a
+// *time.Location, channels and functions are shared. Lazy parameters are not
+// copied.
+//
+// Copying is expensive: prefer treating inputs as read-only, and use
+// WithImmutabilityCheck in tests to find the functions that do not.
+func WithDeepCopy() Option {
+	return func(c *config) {
+		c.deepCopy = true
+	}
+}
+
+// deepCopy returns a deep copy of v, see WithDeepCopy.
+func deepCopy(v reflect.Value) reflect.Value {
+	if v.Type().Implements(reflect.TypeOf((*awaiter)(nil)).Elem()) {
+		// Outputs still being produced are shared by design
+		return v
+	}
+	return copyValue(v, map[copyKey]reflect.Value{})
+}
+
+// copyKey identifies a copied pointer or map. A pointer to a struct and a
+// pointer to its first field have the same address, so the type is part of
+// the key.
+type copyKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func copyValue(v reflect.Value, copies map[copyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || opaque(v.Type().Elem()) {
+			return v
+		}
+		key := copyKey{addr: v.Pointer(), typ: v.Type()}
+		if cp, ok := copies[key]; ok {
+			return cp
+		}
+		cp := reflect.New(v.Type().Elem())
+		copies[key] = cp
+		cp.Elem().Set(copyValue(v.Elem(), copies))
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(copyValue(v.Elem(), copies))
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(copyValue(v.Index(i), copies))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(copyValue(v.Index(i), copies))
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{addr: v.Pointer(), typ: v.Type()}
+		if cp, ok := copies[key]; ok {
+			return cp
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[key] = cp
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), copyValue(iter.Value(), copies))
+		}
+		return cp
+	case reflect.Struct:
+		// The unexported fields are copied shallowly with the struct
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				cp.Field(i).Set(copyValue(v.Field(i), copies))
+			}
+		}
+		return cp
+	default:
+		// Values of basic kinds are copied with v, channels, functions and
+		// unsafe pointers are shared
+		return v
+	}
+}
+
+// opaque reports whether t is a struct without exported fields, whose state
+// can not be copied.
+func opaque(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package warp_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithDeepCopy(t *testing.T) {
+	type (
+		item struct {
+			Name string
+			Tags []string
+		}
+		cart struct {
+			Items  []*item
+			Counts map[string]int
+			First  *item
+			Note   any
+			Lock   *sync.Mutex
+			hidden *item
+		}
+		seen    *cart
+		trimmed int
+		summary string
+	)
+
+	newCart := func() *cart {
+		first := &item{Name: "apple", Tags: []string{"fruit"}}
+		return &cart{
+			Items:  []*item{first, {Name: "pear"}},
+			Counts: map[string]int{"apple": 1},
+			First:  first,
+			Note:   []string{"gift"},
+			Lock:   &sync.Mutex{},
+			hidden: first,
+		}
+	}
+
+	t.Run("gives every function its own copy of its inputs", func(t *testing.T) {
+		t.Parallel()
+		var received *cart
+		ngn, err := Initialize(
+			func(c *cart) trimmed {
+				received = c
+				c.Items[0].Tags[0] = "mutated"
+				c.Items = c.Items[:1]
+				c.Counts["apple"] = 10
+				c.Note.([]string)[0] = "mutated"
+				return 1
+			},
+			func(c *cart, _ trimmed) summary {
+				return summary(c.Items[0].Tags[0] + " " + c.Items[1].Name + " " + c.Note.([]string)[0])
+			},
+			WithDeepCopy(),
+			WithImmutabilityCheck(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		provided := newCart()
+		out, err := Run[summary](context.Background(), ngn, provided)
+		assert.NoError(t, err)
+		assert.Equal(t, summary("fruit pear gift"), out)
+		assert.Equal(t, "fruit", provided.Items[0].Tags[0])
+		assert.Equal(t, 1, provided.Counts["apple"])
+
+		assert.NotSame(t, provided, received)
+		assert.Same(t, received.Items[0], received.First, "pointers to a same value point to a same copy")
+		assert.Same(t, provided.Lock, received.Lock, "opaque values are shared")
+		assert.Same(t, provided.hidden, received.hidden, "unexported fields are shared")
+	})
+
+	t.Run("shares inputs by default", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(c *cart) seen { return seen(c) })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		provided := newCart()
+		out, err := Run[seen](context.Background(), ngn, provided)
+		assert.NoError(t, err)
+		assert.Same(t, provided, (*cart)(out))
+	})
+
+	t.Run("copies a pointer to a struct and to its first field apart", func(t *testing.T) {
+		t.Parallel()
+		type (
+			inner  struct{ N int }
+			outer  struct{ I inner }
+			holder struct {
+				O *outer
+				I *inner
+			}
+			total int
+		)
+		var received holder
+		ngn, err := Initialize(
+			func(h holder) total {
+				received = h
+				h.I.N++
+				return total(h.O.I.N + h.I.N)
+			},
+			WithDeepCopy(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		o := &outer{I: inner{N: 1}}
+		out, err := Run[total](context.Background(), ngn, holder{O: o, I: &o.I})
+		assert.NoError(t, err)
+		assert.Equal(t, total(3), out)
+		assert.Equal(t, 1, o.I.N)
+		assert.NotSame(t, o, received.O)
+		assert.NotSame(t, &o.I, received.I)
+	})
+}
@@ -0,0 +1,50 @@
+package warp
+
+import "reflect"
+
+// Default registers value as the default value of type T: the functions of
+// the engine accepting a T, or an Optional[T], receive it when no provided
+// input and no function of the run supplies T, instead of being skipped or of
+// receiving an unset Optional. A function returning an unset Optional[T], or
+// skipped, leaves its consumers with the default too.
+//
+//	warp.Initialize(fns, warp.Default(Timeout(5*time.Second)))
+//
+// A default is a fallback for the consumers only: it is not the output of a
+// run targeting T, and it never prevents the functions producing T from
+// running. Registering the default of the same type twice keeps the last.
+func Default[T any](value T) Option {
+	return func(c *config) {
+		if c.defaults == nil {
+			c.defaults = map[reflect.Type]reflect.Value{}
+		}
+		c.defaults[reflect.TypeOf((*T)(nil)).Elem()] = reflect.ValueOf(&value).Elem()
+	}
+}
+
+// applyDefaults sets the default values of the inputs of funcs.
+func applyDefaults(funcs []*function, defaults map[reflect.Type]reflect.Value) {
+	for _, fn := range funcs {
+		for i, in := range fn.ins {
+			if def, ok := defaults[in.key]; ok && !in.injected() {
+				fn.ins[i].def = def
+			}
+		}
+	}
+}
+
+// unset returns the value of the parameter when no value of its type is
+// available: its default value if it has one, else an unset Optional if the
+// parameter is optional or its zero value if it accepts it. ok is false
+// otherwise, the function must then be skipped.
+func (in inputPlan) unset() (_ reflect.Value, ok bool) {
+	switch {
+	case in.def.IsValid() && in.optional:
+		return newOptional(in.typ, in.def), true
+	case in.def.IsValid():
+		return in.def, true
+	case in.optional, in.zero:
+		return reflect.Zero(in.typ), true
+	}
+	return reflect.Value{}, false
+}
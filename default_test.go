@@ -0,0 +1,104 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Default(t *testing.T) {
+	type (
+		region   string
+		locale   string
+		currency string
+		price    string
+	)
+
+	ngn, err := Initialize(
+		func(r region) Optional[locale] {
+			if r == "<unknown>" {
+				return Optional[locale]{}
+			}
+			return Optional[locale]{Val: locale("locale of " + r), IsSet: true}
+		},
+		func(l locale, c Optional[currency]) price { return price(string(l) + " in " + string(c.Val)) },
+		Default(locale("en-US")),
+		Default(currency("USD")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should not use the default of a produced value", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[price](context.Background(), ngn, region("<eu>"))
+		assert.NoError(t, err)
+		assert.Equal(t, price("locale of <eu> in USD"), out)
+	})
+
+	t.Run("should use the default if the producer does not set the value", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[price](context.Background(), ngn, region("<unknown>"))
+		assert.NoError(t, err)
+		assert.Equal(t, price("en-US in USD"), out)
+	})
+
+	t.Run("should use the default if no function produces the value", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		out, err := Run[price](context.Background(), ngn, WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, price("en-US in USD"), out)
+		assert.Equal(t, StatusSkipped, report.Functions[0].Status)
+	})
+
+	t.Run("should not use the default of a provided value", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[price](context.Background(), ngn, region("<eu>"), currency("EUR"))
+		assert.NoError(t, err)
+		assert.Equal(t, price("locale of <eu> in EUR"), out)
+	})
+
+	t.Run("should not return the default as the output of the run", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[locale](context.Background(), ngn, region("<unknown>"))
+		assert.NoError(t, err)
+		assert.Equal(t, locale(""), out)
+	})
+
+	t.Run("should keep the last default registered for a type", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(c currency) price { return price(c) },
+			Default(currency("USD")),
+			Default(currency("EUR")),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Run[price](context.Background(), ngn)
+		assert.NoError(t, err)
+		assert.Equal(t, price("EUR"), out)
+	})
+
+	t.Run("should fall back to the default of a lazy value once its producer is done", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(r region) Optional[locale] { return Optional[locale]{} },
+			func(ctx context.Context, l Lazy[locale]) (price, error) {
+				v, err := l.Get(ctx)
+				return price(v), err
+			},
+			Default(locale("en-US")),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Run[price](context.Background(), ngn, region("<eu>"))
+		assert.NoError(t, err)
+		assert.Equal(t, price("en-US"), out)
+	})
+}
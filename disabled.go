@@ -0,0 +1,47 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// WithDisabled switches off, for the run, the function producing T, as if
+// its flag was off, see Flagged: its outputs are not available and the
+// functions requiring them are skipped too, while those accepting them as
+// Optional run without. Degradation drills and A/B tests can so exercise the
+// run without a function without registering another engine. The report of
+// the run gives the reason as the SkipReason of the function.
+//
+// The run fails if no function of the run produces T.
+func WithDisabled[T any]() RunOption {
+	t, _ := unwrapOptional(reflect.TypeOf((*T)(nil)).Elem())
+	return func(c *runConfig) {
+		c.disabled = append(c.disabled, t)
+	}
+}
+
+// validateDisabled returns an error if a type disabled with WithDisabled is
+// not produced by a function of s.
+func validateDisabled(s *schedule, disabled []reflect.Type) error {
+	for _, t := range disabled {
+		produced := slices.ContainsFunc(s.funcs, func(fn *function) bool {
+			return slices.Contains(fn.valueOutputs(), t)
+		})
+		if !produced {
+			return fmt.Errorf("can not disable %s: no function of the run produces it", t)
+		}
+	}
+	return nil
+}
+
+// disables returns the output of fn disabled for the run, nil if there is
+// none.
+func (rs *runState) disables(fn *function) reflect.Type {
+	for _, outT := range fn.valueOutputs() {
+		if slices.Contains(rs.disabled, outT) {
+			return outT
+		}
+	}
+	return nil
+}
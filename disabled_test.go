@@ -0,0 +1,85 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithDisabled(t *testing.T) {
+	type (
+		query       string
+		results     string
+		ranked      string
+		suggestions string
+		page        string
+	)
+
+	ngn, err := Initialize(
+		func(q query) results { return results(q) },
+		func(r results) ranked { return "ranked " + ranked(r) },
+		func(q query) suggestions { return "suggestions" },
+		func(r ranked, s Optional[suggestions]) page {
+			if s.IsSet {
+				return page(string(r) + ", " + string(s.Val))
+			}
+			return page(r)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("runs every function by default", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[page](context.Background(), ngn, query("q"))
+		assert.NoError(t, err)
+		assert.Equal(t, page("ranked q, suggestions"), out)
+	})
+
+	t.Run("skips the function producing the disabled type", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		out, err := Run[page](context.Background(), ngn, query("q"), WithDisabled[suggestions](), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, page("ranked q"), out)
+		assert.Equal(t, StatusSkipped, report.Functions[2].Status)
+		assert.Equal(t, "warp_test.suggestions disabled for the run", report.Functions[2].SkipReason)
+		assert.Equal(t, StatusSucceeded, report.Functions[3].Status)
+	})
+
+	t.Run("skips the functions requiring the disabled type", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		out, err := Run[page](context.Background(), ngn, query("q"), WithDisabled[Optional[results]](), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+		for _, fr := range report.Functions {
+			if fr.Name == report.Functions[2].Name {
+				continue
+			}
+			assert.Equal(t, StatusSkipped, fr.Status, fr.Name)
+		}
+		assert.Empty(t, report.Functions[1].SkipReason)
+		assert.Equal(t, StatusSucceeded, report.Functions[2].Status)
+	})
+
+	t.Run("only disables for one run", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[page](context.Background(), ngn, query("q"), WithDisabled[suggestions]())
+		assert.NoError(t, err)
+
+		out, err := Run[page](context.Background(), ngn, query("q"))
+		assert.NoError(t, err)
+		assert.Equal(t, page("ranked q, suggestions"), out)
+	})
+
+	t.Run("fails if no function produces the disabled type", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[page](context.Background(), ngn, query("q"), WithDisabled[int]())
+		assertErr(t, err, "can not disable int: no function of the run produces it")
+	})
+}
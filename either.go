@@ -0,0 +1,105 @@
+package warp
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Either is an output holding a value of one of two types, made with Left or
+// Right. A function returning an Either[A, B] produces A and B as if it
+// returned an Optional[A] and an Optional[B] of which exactly one is set: the
+// functions requiring A run only if the function chose A, and the functions
+// requiring B only if it chose B.
+//
+//	func route(o Order) warp.Either[Express, Standard] {
+//		if o.Priority {
+//			return warp.Left[Express, Standard](Express{o})
+//		}
+//		return warp.Right[Express](Standard{o})
+//	}
+//
+// The zero Either holds neither value, as when the function fails: the
+// functions requiring either are skipped.
+type Either[A, B any] struct {
+	left    A
+	right   B
+	isLeft  bool
+	isRight bool
+}
+
+// Left returns the Either holding the value a of type A.
+func Left[A, B any](a A) Either[A, B] {
+	return Either[A, B]{left: a, isLeft: true}
+}
+
+// Right returns the Either holding the value b of type B.
+func Right[A, B any](b B) Either[A, B] {
+	return Either[A, B]{right: b, isRight: true}
+}
+
+// Left returns the value of type A and whether the Either holds it.
+func (e Either[A, B]) Left() (A, bool) {
+	return e.left, e.isLeft
+}
+
+// Right returns the value of type B and whether the Either holds it.
+func (e Either[A, B]) Right() (B, bool) {
+	return e.right, e.isRight
+}
+
+func (e Either[A, B]) optionals() (left, right any) {
+	return Optional[A]{Val: e.left, IsSet: e.isLeft}, Optional[B]{Val: e.right, IsSet: e.isRight}
+}
+
+type either interface {
+	optionals() (left, right any)
+}
+
+var eitherT = reflect.TypeOf((*either)(nil)).Elem()
+
+// isEither reports whether t is an Either type.
+func isEither(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(eitherT)
+}
+
+// expandEithers replaces the function of p, if it returns Either values, with
+// a view function returning the Optional values of both sides instead.
+func expandEithers(p *Provider) {
+	fnV := reflect.ValueOf(p.fn)
+	if p.err != nil || fnV.Kind() != reflect.Func || fnV.IsNil() {
+		return
+	}
+	fnT := fnV.Type()
+
+	var (
+		isEithers = make([]bool, fnT.NumOut())
+		viewOuts  []reflect.Type
+	)
+	for i, outT := range outputs(fnT) {
+		if !isEither(outT) {
+			viewOuts = append(viewOuts, outT)
+			continue
+		}
+		isEithers[i] = true
+		left, right := reflect.Zero(outT).Interface().(either).optionals()
+		viewOuts = append(viewOuts, reflect.TypeOf(left), reflect.TypeOf(right))
+	}
+	if !slices.Contains(isEithers, true) {
+		return
+	}
+
+	p.name, p.location = p.ref(), p.locate()
+	viewT := reflect.FuncOf(inputs(fnT), viewOuts, false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		results := make([]reflect.Value, 0, len(viewOuts))
+		for i, v := range fnV.Call(args) {
+			if !isEithers[i] {
+				results = append(results, v)
+				continue
+			}
+			left, right := v.Interface().(either).optionals()
+			results = append(results, reflect.ValueOf(left), reflect.ValueOf(right))
+		}
+		return results
+	}).Interface()
+}
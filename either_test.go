@@ -0,0 +1,110 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Either(t *testing.T) {
+	type (
+		order    string
+		express  string
+		standard string
+		label    string
+		tracking string
+		delivery string
+		results  struct {
+			Out
+
+			Route Either[express, standard]
+		}
+	)
+	ngn, err := Initialize(
+		func(o order) Either[express, standard] {
+			if o == "<priority>" {
+				return Left[express, standard](express(o))
+			}
+			return Right[express](standard(o))
+		},
+		func(e express) label { return label("express " + e) },
+		func(s standard) tracking { return tracking("standard " + s) },
+		func(l Optional[label], t Optional[tracking]) delivery {
+			if l.IsSet {
+				return delivery(l.Val)
+			}
+			return delivery(t.Val)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should run the consumers of the chosen side only", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		out, err := Run[delivery](context.Background(), ngn, order("<priority>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, delivery("express <priority>"), out)
+		assert.Equal(t, StatusSucceeded, report.Functions[1].Status)
+		assert.Equal(t, StatusSkipped, report.Functions[2].Status)
+
+		out, err = Run[delivery](context.Background(), ngn, order("<regular>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, delivery("standard <regular>"), out)
+		assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+		assert.Equal(t, StatusSucceeded, report.Functions[2].Status)
+	})
+
+	t.Run("should skip the consumers of both sides if neither is set", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(o order) (Either[express, standard], error) { return Either[express, standard]{}, nil },
+			func(e express) label { return label(e) },
+			func(s standard) tracking { return tracking(s) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[label](context.Background(), ngn, order("<o>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+		assert.Equal(t, StatusSkipped, report.Functions[2].Status)
+	})
+
+	t.Run("should expand the Either fields of result structs", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(o order) (results, error) {
+				if o == "" {
+					return results{}, errors.New("<empty order>")
+				}
+				return results{Route: Right[express](standard(o))}, nil
+			},
+			func(s standard) tracking { return tracking("standard " + s) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[tracking](context.Background(), ngn, order("<o>"))
+		assert.NoError(t, err)
+		assert.Equal(t, tracking("standard <o>"), out)
+	})
+
+	t.Run("should expose the chosen side", func(t *testing.T) {
+		t.Parallel()
+		e := Left[express, standard]("<e>")
+		l, isLeft := e.Left()
+		_, isRight := e.Right()
+		assert.Equal(t, express("<e>"), l)
+		assert.True(t, isLeft)
+		assert.False(t, isRight)
+	})
+}
@@ -4,19 +4,63 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
 // Engine is used to run a set of functions in the correct order and gather the output.
 type Engine struct {
-	functions   map[reflect.Type]runFunc
+	funcs       []*function
+	schedule    *schedule
+	producers   map[reflect.Type]*function
+	numSignals  int
+	values      *valueIndex
 	outputTypes map[reflect.Type]bool
+	adapted     map[reflect.Type]bool
 	initialized bool
+	// checkImmutability is set by WithImmutabilityCheck
+	checkImmutability bool
+	deepCopy          bool
+	mutationAudit     *mutationAudit
+	redactions        redactions
+	mode              RunMode
+	maxInvokeDepth    int
+	listeners         listeners
+	shed              *shedder
+	deadlineLogger    *slog.Logger
+	codecs            codecs
+	cooperative       *cooperation
+	pools             *workloadPools
+	onError           func(context.Context, FunctionError) ErrorDecision
+	retry             retryPolicy
+	watchdog          time.Duration
+	clock             clock
+	flags             FlagSource
+	fingerprint       string
+	// types and optionals index the value types by name, see indexTypes
+	types     map[string]reflect.Type
+	optionals map[reflect.Type]reflect.Type
+	// cfg and sources hold the settings and, per function, the Provider or
+	// Adapter the engine was initialized with, see Subgraph
+	cfg     *config
+	sources []any
+
+	mu        sync.Mutex
+	closed    bool
+	stopping  bool
+	owned     cleanups
+	done      chan struct{}
+	stopped   bool
+	inflight  map[uint64]inflightRun
+	nextRunID uint64
+	drained   chan struct{}
 }
 
 // Initialize returns a new Engine. It validates the functions and their
@@ -24,10 +68,11 @@ type Engine struct {
 //
 // * each function MUST:
 //   - be of type function.
-//   - return at least one non error output.
+//   - return at least one non error output, unless it is a Sink.
 //   - return at most one error output.
-//   - NOT accept an error type parameter.
-//   - NOT return a context.Context type output.
+//   - return at most one Cleanup output.
+//   - NOT accept an error or Cleanup type parameter.
+//   - NOT return a context.Context or Invoker type output.
 //   - NOT output any types that overlap with the function parameter types
 //   - NOT accept variadic parameters
 //   - NOT repeat paramater types
@@ -35,65 +80,206 @@ type Engine struct {
 // * all functions MUST:
 //   - NOT have overlapping output types.
 //   - NOT contain cyclic dependencies between function inputs and outputs
+//
+// The error returned joins the failures of every invalid function, so that a
+// large graph can be fixed in one pass. The validations across functions only
+// consider the functions that are valid on their own.
+//
+// Any of the functions may be wrapped in a Provider to change how the engine
+// runs it. Options, Adapters and the Services of Methods may be passed in any
+// position among the functions.
 func Initialize(fns ...any) (engine *Engine, err error) {
 	var (
-		fnVs []reflect.Value
-		out  = map[reflect.Type]bool{}
+		fnVs     []reflect.Value
+		out      = map[reflect.Type]bool{}
+		adapted  = map[reflect.Type]bool{}
+		cfg      *config
+		adapters []*Adapter
 	)
 
+	fns, cfg = splitOptions(fns)
+	fns, adapters = splitAdapters(splitServices(fns))
+
 	if err := validateAtLeastOneFunction(fns...); err != nil {
-		return nil, wrapValidationError(err)
+		return nil, err
+	}
+
+	// Every function is validated, so that all the invalid functions are
+	// reported at once. The functions failing validation are left out of the
+	// validations across functions.
+	var errs []error
+	providers := make([]*Provider, 0, len(fns))
+	for _, p := range asProviders(fns) {
+		expandParams(p)
+		expandOneOfs(p)
+		expandResults(p)
+		expandEithers(p)
+		if p.err != nil {
+			errs = append(errs, wrapValidationError(RuleInvalidProvider, p.err))
+			continue
+		}
+		providers = append(providers, p)
+	}
+
+	validAdapters := make([]*Adapter, 0, len(adapters))
+	for _, a := range adapters {
+		if err := validateAdapterNotNil(a); err != nil {
+			errs = append(errs, wrapValidationError(RuleInvalidAdapter, err))
+			continue
+		}
+		fnV := reflect.ValueOf(a.fn)
+		if err := validateDistinctInputOutputTypes(fnV.Type()); err != nil {
+			errs = append(errs, wrapValidationErrorWithInput(fnV, err))
+			continue
+		}
+		fnVs = append(fnVs, fnV)
+		_, to := a.types()
+		adapted[to] = true
+		validAdapters = append(validAdapters, a)
 	}
+	adapters = validAdapters
 
-	for _, fn := range fns {
-		fnV := reflect.ValueOf(fn)
-		fnT := reflect.TypeOf(fn)
+	validProviders := make([]*Provider, 0, len(providers))
+	for _, p := range providers {
+		fnV := reflect.ValueOf(p.fn)
+		fnT := reflect.TypeOf(p.fn)
 
-		for _, validator := range []func(reflect.Type) error{
+		validators := []func(reflect.Type) error{
 			validateTypeFunction,
 			validateFunctionHasOutputs,
 			validateFunctionHasAtLeastOneNonErrorValueOutput,
+		}
+		if p.sink {
+			validators = []func(reflect.Type) error{
+				validateTypeFunction,
+				validateSinkOutputs,
+			}
+		}
+		var invalid bool
+		for _, validator := range append(validators,
 			validateFunctionHasReturnsAtMostOneError,
+			validateFunctionHasReturnsAtMostOneCleanup,
 			validateFunctionInputsNotError,
+			validateFunctionInputsNotCleanup,
 			validateFunctionOutputsNotContext,
+			validateFunctionOutputsNotInvoker,
 			validateDistinctInputOutputTypes,
 			validateFunctionNotVariadic,
 			validateSameInputTypes,
-		} {
+		) {
 			if err := validator(fnT); err != nil {
-				return nil, wrapValidationErrorWithInput(fnV, err)
+				errs = append(errs, wrapProviderValidationError(p, err))
+				invalid = true
+				break
 			}
 		}
+		if invalid {
+			continue
+		}
 
 		fnVs = append(fnVs, fnV)
+		validProviders = append(validProviders, p)
 
 		for _, outT := range outputs(fnT) {
-			if !isType[error](outT) {
+			if isValueType(outT) {
 				out[outT] = true
 			}
 		}
 	}
-
-	if err := validateOutputTypesUnique(fns...); err != nil {
-		return nil, wrapValidationError(err)
+	providers = validProviders
+	fns = providerFuncs(providers)
+
+	errs = append(errs, validateOutputTypesUnique(fns...)...)
+	errs = append(errs, validateAdapters(fns, adapters)...)
+	errs = append(errs, validateFlags(providers, cfg.flags)...)
+	errs = append(errs, validateWorkloads(providers)...)
+
+	registered := len(adapters)
+	if cfg.interfaceBinding {
+		bindings, bindErrs := bindInterfaces(fns, adapters)
+		errs = append(errs, bindErrs...)
+		for _, a := range bindings {
+			fnVs = append(fnVs, reflect.ValueOf(a.fn))
+			_, to := a.types()
+			adapted[to] = true
+		}
+		adapters = append(adapters, bindings...)
 	}
 
 	if err := validateNoCyclicDependancies(fnVs); err != nil {
-		return nil, wrapValidationError(err)
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
-	return &Engine{
-		functions:   buildRunFuncs(fns...),
+	engine = &Engine{
 		outputTypes: out,
+		adapted:     adapted,
 		initialized: true,
-	}, nil
+		done:        make(chan struct{}),
+
+		checkImmutability: cfg.immutabilityCheck,
+		deepCopy:          cfg.deepCopy,
+		mutationAudit:     cfg.mutationAudit,
+		redactions:        newRedactions(cfg.redactions),
+		mode:              cfg.mode,
+		maxInvokeDepth:    cfg.maxInvokeDepth,
+		listeners:         cfg.listeners,
+		shed:              cfg.shed,
+		flags:             cfg.flags,
+		deadlineLogger:    cfg.deadlineLogger,
+		codecs:            cfg.codecs,
+		cooperative:       cfg.cooperative,
+		pools:             newWorkloadPools(providers, cfg),
+		onError:           cfg.onError,
+		retry:             cfg.retry,
+		watchdog:          cfg.watchdog,
+		clock:             cfg.clock,
+	}
+	engine.funcs = engine.buildRunFuncs(providers)
+	for _, a := range adapters {
+		engine.funcs = append(engine.funcs, buildAdapterFunction(a))
+	}
+	engine.cfg, engine.sources = cfg, make([]any, len(engine.funcs))
+	for i, p := range providers {
+		engine.sources[i] = p
+	}
+	for i, a := range adapters[:registered] {
+		engine.sources[len(providers)+i] = a
+	}
+	engine.producers = producers(engine.funcs)
+	engine.numSignals, engine.values = compilePlan(engine.funcs)
+	applyDefaults(engine.funcs, cfg.defaults)
+	engine.schedule = newSchedule(engine.funcs, engine.numSignals)
+	engine.fingerprint = fingerprint(engine.funcs)
+	engine.types, engine.optionals = indexTypes(engine.funcs)
+	for _, fn := range engine.funcs {
+		if est, ok := cfg.latencies[fn.name]; ok {
+			fn.latency.seed(est)
+		}
+	}
+
+	if cfg.coldLogger != nil && cfg.coldInterval > 0 {
+		go engine.logColdFunctions(cfg.coldLogger, cfg.coldInterval, engine.done)
+	}
+
+	return engine, nil
 }
 
 // Run executes the engine functions in the order determined by their dependencies. It returns the output
-// of each function where the type matches the generic type T.
+// of the function producing the generic type T. Output types are unique across functions, so the output
+// does not depend on the order in which functions ran. If the function returns an Optional[T], the value
+// is returned if set. The zero value of T is returned if the function was skipped. Outputs of other
+// types are only returned under a Conversion set with WithConversion.
 //
 // If any function returns an error, the execution is stopped and the error is returned.
 //
+// Any Cleanup returned by the functions is invoked in reverse dependency order once all
+// functions have returned, after the Teardown callbacks of a run made WithTeardown. Cleanup
+// and teardown errors are joined with the run error.
+//
 // If the engine has not been initialized, an error is returned.
 //
 // If any of the provided input types are duplicated or match any of the function output types,
@@ -101,115 +287,485 @@ func Initialize(fns ...any) (engine *Engine, err error) {
 //
 // If the engine cannot provide a value for a function input from either provided inputs or
 // returned function values, the functions execution is skipped.
+//
+// RunOptions may be passed in any position among the provided inputs.
 func Run[T any](ctx context.Context, e *Engine, provided ...any) (T, error) {
-	// Init zero T value
-	var out T
 	if e == nil || !e.initialized {
-		return out, errors.New("error running engine that has not been initialized")
+		var out T
+		return out, categorize(ErrNotInitialized, "error running engine that has not been initialized")
 	}
 
-	// Validate provided inputs
-	err := validateProvided(out, provided, e.outputTypes)
+	return run[T](ctx, e, e.schedule, provided)
+}
+
+// run executes the given subset of the engine functions.
+func run[T any](ctx context.Context, e *Engine, s *schedule, args []any) (out T, err error) {
+	provided, cfg := splitRunOptions(args)
+
+	ctx, finish, err := e.startRun(withRunInfo(ctx, cfg), cfg.invocations != nil)
 	if err != nil {
 		return out, err
 	}
-
-	// Initialize storage with provided inputs
-	storage := &sync.Map{}
-	for _, in := range provided {
-		inT := reflect.TypeOf(in)
-		inTU, _ := unwrapOptional(inT)
-		storage.Store(inTU, reflect.ValueOf(in))
+	defer finish()
+	ctx = withClock(ctx, e.clock)
+	ctx, teardowns := withTeardowns(ctx, cfg)
+	if e.shed != nil {
+		started := e.clock.now()
+		defer func() { e.shed.observe(e.clock.since(started)) }()
 	}
 
-	// Initialize a channel for each output type
-	notifiers := map[reflect.Type]chan struct{}{}
-	for outT := range e.outputTypes {
-		outTU, _ := unwrapOptional(outT)
-		notifiers[outTU] = make(chan struct{})
+	// Validate provided inputs against the outputs returned for T
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	outTs, err := e.returned(s, target, cfg)
+	if err != nil {
+		return out, err
+	}
+	err = validateProvided(outTs, provided, e.producers, e.adapted)
+	if err != nil {
+		return out, err
+	}
+	if err := s.produced(e.values, outTs); err != nil {
+		return out, err
+	}
+	if err := validateDisabled(s, cfg.disabled); err != nil {
+		return out, err
 	}
 
-	// Run functions
-	eg, ctx := errgroup.WithContext(ctx)
-	for _, fn := range e.functions {
-		eg.Go(fn(ctx, storage, notifiers))
+	rs := s.states.get(s, e.values)
+	defer rs.release(s)
+	rs.provide(provided)
+	for t, v := range cfg.restored {
+		rs.storage.Store(t, v)
+	}
+	if cfg.checkpoint != nil {
+		defer func() { *cfg.checkpoint = e.checkpoint(rs.storage, target) }()
+	}
+	if e.checkImmutability || e.mutationAudit != nil {
+		rs.hashes = newValueHashes(provided)
+	}
+	rs.mutations = e.mutationAudit.newRun()
+	if cfg.report != nil || e.deadlineLogger != nil {
+		rs.report = newRunReport(s.funcs, e.redactions, e.clock)
+		rs.report.recordProvided(rs.storage)
+		defer func() {
+			report := rs.report.report(err)
+			report.RunID, _ = RunIDFromContext(ctx)
+			if cfg.report != nil {
+				*cfg.report = report
+			}
+			if e.deadlineLogger != nil && errors.Is(err, context.DeadlineExceeded) {
+				logDeadlineReport(e.deadlineLogger, reflect.TypeOf((*T)(nil)).Elem(), report)
+			}
+		}()
 	}
 
-	// Wait for all functions to complete
-	if err := eg.Wait(); err != nil {
+	// Run functions as their inputs become available
+	rs.mode = e.mode
+	if cfg.mode != nil {
+		rs.mode = *cfg.mode
+	}
+	if err := rs.mode.validate(); err != nil {
+		return out, err
+	}
+	if rs.mode.Execution == Sequential {
+		rs.sequential = newGate(1)
+	}
+	rs.handle = cfg.handle
+	rs.outputs = outTs
+	if cfg.requireTarget {
+		rs.targets = outTs
+	}
+	rs.listeners = e.listeners
+	rs.onError, rs.retry = e.onError, e.retry
+	rs.budget = newFailureBudget(cfg.maxFailures)
+	rs.progress = newProgress(cfg.progress)
+	rs.faults = cfg.faults
+	rs.clock = e.clock
+	rs.flags, rs.disabled = e.flags, cfg.disabled
+	rs.cooperative, rs.slots = e.cooperative, e.cooperative.slots()
+	rs.pools = e.pools
+	if e.shed.overloaded(e.inflightRuns()) {
+		rs.shed = e.shed
+		rs.report.degrade()
+	}
+	rs.invoker = Invoker{e: e, maxDepth: e.maxInvokeDepth, chain: cfg.invocations}
+	if cfg.invocations == nil {
+		rs.invoker.chain = []invocation{newInvocation[T](provided)}
+	}
+	if len(rs.listeners) > 0 {
+		started := e.clock.now()
+		rs.listeners.runStart(ctx, RunEvent{Target: target})
+		defer func() {
+			rs.listeners.runEnd(ctx, RunEvent{Target: target, Err: err, Duration: e.clock.since(started)})
+		}()
+	}
+	eg, egCtx := &errgroup.Group{}, ctx
+	switch rs.mode.Failure {
+	case FailFast:
+		eg, egCtx = errgroup.WithContext(ctx)
+	case FailDrain:
+		eg, rs.halted = errgroup.WithContext(ctx)
+	}
+	egCtx, cancel := rs.withShortCircuit(egCtx, cfg)
+	defer cancel(nil)
+	if err := rs.start(egCtx, eg, s); err != nil {
 		return out, err
 	}
+	stopWatch := rs.watch(e.watchdog)
 
-	// Find output T
-	storage.Range(func(_ any, val any) bool {
-		valV := val.(reflect.Value)
-		valT := valV.Type()
-		valTU, _ := unwrapOptional(valT)
-		if e.outputTypes[valTU] {
-			// Return first output that matches T
-			if valTU == reflect.TypeOf((*T)(nil)).Elem() {
-				out = valV.Interface().(T)
-				return false
-			}
+	// Wait for all functions to complete, then release acquired resources
+	err = eg.Wait()
+	stopWatch()
+	if rs.mode.Failure == FailAggregate {
+		err = errors.Join(rs.errs...)
+	}
+	rs.mutations.finish(ctx, rs.hashes, rs.report)
+	if e.checkImmutability {
+		if mutationErr := rs.hashes.verify(s.funcs); mutationErr != nil {
+			err = errors.Join(err, mutationErr)
 		}
-		return true
-	})
+	}
+	if teardownErr := teardowns.run(ctx, err); teardownErr != nil {
+		err = errors.Join(err, teardownErr)
+	}
+	if cleanupErr := rs.cleanups.run(ctx); cleanupErr != nil {
+		err = errors.Join(err, cleanupErr)
+	}
+	if err != nil {
+		return out, err
+	}
+
+	// Load output T, stored under its own type by the single function
+	// producing it, converted if the run allows it
+	if cfg.collect {
+		out = collect[T](rs.storage, outTs)
+	} else if v, ok := loadValue(rs.storage, valuePlan(outTs[0])); ok {
+		out, _ = convert[T](v)
+	}
 
 	return out, nil
 }
 
-type runFunc = func(ctx context.Context, storage *sync.Map, notifiers map[reflect.Type]chan struct{}) func() error
+type runFunc = func(ctx context.Context, rs *runState) error
+
+// function describes a function registered with the engine.
+type function struct {
+	name     string
+	location string
+	inputs   []reflect.Type
+	outputs  []reflect.Type
+	ins      []inputPlan
+	outs     []outputPlan
+	tags     []string
+	// oneOf holds the positions of the inputs standing for the alternatives
+	// of every OneOf parameter, see OneOf
+	oneOf [][]int
+	// redactions are the per-function redactions, see Redacted
+	redactions redactions
+	run        runFunc
+	// call calls the function with its arguments, see caller
+	call func([]reflect.Value) []reflect.Value
+	// cached reports whether the outputs are available without running the
+	// function, nil if they never are.
+	cached func() bool
+	called atomic.Bool
+	// latency estimates the duration of the calls of the function
+	latency latencyEstimator
+	// stats aggregates the calls of the function, see Engine.Stats
+	stats functionStats
+	// sink is set for the functions producing no value, see Sink
+	sink bool
+	// bestEffort is set for the functions whose failures are tolerated, see
+	// BestEffort
+	bestEffort bool
+	// priority orders the ready functions waiting to execute, see
+	// Prioritized
+	priority int
+	// cost is the estimated duration of the function, see Estimated
+	cost time.Duration
+	// flag is the feature flag gating the function, see Flagged
+	flag string
+	// workload selects the pool the function executes on, see CPUBound
+	workload workload
+	// limit bounds the calls executing at once across runs, nil if they are
+	// not bounded, see Limited
+	limit *gate
+}
 
-func buildRunFuncs(fns ...any) map[reflect.Type]runFunc {
-	out := make(map[reflect.Type]runFunc, len(fns))
-	for _, fn := range fns {
-		fnV := reflect.ValueOf(fn)
-		fnT := reflect.TypeOf(fn)
+// produces reports whether fn stores a value of type t.
+func (fn *function) produces(t reflect.Type) bool {
+	for _, o := range fn.outs {
+		if o.key == t {
+			return true
+		}
+	}
+	return false
+}
+
+// valueOutputs returns the unwrapped types of the values stored by fn.
+func (fn *function) valueOutputs() []reflect.Type {
+	out := make([]reflect.Type, len(fn.outs))
+	for i, o := range fn.outs {
+		out[i] = o.key
+	}
+	return out
+}
+
+// producers maps every value type produced by funcs to the function producing
+// it.
+func producers(funcs []*function) map[reflect.Type]*function {
+	out := map[reflect.Type]*function{}
+	for _, fn := range funcs {
+		for _, outT := range fn.valueOutputs() {
+			out[outT] = fn
+		}
+	}
+	return out
+}
+
+// runState holds the values shared by all functions during a single run.
+type runState struct {
+	storage   *valueStore
+	cleanups  cleanups
+	report    *runReport
+	hashes    *valueHashes
+	mutations *runAudit
+	listeners listeners
+	invoker   Invoker
+	// shed is set if the run is degraded
+	shed *shedder
+	// flags tells whether the flags of the Flagged functions are on
+	flags FlagSource
+	// disabled holds the outputs whose functions are switched off, see
+	// WithDisabled
+	disabled []reflect.Type
+	// targets are set if the run must be able to produce them, see
+	// WithRequireTarget
+	targets []reflect.Type
+	// onError decides what to do with the errors of the functions, see
+	// OnError
+	onError func(context.Context, FunctionError) ErrorDecision
+	// retry bounds the retries decided by onError, see WithRetryLimit
+	retry retryPolicy
+	// budget counts the tolerated failures, see WithMaxFailures
+	budget *failureBudget
+	// progress reports the progress of the run, see WithProgress
+	progress *progress
+	// faults are injected into the calls of the functions, see WithFaults
+	faults faults
+	clock  clock
+	// cooperative and slots implement WithCooperativeExecution
+	cooperative *cooperation
+	slots       *gate
+	// pools are the workload pools of the engine, see CPUBound
+	pools *workloadPools
+
+	// scheduling state, see start
+	mode     RunMode
+	handle   *RunHandle
+	ctx      context.Context
+	eg       *errgroup.Group
+	schedule *schedule
+	// pooled holds the slices below, reused by the next run, see runSlots
+	pooled *runSlots
+	// pending counts, per function, the inputs not resolved yet
+	pending  []atomic.Int32
+	started  []atomic.Bool
+	resolved []atomic.Bool
+	// status holds, per function, the status recorded last
+	status []Status
+	// errs holds, per function, the error kept under FailAggregate
+	errs []error
+	// halted is cancelled on the first error under FailDrain, the functions
+	// not started yet are then not called
+	halted context.Context
+	// sequential is the gate of the Sequential execution policy, nil under
+	// Concurrent
+	sequential *gate
+	// outputs are the types returned by the run
+	outputs []reflect.Type
+	// shortCircuit cancels the run once the outputs are produced, see
+	// WithShortCircuit
+	shortCircuit context.CancelCauseFunc
+	unproduced   atomic.Int32
+	// state of the Lazy parameters, see lazyState
+	forced   []atomic.Bool
+	closed   []atomic.Bool
+	finished []chan struct{}
+}
+
+// provide stores the values the run starts with.
+func (rs *runState) provide(provided []any) {
+	for _, in := range provided {
+		inT, inV := providedValue(in)
+		inTU, _ := unwrapOptional(inT)
+		rs.storage.Store(inTU, inV)
+	}
+}
+
+func (e *Engine) buildRunFuncs(providers []*Provider) []*function {
+	funcs := make([]*function, 0, len(providers))
+	for _, p := range providers {
+		fnV := reflect.ValueOf(p.fn)
+		fnT := reflect.TypeOf(p.fn)
 		inputs := inputs(fnT)
 		outputs := outputs(fnT)
-		// Get position of context input, -1 if none
-		ctxPos := getPosOfType[context.Context](inputs)
+		fn := &function{
+			call:       caller(fnV),
+			name:       p.ref(),
+			location:   p.locate(),
+			inputs:     inputs,
+			outputs:    outputs,
+			ins:        planInputs(inputs),
+			outs:       planOutputs(outputs),
+			tags:       p.tags,
+			oneOf:      p.oneOf,
+			sink:       p.sink,
+			bestEffort: p.bestEffort,
+			priority:   p.priority,
+			cost:       p.cost,
+			flag:       p.flag,
+			workload:   p.workload,
+
+			redactions: newRedactions(p.redactions),
+		}
+		if p.limit > 0 {
+			fn.limit = newGate(p.limit)
+		}
+		for i, zero := range p.zero {
+			fn.ins[i].zero = zero
+		}
+		funcs = append(funcs, fn)
 		// Get position of error output, -1 if none
 		errPos := getPosOfType[error](outputs)
+		// Get position of cleanup output, -1 if none
+		cleanupPos := getPosOfType[Cleanup](outputs)
+		// Get positions of outputs still being produced once the function returns
+		var awaitPos []int
+		for i, outT := range outputs {
+			if outT.Implements(reflect.TypeOf((*awaiter)(nil)).Elem()) {
+				awaitPos = append(awaitPos, i)
+			}
+		}
+		// Singleton functions share their outputs across runs
+		var s *singleton
+		if p.singleton {
+			s = &singleton{}
+			fn.cached = func() bool {
+				_, ok := s.load()
+				return ok
+			}
+		}
 
-		out[fnT] = func(ctx context.Context, storage *sync.Map, notifiers map[reflect.Type]chan struct{}) func() error {
-			return func() error {
-				// NOTE: anything in this func happens at runtime
-				ins := make([]reflect.Value, 0, len(inputs))
-				for i, inT := range inputs {
-					if i == ctxPos {
-						ins = append(ins, reflect.ValueOf(ctx))
-						continue
-					}
-
-					if err := waitForSignal(ctx, notifiers, inT); err != nil {
-						return err
-					}
-
-					// Find the value in storage
-					v, ok := loadValue(storage, inT)
-					if !ok {
-						// Skip function if input is not available
-						closeNotifiers(notifiers, outputs...)
-						return nil
-					}
-					ins = append(ins, v)
+		fn.run = func(ctx context.Context, rs *runState) error {
+			// NOTE: anything in this func happens at runtime
+			if s != nil {
+				if outValues, ok := s.load(); ok {
+					rs.store(fn, outValues)
+					rs.resolve(fn)
+					rs.record(fn, StatusSucceeded, nil)
+					return nil
 				}
+			}
 
-				outValues := fnV.Call(ins)
-				if err := getError(outValues, errPos); err != nil {
-					return err
+			if p.resilient {
+				rec := &ResilienceReport{}
+				ctx = withResilienceReport(ctx, rec)
+				defer rs.report.recordResilience(fn, rec)
+			}
+			var shadow *shadowCall
+			if p.shadowed {
+				shadow = &shadowCall{}
+				ctx = withShadowCall(ctx, shadow)
+				defer func() {
+					// fn is not done until its shadow returns, even if it
+					// fails
+					rs.dispatch()
+					shadow.pending.Wait()
+				}()
+			}
+
+			ins := rs.args(fn)
+			for _, in := range fn.ins {
+				if in.context {
+					ins = append(ins, reflect.ValueOf(ctx))
+					continue
+				}
+				if in.invoker {
+					ins = append(ins, reflect.ValueOf(rs.invoker))
+					continue
+				}
+				if in.lazy {
+					ins = append(ins, rs.newLazy(fn, in))
+					continue
 				}
 
-				storeOutputs(storage, outValues, outputs)
+				// Find the value in storage
+				v, ok := loadValue(rs.storage, in)
+				if !ok {
+					// Skip function if input is not available
+					return rs.skip(fn, rs.missingInputs(fn))
+				}
+				if e.deepCopy {
+					v = deepCopy(v)
+				}
+				ins = append(ins, v)
+			}
+			if missing := fn.unavailable(ins); missing != nil {
+				// Skip function if no alternative of a OneOf is available
+				return rs.skip(fn, missing)
+			}
+			rs.report.recordFed(fn, rs.storage)
+
+			var (
+				outValues []reflect.Value
+				decision  ErrorDecision
+				err       error
+			)
+			if s != nil {
+				produce := func() (out []reflect.Value, err error) {
+					out, decision, err = rs.attempt(ctx, fn, ins, errPos)
+					return out, err
+				}
+				outValues, err = e.produceSingleton(ctx, s, produce, outputs, cleanupPos)
+				if err != nil {
+					return rs.failed(ctx, fn, err, decision)
+				}
+			} else {
+				outValues, decision, err = rs.attempt(ctx, fn, ins, errPos)
+				if err != nil {
+					return rs.failed(ctx, fn, err, decision)
+				}
 
-				closeNotifiers(notifiers, outputs...)
+				if cleanupPos != -1 {
+					rs.cleanups.add(outValues[cleanupPos].Interface().(Cleanup))
+				}
+			}
 
-				return nil
+			rs.store(fn, outValues)
+			rs.resolve(fn)
+			if len(awaitPos) > 0 || shadow != nil {
+				// The consumers run while fn waits
+				rs.dispatch()
+			}
+
+			// Wait for the outputs still being produced
+			for _, i := range awaitPos {
+				if err := outValues[i].Interface().(awaiter).await(ctx); err != nil {
+					rs.recordError(ctx, fn, err)
+					return err
+				}
 			}
+			if shadow != nil {
+				// Wait for the shadow to compare its outputs
+				rs.report.recordShadow(fn, shadow)
+			}
+
+			rs.record(fn, StatusSucceeded, nil)
+			return nil
 		}
 	}
-	return out
+	return funcs
 }
 
 func getError(outValues []reflect.Value, errPos int) error {
@@ -221,22 +777,30 @@ func getError(outValues []reflect.Value, errPos int) error {
 	return nil
 }
 
-func storeOutputs(storage *sync.Map, outValues []reflect.Value, outputs []reflect.Type) {
-	for i, outT := range outputs {
-		if !isType[error](outT) {
-			outTU, _ := unwrapOptional(outT)
-			storage.Store(outTU, outValues[i])
-		}
+// args returns the buffer of the arguments of fn, reused by the next runs.
+func (rs *runState) args(fn *function) []reflect.Value {
+	i := rs.schedule.pos[fn]
+	if cap(rs.pooled.args[i]) < len(fn.ins) {
+		rs.pooled.args[i] = make([]reflect.Value, 0, len(fn.ins))
 	}
+	return rs.pooled.args[i][:0]
 }
 
-func closeNotifiers(notifiers map[reflect.Type]chan struct{}, outputs ...reflect.Type) {
-	for _, outT := range outputs {
-		if !isType[error](outT) {
-			outTU, _ := unwrapOptional(outT)
-			close(notifiers[outTU])
-		}
+// call calls fn with ins, and audits the values fn was called with once it
+// returns.
+func (rs *runState) call(fn *function, ins []reflect.Value) []reflect.Value {
+	fn.called.Store(true)
+	defer rs.audit(fn)
+	return fn.call(ins)
+}
+
+// store stores the value outputs of fn.
+func (rs *runState) store(fn *function, outValues []reflect.Value) {
+	for _, out := range fn.outs {
+		rs.storage.storeSlot(out.signal, outValues[out.pos])
+		rs.hashes.track(out.key, outValues[out.pos], fn.name)
 	}
+	rs.report.recordValues(fn, outValues)
 }
 
 func convert[T any](v reflect.Value) (T, bool) {
@@ -272,79 +836,87 @@ func outputs(fn reflect.Type) []reflect.Type {
 	return out
 }
 
-// waitForSignal blocks if inT is available in the notifiers map,
-// it waits until it gets notified or the context is canceled.
-func waitForSignal(
-	ctx context.Context,
-	notifiers map[reflect.Type]chan struct{},
-	inT reflect.Type,
-) error {
-	inTU, _ := unwrapOptional(inT)
-	if _, ok := notifiers[inTU]; !ok {
-		return nil
-	}
-
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-notifiers[inTU]:
-		return nil
-	}
-}
-
 func loadValue(
-	storage *sync.Map,
-	inT reflect.Type,
+	storage *valueStore,
+	in inputPlan,
 ) (_ reflect.Value, ok bool) {
-	// Unwrap function input type if it is Optional[T]
-	inTU, isInTOptional := unwrapOptional(inT)
+	inT, isInTOptional := in.typ, in.optional
 
 	// Load value from storage
-	v, ok := storage.Load(inTU)
+	v, ok := storage.load(in)
 	if !ok {
-		// Return zero value if input is not available and allow function to run
-		if isInTOptional {
-			return reflect.Zero(inT), true
-		}
+		// Fall back to the default value, or to an unset Optional[T], if input
+		// is not available
+		return in.unset()
+	}
 
-		// Skip function if input is not available and not Optional[T]
-		return reflect.Value{}, false
+	// Rebuild the input from the value of an Optional of another type, such
+	// as a type derived from Optional[T]
+	if isInTOptional && v.Type() != inT && isOptional(v.Type()) {
+		if !v.FieldByName("IsSet").Bool() {
+			return in.unset()
+		}
+		return newOptional(inT, v.FieldByName("Val")), true
 	}
 
 	// Wrap value in Optional[T] if function input type is Optional[T] and value is NOT also Optional[T]
-	if isInTOptional && v.(reflect.Value).Type() != inT {
-		return newOptional(inT, v.(reflect.Value)), true
+	if isInTOptional && v.Type() != inT {
+		return newOptional(inT, v), true
 	}
 
 	// if function input type is T and value is Optional[T]
-	if !isInTOptional && isOptional(v.(reflect.Value).Type()) {
-		if v.(reflect.Value).FieldByName("IsSet").Bool() {
+	if !isInTOptional && isOptional(v.Type()) {
+		if v.FieldByName("IsSet").Bool() {
 			// Unwrap value
-			return v.(reflect.Value).FieldByName("Val"), true
+			return v.FieldByName("Val"), true
 		}
-		// Skip function if input is Optional but not set
-		return reflect.Value{}, false
+		// Input is Optional but not set
+		return in.unset()
 	}
 
 	// Both input type and value are Optional[T]
-	if isInTOptional && v.(reflect.Value).Type() == inT {
-		// Set value to empty if Optional[T] is not set
-		if !v.(reflect.Value).FieldByName("IsSet").Bool() {
-			return reflect.Zero(inT), true
+	if isInTOptional && v.Type() == inT {
+		// Input is Optional but not set
+		if !v.FieldByName("IsSet").Bool() {
+			return in.unset()
 		}
-		// Unwrap value
-		return v.(reflect.Value).FieldByName("Val"), true
+		// Pass the Optional[T] value as is
+		return v, true
 	}
 
-	return v.(reflect.Value), true
+	return v, true
 }
 
 func wrapValidationErrorWithInput(badInput reflect.Value, err error) error {
-	return fmt.Errorf("input %s caused validation error: %w", referTo(badInput), err)
+	return attributeValidationError(referTo(badInput), err)
+}
+
+// wrapProviderValidationError refers to the function registered by p, rather
+// than to the view function the engine runs.
+func wrapProviderValidationError(p *Provider, err error) error {
+	return attributeValidationError(p.ref(), err)
 }
 
-func wrapValidationError(err error) error {
-	return fmt.Errorf("input validation error: %w", err)
+// attributeValidationError returns a copy of the ValidationError err
+// attributed to the function referred to by name.
+func attributeValidationError(name string, err error) error {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		verr = &ValidationError{Detail: err.Error(), err: err}
+	}
+	attributed := *verr
+	attributed.Func = name
+	return &attributed
+}
+
+// wrapValidationError returns err as a ValidationError breaking rule, unless
+// it already is one.
+func wrapValidationError(rule Rule, err error) error {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr
+	}
+	return &ValidationError{Rule: rule, Detail: err.Error(), err: err}
 }
 
 func referTo(rv reflect.Value) string {
@@ -358,11 +930,23 @@ func referTo(rv reflect.Value) string {
 	return reference
 }
 
+// locate returns the file:line source location of a function.
+func locate(fnV reflect.Value) string {
+	file, line := runtime.FuncForPC(fnV.Pointer()).FileLine(fnV.Pointer())
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
 func isType[T any](in reflect.Type) bool {
 	needle := reflect.TypeOf((*T)(nil)).Elem()
 	return in == needle
 }
 
+// isValueType reports whether a function output type is a value stored by the
+// engine, as opposed to the error and Cleanup outputs it handles itself.
+func isValueType(t reflect.Type) bool {
+	return !isType[error](t) && !isType[Cleanup](t)
+}
+
 func sliceConvert[T any, V any](f func(T) V, in []T) []V {
 	out := make([]V, len(in))
 	for i := range in {
@@ -380,31 +964,23 @@ func getPosOfType[T any](in []reflect.Type) int {
 	return -1
 }
 
-func validateProvided(out any, provided []any, outputs map[reflect.Type]bool) error {
-	// Unwrap any Optional[T] output types
-	outputsU := map[reflect.Type]bool{}
-	var canBeOutput bool
-	for outT := range outputs {
-		outTU, _ := unwrapOptional(outT)
-		outputsU[outTU] = true
-		if outTU == reflect.TypeOf(out) {
-			canBeOutput = true
+func validateProvided(outTs []reflect.Type, provided []any, producers map[reflect.Type]*function, adapted map[reflect.Type]bool) error {
+	for _, outT := range outTs {
+		if _, canBeOutput := producers[outT]; !canBeOutput {
+			return categorize(ErrTargetNotProducible, "output type %s does not match any provided input types", outT)
 		}
 	}
-	if !canBeOutput {
-		return fmt.Errorf("output type %s does not match any provided input types", reflect.TypeOf(out))
-	}
 
 	checked := map[reflect.Type]bool{}
 	for _, in := range provided {
-		inT := reflect.TypeOf(in)
+		inT, _ := providedValue(in)
 		inTU, _ := unwrapOptional(inT)
 		if alreadyChecked := checked[inT]; alreadyChecked {
-			return fmt.Errorf("duplicate provided input type: %s", inTU)
+			return categorize(ErrDuplicateInput, "duplicate provided input type: %s", inTU)
 		}
 
-		if outputsU[inTU] {
-			return fmt.Errorf("provided input type matches function output type: %s", inTU)
+		if _, ok := producers[inTU]; ok && !adapted[inTU] {
+			return categorize(ErrInputMatchesOutput, "provided input type matches function output type: %s", inTU)
 		}
 
 		checked[inT] = true
@@ -311,6 +311,34 @@ func Test_EngineInit(t *testing.T) {
 
 		assertErrContains(t, err, "must not be a variadic function")
 	})
+
+	t.Run("should return the errors of every invalid function", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			"<not-a-function>",
+			func(context.Context, ...inType) (outType1, error) {
+				return "", nil
+			},
+			func(inType) outType2 {
+				return ""
+			},
+			func(inType) (outType2, outType3) {
+				return "", ""
+			},
+			func(outType3) outType4 {
+				return ""
+			},
+			func(inType) outType4 {
+				return ""
+			},
+		)
+
+		assert.Equal(t, []Rule{RuleNotFunction, RuleVariadic, RuleDuplicateOutput, RuleDuplicateOutput}, rules(err))
+		assertErrContains(t, err, "input string caused validation error: all inputs must be functions")
+		assertErrContains(t, err, "caused validation error: must not be a variadic function")
+		assertErrContains(t, err, "input validation error: output value type warp_test.outType2 already provided to the engine by")
+		assertErrContains(t, err, "input validation error: output value type warp_test.outType4 already provided to the engine by")
+	})
 }
 
 type (
@@ -621,7 +649,8 @@ func Test_EngineRun(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
 		out, err := Run[outType4](
 			ctx,
 			ngn,
@@ -1430,6 +1459,36 @@ func Test_EngineRun(t *testing.T) {
 
 		})
 
+		t.Run("when value is set and function input is optional", func(t *testing.T) {
+			t.Parallel()
+			ngn, err := Initialize(
+				func(in Optional[inType1]) outType1 {
+					return outType1{in.Val.ValueIn1 + "<outType1>"}
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			out, err := Run[outType1](
+				ctx,
+				ngn,
+				Optional[inType1]{
+					Val:   inType1{"<inType1>"},
+					IsSet: true,
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expected := "<inType1><outType1>"; out.ValueOut1 != expected {
+				t.Fatalf("expected output value '%s', got '%s'", expected, out)
+			}
+		})
+
 	})
 
 	t.Run("should return an error if a type of provided inputs matches another function", func(t *testing.T) {
@@ -1510,6 +1569,40 @@ func Test_EngineRun(t *testing.T) {
 		})
 	})
 
+	t.Run("should return the value of an optional output", func(t *testing.T) {
+		t.Run("when value is set", func(t *testing.T) {
+			t.Parallel()
+			ngn, err := Initialize(
+				func(in inType1) Optional[outType1] {
+					return Optional[outType1]{Val: outType1{in.ValueIn1 + "<outType1>"}, IsSet: true}
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := Run[outType1](context.Background(), ngn, inType1{"<inType1>"})
+			assert.NoError(t, err)
+			assert.Equal(t, outType1{"<inType1><outType1>"}, out)
+		})
+
+		t.Run("when value is NOT set", func(t *testing.T) {
+			t.Parallel()
+			ngn, err := Initialize(
+				func(in inType1) Optional[outType1] {
+					return Optional[outType1]{}
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := Run[outType1](context.Background(), ngn, inType1{"<inType1>"})
+			assert.NoError(t, err)
+			assert.Equal(t, outType1{}, out)
+		})
+	})
+
 	t.Run("should not execute downstream function if an upstream function did not run", func(t *testing.T) {
 		t.Parallel()
 		var count atomic.Int32
@@ -0,0 +1,54 @@
+package warp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors categorizing the failures of the engine API, matched with
+// errors.Is. The errors returned keep describing the failure in details.
+var (
+	// ErrNotInitialized is returned when using an engine that was not
+	// returned by Initialize.
+	ErrNotInitialized = errors.New("engine has not been initialized")
+	// ErrDuplicateInput is returned by a run provided with two values of
+	// the same type.
+	ErrDuplicateInput = errors.New("duplicate provided input")
+	// ErrInputMatchesOutput is returned by a run provided with a value of a
+	// type produced by a function of the engine.
+	ErrInputMatchesOutput = errors.New("provided input matches a function output")
+	// ErrTargetNotProducible is returned when the target type of a run, of
+	// a compiled runner or of an explanation is produced by no function, or
+	// can not be produced by the run under WithRequireTarget.
+	ErrTargetNotProducible = errors.New("target can not be produced")
+	// ErrNotExactlyOne is returned by RunOne when the run produced no value
+	// or several values of its target type.
+	ErrNotExactlyOne = errors.New("not exactly one value produced")
+	// ErrClosed is returned by a run of an engine that has been closed, see
+	// Engine.Close.
+	ErrClosed = errors.New("engine closed")
+	// ErrShuttingDown is returned by a run of an engine that is shutting
+	// down, see Engine.Shutdown.
+	ErrShuttingDown = errors.New("engine shutting down")
+)
+
+// categorizedError is an error of one of the categories of the sentinel
+// errors, with its own message.
+type categorizedError struct {
+	msg      string
+	category error
+}
+
+func (e *categorizedError) Error() string {
+	return e.msg
+}
+
+func (e *categorizedError) Is(target error) bool {
+	return target == e.category
+}
+
+// categorize returns the error formatted from format and args that matches
+// category with errors.Is.
+func categorize(category error, format string, args ...any) error {
+	return &categorizedError{msg: fmt.Sprintf(format, args...), category: category}
+}
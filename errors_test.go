@@ -0,0 +1,66 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_SentinelErrors(t *testing.T) {
+	ngn, err := Initialize(
+		func(inType) outType1 { return "" },
+		func(outType1, outType2) outType3 { return "" },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should categorize the use of an engine not initialized", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[outType1](context.Background(), &Engine{})
+		assert.ErrorIs(t, err, ErrNotInitialized)
+		assertErr(t, err, "error running engine that has not been initialized")
+
+		_, err = Compile[outType1](nil)
+		assert.ErrorIs(t, err, ErrNotInitialized)
+	})
+
+	t.Run("should categorize a duplicate provided input", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[outType1](context.Background(), ngn, inType("<a>"), inType("<b>"))
+		assert.ErrorIs(t, err, ErrDuplicateInput)
+		assert.NotErrorIs(t, err, ErrInputMatchesOutput)
+	})
+
+	t.Run("should categorize a provided input matching an output", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[outType3](context.Background(), ngn, outType1("<a>"))
+		assert.ErrorIs(t, err, ErrInputMatchesOutput)
+	})
+
+	t.Run("should categorize a target that can not be produced", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[outType4](context.Background(), ngn)
+		assert.ErrorIs(t, err, ErrTargetNotProducible)
+
+		_, err = Run[outType3](context.Background(), ngn, inType("<a>"), WithRequireTarget())
+		assert.ErrorIs(t, err, ErrTargetNotProducible)
+		assertErrContains(t, err, "input warp_test.outType2 is not available")
+	})
+
+	t.Run("should categorize a run of a closed engine", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(inType) outType1 { return "" })
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, ngn.Close())
+
+		_, err = Run[outType1](context.Background(), ngn, inType("<a>"))
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.NotErrorIs(t, err, ErrShuttingDown)
+	})
+}
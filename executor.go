@@ -0,0 +1,136 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Executor invokes functions of the engine on behalf of a run, typically by
+// dispatching them to remote workers over HTTP, gRPC or a queue. The engine
+// keeps ordering the functions locally: it resolves the inputs of a function
+// before calling Execute and stores its outputs once Execute returns.
+type Executor interface {
+	// Execute invokes the function described by call and returns its
+	// results, one per result of the function and in the same order, the
+	// error result included. A non-nil error fails the function as if it
+	// had returned it.
+	Execute(ctx context.Context, call Call) ([]any, error)
+}
+
+// ExecutorFunc is a function implementing Executor.
+type ExecutorFunc func(ctx context.Context, call Call) ([]any, error)
+
+func (f ExecutorFunc) Execute(ctx context.Context, call Call) ([]any, error) {
+	return f(ctx, call)
+}
+
+// Call is the invocation of a function dispatched to an Executor.
+type Call struct {
+	// Name is the name of the function.
+	Name string
+	// Location is the file:line source location of the function.
+	Location string
+	// Args holds the arguments of the function, one per parameter and in
+	// the same order. Injected parameters, such as the context.Context or
+	// the Invoker, are local to the run and can not be sent to a worker.
+	Args []any
+
+	fn reflect.Value
+}
+
+// Local invokes the function in the current process and returns its results.
+// Executors fall back to it, for example when no worker is available.
+func (c Call) Local() []any {
+	ins := make([]reflect.Value, len(c.Args))
+	for i, arg := range c.Args {
+		ins[i] = valueOf(c.fn.Type().In(i), arg)
+	}
+	return sliceConvert(reflect.Value.Interface, c.fn.Call(ins))
+}
+
+// Remote registers fn to be invoked by executor instead of being called by
+// the run. The function keeps its name, and its outputs are stored and
+// consumed like those of any other function. When fn does not return an
+// error, the failures of executor still fail the run.
+func Remote(fn any, executor Executor) *Provider {
+	fnV := reflect.ValueOf(fn)
+	p := &Provider{fn: fn}
+	if fn == nil || fnV.Kind() != reflect.Func {
+		p.err = errors.New("remote input must be a function")
+		return p
+	}
+	if executor == nil {
+		p.err = errors.New("remote executor must not be nil")
+		return p
+	}
+	p.name, p.location = referTo(fnV), locate(fnV)
+
+	// The view function accepts the run context, in first position unless fn
+	// already accepts one, and returns an error, last unless fn already
+	// returns one
+	fnT := fnV.Type()
+	ins, ctxPos := inputs(fnT), getPosOfType[context.Context](inputs(fnT))
+	if ctxPos == -1 {
+		ins = append([]reflect.Type{reflect.TypeOf((*context.Context)(nil)).Elem()}, ins...)
+	}
+	outs, errPos := outputs(fnT), getPosOfType[error](outputs(fnT))
+	if errPos == -1 {
+		outs, errPos = append(outs, reflect.TypeOf((*error)(nil)).Elem()), len(outs)
+	}
+	viewT := reflect.FuncOf(ins, outs, fnT.IsVariadic())
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		ctx := args[max(ctxPos, 0)].Interface().(context.Context)
+		if ctxPos == -1 {
+			args = args[1:]
+		}
+		call := Call{
+			Name:     p.name,
+			Location: p.location,
+			Args:     sliceConvert(reflect.Value.Interface, args),
+			fn:       fnV,
+		}
+
+		results, err := executor.Execute(ctx, call)
+		if err == nil {
+			out, err := remoteResults(fnT, results)
+			if err == nil {
+				if len(out) < len(outs) {
+					out = append(out, reflect.Zero(outs[errPos]))
+				}
+				return out
+			}
+			return failedResults(outs, errPos, fmt.Errorf("function %s: %w", p.name, err))
+		}
+		return failedResults(outs, errPos, err)
+	}).Interface()
+	return p
+}
+
+// remoteResults returns the results of a function of type fnT returned by an
+// Executor as values of the result types.
+func remoteResults(fnT reflect.Type, results []any) ([]reflect.Value, error) {
+	if len(results) != fnT.NumOut() {
+		return nil, fmt.Errorf("executor returned %d results, expected %d", len(results), fnT.NumOut())
+	}
+	out := make([]reflect.Value, len(results))
+	for i, res := range results {
+		outT := fnT.Out(i)
+		if res != nil && !reflect.TypeOf(res).AssignableTo(outT) {
+			return nil, fmt.Errorf("executor returned a %T for result %d, expected %s", res, i, outT)
+		}
+		out[i] = valueOf(outT, res)
+	}
+	return out, nil
+}
+
+// failedResults returns the zero values of outs holding err at errPos.
+func failedResults(outs []reflect.Type, errPos int, err error) []reflect.Value {
+	out := make([]reflect.Value, len(outs))
+	for i, outT := range outs {
+		out[i] = reflect.Zero(outT)
+	}
+	out[errPos] = reflect.ValueOf(&err).Elem()
+	return out
+}
@@ -0,0 +1,122 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Remote(t *testing.T) {
+	type (
+		jobID  string
+		result string
+		digest string
+	)
+	process := func(id jobID) result { return result("processed " + id) }
+
+	t.Run("should dispatch the function to the executor", func(t *testing.T) {
+		t.Parallel()
+		var calls []Call
+		executor := ExecutorFunc(func(ctx context.Context, call Call) ([]any, error) {
+			calls = append(calls, call)
+			return []any{result("remote " + call.Args[0].(jobID))}, nil
+		})
+		ngn, err := Initialize(
+			Remote(process, executor),
+			func(r result) digest { return digest("digest of " + r) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[digest](context.Background(), ngn, jobID("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, digest("digest of remote <id>"), out)
+		if assert.Len(t, calls, 1) {
+			assert.Contains(t, calls[0].Name, "Test_Remote")
+			assert.NotEmpty(t, calls[0].Location)
+			assert.Equal(t, []any{jobID("<id>")}, calls[0].Args)
+		}
+	})
+
+	t.Run("should invoke the function locally", func(t *testing.T) {
+		t.Parallel()
+		var dispatched atomic.Int32
+		executor := ExecutorFunc(func(ctx context.Context, call Call) ([]any, error) {
+			dispatched.Add(1)
+			return call.Local(), nil
+		})
+		ngn, err := Initialize(Remote(func(ctx context.Context, id jobID) (result, error) {
+			return process(id), ctx.Err()
+		}, executor))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[result](context.Background(), ngn, jobID("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, result("processed <id>"), out)
+		assert.Equal(t, int32(1), dispatched.Load())
+	})
+
+	t.Run("should fail the run with the error of the executor", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Remote(process, ExecutorFunc(func(context.Context, Call) ([]any, error) {
+			return nil, errors.New("<worker unavailable>")
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[result](context.Background(), ngn, jobID("<id>"))
+		assertErr(t, err, "<worker unavailable>")
+	})
+
+	t.Run("should fail the run with the error returned by the function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Remote(func(jobID) (result, error) { return "", nil }, ExecutorFunc(func(context.Context, Call) ([]any, error) {
+			return []any{result(""), errors.New("<job failed>")}, nil
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[result](context.Background(), ngn, jobID("<id>"))
+		assertErr(t, err, "<job failed>")
+	})
+
+	t.Run("should fail the run with results not matching the function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Remote(process, ExecutorFunc(func(context.Context, Call) ([]any, error) {
+			return []any{digest("<digest>")}, nil
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Run[result](context.Background(), ngn, jobID("<id>"))
+		assertErrContains(t, err, "executor returned a warp_test.digest for result 0, expected warp_test.result")
+
+		ngn, err = Initialize(Remote(process, ExecutorFunc(func(context.Context, Call) ([]any, error) {
+			return nil, nil
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Run[result](context.Background(), ngn, jobID("<id>"))
+		assertErrContains(t, err, "executor returned 0 results, expected 1")
+	})
+
+	t.Run("should reject invalid remote functions", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Remote("<not-a-function>", ExecutorFunc(nil)))
+		assertErr(t, err, "input validation error: remote input must be a function")
+
+		_, err = Initialize(Remote(process, nil))
+		assertErr(t, err, "input validation error: remote executor must not be nil")
+	})
+}
@@ -0,0 +1,81 @@
+package warp
+
+import (
+	"context"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Fault is a failure or a latency injected into the calls of the function
+// producing a type, see WithFaults.
+type Fault struct {
+	// Output is the type produced by the faulty function. Optional types are
+	// unwrapped.
+	Output reflect.Type
+	// Err, if set, is returned by the calls instead of calling the function.
+	Err error
+	// Latency delays the calls. A call is not made if the run is cancelled
+	// meanwhile.
+	Latency time.Duration
+	// Probability is the probability of a call to be faulty, every call if
+	// zero.
+	Probability float64
+}
+
+// FaultOf returns the Fault failing the calls of the function producing T
+// with err, unless err is nil, after a delay of latency.
+func FaultOf[T any](err error, latency time.Duration) Fault {
+	return Fault{Output: reflect.TypeOf((*T)(nil)).Elem(), Err: err, Latency: latency}
+}
+
+// WithFaults injects faults into the calls of the functions of the run, so
+// that tests exercise the handling of failures and slow dependencies, such as
+// OnError retries, BestEffort functions or WithMaxFailures, without modifying
+// the functions. Every retry of a function is a new call, which may or may not
+// be faulty. A Resilient function is faulted as a whole, its own retries and
+// fallback included.
+//
+// An injected error is handled as if the function had returned it, whether
+// it returns an error or not.
+func WithFaults(faults ...Fault) RunOption {
+	return func(c *runConfig) {
+		c.faults = append(c.faults, faults...)
+	}
+}
+
+// faults are the faults injected into a run.
+type faults []Fault
+
+// inject applies the faults of fn to a call, and returns the error failing
+// the call, if any.
+func (fs faults) inject(ctx context.Context, fn *function) error {
+	for _, f := range fs {
+		if f.Output == nil {
+			continue
+		}
+		if output, _ := unwrapOptional(f.Output); !slices.Contains(fn.valueOutputs(), output) {
+			continue
+		}
+		if f.Probability > 0 && rand.Float64() >= f.Probability {
+			continue
+		}
+		if f.Latency > 0 && !clockFrom(ctx).sleep(ctx, f.Latency) {
+			return ctx.Err()
+		}
+		if f.Err != nil {
+			return f.Err
+		}
+	}
+	return nil
+}
+
+// zeroOutputs returns the zero values of the outputs of fn.
+func zeroOutputs(fn *function) []reflect.Value {
+	out := make([]reflect.Value, len(fn.outputs))
+	for i, outT := range fn.outputs {
+		out[i] = reflect.Zero(outT)
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithFaults(t *testing.T) {
+	type (
+		in  string
+		a   string
+		b   string
+		out string
+	)
+
+	var calls atomic.Int32
+	ngn, err := Initialize(
+		func(i in) a { calls.Add(1); return a(i) },
+		BestEffort(func(i in) (b, error) { return b(i), nil }),
+		func(a a, b Optional[b]) out { return out(a) + out(b.Val) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should fail the faulty function without calling it", func(t *testing.T) {
+		_, err := Run[out](context.Background(), ngn, in("<in>"), WithFaults(FaultOf[a](errors.New("<injected>"), 0)))
+		assertErr(t, err, "<injected>")
+		assert.Zero(t, calls.Load())
+	})
+
+	t.Run("should handle the injected errors like the errors of the function", func(t *testing.T) {
+		res, err := Run[out](context.Background(), ngn, in("<in>"), WithFaults(FaultOf[Optional[b]](errors.New("<injected>"), 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, out("<in>"), res)
+	})
+
+	t.Run("should delay the faulty function", func(t *testing.T) {
+		started := time.Now()
+		res, err := Run[out](context.Background(), ngn, in("<in>"), WithFaults(FaultOf[a](nil, 30*time.Millisecond)))
+		assert.NoError(t, err)
+		assert.Equal(t, out("<in><in>"), res)
+		assert.GreaterOrEqual(t, time.Since(started), 30*time.Millisecond)
+	})
+
+	t.Run("should stop delaying once the run is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		started := time.Now()
+		_, err := Run[out](ctx, ngn, in("<in>"), WithFaults(FaultOf[a](nil, time.Minute)))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(started), time.Minute)
+	})
+
+	t.Run("should retry the faulty calls as decided by the error handler", func(t *testing.T) {
+		var attempts atomic.Int32
+		ngn, err := Initialize(
+			func(i in) (a, error) { return a(i), nil },
+			OnError(func(_ context.Context, fe FunctionError) ErrorDecision {
+				attempts.Add(1)
+				if fe.Attempt < 3 {
+					return RetryFunction
+				}
+				return fe.Default
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[a](context.Background(), ngn, in("<in>"), WithFaults(FaultOf[a](errors.New("<injected>"), 0)))
+		assertErr(t, err, "<injected>")
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+}
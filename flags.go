@@ -0,0 +1,89 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// FlagSource tells whether feature flags are on, so that functions gated
+// behind a flag with Flagged can be rolled out, or switched off, without
+// redeploying the engine. Register it with WithFlagSource.
+type FlagSource interface {
+	// Enabled reports whether flag is on for the run of ctx, which carries
+	// the values set by the caller of the run, such as the current user.
+	Enabled(ctx context.Context, flag string) bool
+}
+
+// WithFlagSource sets the FlagSource of the engine. Initialize fails if a
+// function is Flagged and no FlagSource is set.
+func WithFlagSource(src FlagSource) Option {
+	return func(cfg *config) {
+		cfg.flags = src
+	}
+}
+
+// Flagged gates fn behind the feature flag named flag. Every run asks the
+// FlagSource of the engine whether the flag is on, once per flag, before
+// starting any function. If it is off, fn is skipped as if one of its inputs
+// was missing: its outputs are not available and the functions requiring
+// them are skipped too, while those accepting them as Optional run without.
+// The report of the run gives the flag as the SkipReason of fn.
+func Flagged(fn any, flag string) *Provider {
+	p := asProvider(fn)
+	if flag == "" {
+		p.err = errors.New("flag name must not be empty")
+		return p
+	}
+	p.flag = flag
+	return p
+}
+
+// validateFlags returns an error per flagged provider if the engine has no
+// FlagSource.
+func validateFlags(providers []*Provider, src FlagSource) []error {
+	if src != nil {
+		return nil
+	}
+	var errs []error
+	for _, p := range providers {
+		if p.flag != "" {
+			errs = append(errs, wrapProviderValidationError(p, fmt.Errorf("function is flagged %q but the engine has no FlagSource", p.flag)))
+		}
+	}
+	return errs
+}
+
+// switchedOff returns, per function of the run, the reason it is switched
+// off for the run, empty if it is not: the function is shed by a degraded
+// run, its output is disabled with WithDisabled or its flag is off. It
+// returns nil if no function can be switched off.
+func (rs *runState) switchedOff(ctx context.Context) []string {
+	if rs.shed == nil && rs.flags == nil && len(rs.disabled) == 0 {
+		return nil
+	}
+	out := make([]string, len(rs.schedule.funcs))
+	flags := map[string]bool{}
+	for i, fn := range rs.schedule.funcs {
+		if rs.shed != nil && rs.shed.shed(fn) {
+			out[i] = "run degraded"
+			continue
+		}
+		if t := rs.disables(fn); t != nil {
+			out[i] = fmt.Sprintf("%s disabled for the run", t)
+			continue
+		}
+		if fn.flag == "" || rs.flags == nil {
+			continue
+		}
+		on, ok := flags[fn.flag]
+		if !ok {
+			on = rs.flags.Enabled(ctx, fn.flag)
+			flags[fn.flag] = on
+		}
+		if !on {
+			out[i] = fmt.Sprintf("flag %s is off", fn.flag)
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package warp_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+// flagSet is a FlagSource counting the lookups of every flag.
+type flagSet struct {
+	mu      sync.Mutex
+	on      map[string]bool
+	lookups map[string]int
+}
+
+func (f *flagSet) Enabled(_ context.Context, flag string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups[flag]++
+	return f.on[flag]
+}
+
+func Test_Flagged(t *testing.T) {
+	type (
+		cart          string
+		price         int
+		discount      int
+		discountLabel string
+		total         int
+	)
+
+	newEngine := func(t *testing.T, flags *flagSet) *Engine {
+		ngn, err := Initialize(
+			func(c cart) price { return price(len(c)) },
+			Flagged(func(c cart) discount { return 1 }, "discounts"),
+			Flagged(func(d discount) discountLabel { return "-1" }, "discounts"),
+			func(p price, d Optional[discount]) total { return total(int(p) - int(d.Val)) },
+			WithFlagSource(flags),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	t.Run("runs the flagged functions when their flag is on", func(t *testing.T) {
+		t.Parallel()
+		flags := &flagSet{on: map[string]bool{"discounts": true}, lookups: map[string]int{}}
+		ngn := newEngine(t, flags)
+
+		var report Report
+		out, err := Run[total](context.Background(), ngn, cart("abcd"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, total(3), out)
+		for _, fr := range report.Functions {
+			assert.Equal(t, StatusSucceeded, fr.Status, fr.Name)
+			assert.Empty(t, fr.SkipReason, fr.Name)
+		}
+		assert.Equal(t, 1, flags.lookups["discounts"], "flags are looked up once per run")
+	})
+
+	t.Run("skips the flagged functions when their flag is off", func(t *testing.T) {
+		t.Parallel()
+		flags := &flagSet{on: map[string]bool{}, lookups: map[string]int{}}
+		ngn := newEngine(t, flags)
+
+		var report Report
+		out, err := Run[total](context.Background(), ngn, cart("abcd"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, total(4), out)
+		assert.Equal(t, StatusSucceeded, report.Functions[0].Status)
+		for _, fr := range report.Functions[1:3] {
+			assert.Equal(t, StatusSkipped, fr.Status, fr.Name)
+			assert.Equal(t, "flag discounts is off", fr.SkipReason, fr.Name)
+			assert.Empty(t, fr.Missing, fr.Name)
+		}
+		assert.Equal(t, StatusSucceeded, report.Functions[3].Status)
+		assert.Empty(t, report.Functions[3].SkipReason)
+	})
+
+	t.Run("can not produce the output of a switched off function", func(t *testing.T) {
+		t.Parallel()
+		ngn := newEngine(t, &flagSet{on: map[string]bool{}, lookups: map[string]int{}})
+
+		out, err := Run[discountLabel](context.Background(), ngn, cart("abcd"), WithRequireTarget())
+		assert.ErrorIs(t, err, ErrTargetNotProducible)
+		assert.Empty(t, out)
+	})
+
+	t.Run("fails to initialize flagged functions without a FlagSource", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			Flagged(func(c cart) discount { return 1 }, "discounts"),
+		)
+		assertErrContains(t, err, `function is flagged "discounts" but the engine has no FlagSource`)
+	})
+
+	t.Run("fails to initialize a flag without a name", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			Flagged(func(c cart) discount { return 1 }, ""),
+			WithFlagSource(&flagSet{}),
+		)
+		assertErrContains(t, err, "flag name must not be empty")
+	})
+}
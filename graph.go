@@ -0,0 +1,101 @@
+package warp
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// graphJSON is the JSON document produced by MarshalGraphJSON.
+type graphJSON struct {
+	Fingerprint string          `json:"fingerprint"`
+	Nodes       []graphNodeJSON `json:"nodes"`
+	Edges       []graphEdgeJSON `json:"edges"`
+	// Inputs holds the types consumed by some function and produced by
+	// none, which must be provided to runs.
+	Inputs []string `json:"inputs"`
+}
+
+type graphNodeJSON struct {
+	ID       string          `json:"id"`
+	Package  string          `json:"package"`
+	Location string          `json:"location"`
+	Tags     []string        `json:"tags,omitempty"`
+	Inputs   []graphTypeJSON `json:"inputs"`
+	Outputs  []graphTypeJSON `json:"outputs"`
+}
+
+type graphTypeJSON struct {
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type graphEdgeJSON struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// MarshalGraphJSON encodes the dependency graph of the engine as JSON, for web
+// UIs and for diffing the graph between releases. The document holds the
+// fingerprint of the engine, a node per function with its package, source
+// location, tags and typed inputs and outputs, an edge from every function to
+// each function consuming one of its outputs, and the types runs must be
+// provided with. Nodes are identified by function name, types by their name
+// qualified with their package path, and everything is in registration order.
+func (e *Engine) MarshalGraphJSON() ([]byte, error) {
+	if e == nil || !e.initialized {
+		return nil, categorize(ErrNotInitialized, "error marshaling engine that has not been initialized")
+	}
+
+	g := graphJSON{
+		Fingerprint: e.fingerprint,
+		Nodes:       []graphNodeJSON{},
+		Edges:       []graphEdgeJSON{},
+		Inputs:      []string{},
+	}
+	seenInputs := map[string]bool{}
+	for _, info := range e.Functions() {
+		node := graphNodeJSON{
+			ID:       info.Name,
+			Package:  packageOf(info.Name),
+			Location: info.Location,
+			Tags:     info.Tags,
+			Inputs:   []graphTypeJSON{},
+			Outputs:  []graphTypeJSON{},
+		}
+		for _, in := range info.Inputs {
+			name := typeName(in.Type)
+			node.Inputs = append(node.Inputs, graphTypeJSON{Type: name, Optional: in.Optional})
+			if producer, ok := e.producers[in.Type]; ok {
+				g.Edges = append(g.Edges, graphEdgeJSON{From: producer.name, To: info.Name, Type: name, Optional: in.Optional})
+			} else if !seenInputs[name] {
+				seenInputs[name] = true
+				g.Inputs = append(g.Inputs, name)
+			}
+		}
+		for _, out := range info.Outputs {
+			node.Outputs = append(node.Outputs, graphTypeJSON{Type: typeName(out.Type), Optional: out.Optional})
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	return json.Marshal(g)
+}
+
+// packageOf returns the import path of the package declaring the function
+// named name.
+func packageOf(name string) string {
+	for _, prefix := range []string{"adapter ", "resilient ", "race ", "shadow "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if i := strings.IndexAny(name, "(["); i != -1 {
+		name = name[:i]
+	}
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot == -1 {
+		return ""
+	}
+	return name[:slash+1+dot]
+}
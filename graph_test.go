@@ -0,0 +1,92 @@
+package warp_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type (
+	graphIn      string
+	graphUser    string
+	graphAds     string
+	graphQuery   string
+	graphSummary string
+)
+
+func Test_MarshalGraphJSON(t *testing.T) {
+	t.Run("should encode the functions as nodes and their dependencies as edges", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Tag(func(i graphIn) graphUser { return graphUser(i) }, "<tag>"),
+			func(u graphUser, q Optional[graphQuery]) Optional[graphAds] { return Optional[graphAds]{} },
+			func(u graphUser, a Optional[graphAds]) graphSummary { return graphSummary(u) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ngn.MarshalGraphJSON()
+		assert.NoError(t, err)
+
+		var graph struct {
+			Fingerprint string
+			Nodes       []struct {
+				ID       string
+				Package  string
+				Location string
+				Tags     []string
+				Inputs   []struct {
+					Type     string
+					Optional bool
+				}
+				Outputs []struct {
+					Type     string
+					Optional bool
+				}
+			}
+			Edges []struct {
+				From, To, Type string
+				Optional       bool
+			}
+			Inputs []string
+		}
+		if err := json.Unmarshal(data, &graph); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, ngn.Fingerprint(), graph.Fingerprint)
+		if !assert.Len(t, graph.Nodes, 3) || !assert.Len(t, graph.Edges, 3) {
+			return
+		}
+		user, ads, summary := graph.Nodes[0], graph.Nodes[1], graph.Nodes[2]
+		assert.Equal(t, "github.com/dezlitz/warp_test", user.Package)
+		assert.Contains(t, user.Location, "graph_test.go:")
+		assert.Equal(t, []string{"<tag>"}, user.Tags)
+		assert.Equal(t, "github.com/dezlitz/warp_test.graphAds", ads.Outputs[0].Type)
+		assert.True(t, ads.Outputs[0].Optional)
+		assert.Equal(t, "github.com/dezlitz/warp_test.graphQuery", ads.Inputs[1].Type)
+		assert.True(t, ads.Inputs[1].Optional)
+
+		assert.Equal(t, user.ID, graph.Edges[0].From)
+		assert.Equal(t, ads.ID, graph.Edges[0].To)
+		assert.Equal(t, "github.com/dezlitz/warp_test.graphUser", graph.Edges[0].Type)
+		assert.Equal(t, user.ID, graph.Edges[1].From)
+		assert.Equal(t, summary.ID, graph.Edges[1].To)
+		assert.Equal(t, ads.ID, graph.Edges[2].From)
+		assert.Equal(t, summary.ID, graph.Edges[2].To)
+		assert.True(t, graph.Edges[2].Optional)
+
+		assert.Equal(t, []string{"github.com/dezlitz/warp_test.graphIn", "github.com/dezlitz/warp_test.graphQuery"}, graph.Inputs)
+	})
+
+	t.Run("should return an error for an engine that has not been initialized", func(t *testing.T) {
+		t.Parallel()
+		var ngn *Engine
+		_, err := ngn.MarshalGraphJSON()
+		assertErr(t, err, "error marshaling engine that has not been initialized")
+	})
+}
@@ -0,0 +1,93 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrFunctionCancelled is the cause of the context of a function cancelled
+// with RunHandle.CancelFunction.
+var ErrFunctionCancelled = errors.New("function cancelled")
+
+// RunHandle controls a run while it is in progress. Attach it to a run with
+// WithHandle. A RunHandle may be reused by successive runs but must not be
+// attached to concurrent runs.
+type RunHandle struct {
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+}
+
+// WithHandle attaches h to the run.
+func WithHandle(h *RunHandle) RunOption {
+	return func(c *runConfig) {
+		c.handle = h
+	}
+}
+
+// Running returns the names of the functions in flight, as in reports,
+// sorted.
+func (h *RunHandle) Running() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]string, 0, len(h.running))
+	for name := range h.running {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CancelFunction cancels the context of the in-flight function named name and
+// reports whether such a function was running. The rest of the run proceeds:
+// the cancelled function fails the run or is skipped according to the Cancel
+// policy of the RunMode. A function that ignores its context is not
+// interrupted.
+func (h *RunHandle) CancelFunction(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cancel, ok := h.running[name]
+	if ok {
+		cancel(ErrFunctionCancelled)
+	}
+	return ok
+}
+
+// enter registers fn as running and returns its cancellable context and the
+// function to call once fn returns. A nil handle returns ctx as is.
+func (h *RunHandle) enter(ctx context.Context, fn *function) (context.Context, func()) {
+	if h == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running == nil {
+		h.running = map[string]context.CancelCauseFunc{}
+	}
+	h.running[fn.name] = cancel
+	return ctx, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.running, fn.name)
+		cancel(nil)
+	}
+}
+
+// cancelled handles the error of fn when its context was cancelled with
+// CancelFunction: the function is skipped under CancelAsSkip and fails the
+// run under CancelAsError.
+func (rs *runState) cancelled(fn *function, err error) error {
+	if rs.mode.Cancel == CancelAsSkip {
+		rs.resolve(fn)
+		rs.record(fn, StatusSkipped, nil)
+		return nil
+	}
+
+	err = fmt.Errorf("function %s: %w", fn.name, errors.Join(ErrFunctionCancelled, err))
+	rs.record(fn, StatusFailed, err)
+	return err
+}
@@ -0,0 +1,85 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunHandle(t *testing.T) {
+	type (
+		in      string
+		slow    string
+		fast    string
+		summary string
+	)
+
+	newEngine := func(t *testing.T) *Engine {
+		ngn, err := Initialize(
+			func(ctx context.Context, i in) (slow, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			func(i in) fast { return fast(i) },
+			func(s Optional[slow], f fast) summary {
+				return summary(string(f) + string(s.Val))
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	// cancelSlow cancels the slow function once it is running.
+	cancelSlow := func(t *testing.T, h *RunHandle) {
+		for {
+			for _, name := range h.Running() {
+				if strings.Contains(name, "(warp_test.slow, error)") && h.CancelFunction(name) {
+					return
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("should skip a cancelled function under the CancelAsSkip policy", func(t *testing.T) {
+		t.Parallel()
+		var (
+			h      RunHandle
+			report Report
+		)
+		go cancelSlow(t, &h)
+
+		out, err := Run[summary](context.Background(), newEngine(t), in("<in>"),
+			WithHandle(&h), WithRunMode(RunMode{Cancel: CancelAsSkip}), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, summary("<in>"), out)
+		if assert.Len(t, report.Functions, 3) {
+			assert.Equal(t, StatusSkipped, report.Functions[0].Status)
+		}
+		assert.Empty(t, h.Running())
+	})
+
+	t.Run("should fail the run with a cancelled function under the CancelAsError policy", func(t *testing.T) {
+		t.Parallel()
+		var h RunHandle
+		go cancelSlow(t, &h)
+
+		_, err := Run[summary](context.Background(), newEngine(t), in("<in>"), WithHandle(&h))
+		assert.True(t, errors.Is(err, ErrFunctionCancelled))
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("should report whether the function was running", func(t *testing.T) {
+		t.Parallel()
+		var h RunHandle
+		assert.False(t, h.CancelFunction("<unknown>"))
+	})
+}
@@ -0,0 +1,242 @@
+package warp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"maps"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithImmutabilityCheck makes every run deep hash the values it stores, the
+// provided inputs and the function outputs, and hash them again once all
+// functions have returned. A run in which a stored value changed returns an
+// error naming the function that produced the value and the functions that
+// consumed it, one of which mutated it in place.
+//
+// Values are shared by all the functions consuming them, concurrently, so an
+// in place mutation is a data race. Hashing is expensive: the check is meant
+// for tests and debug builds, not production.
+func WithImmutabilityCheck() Option {
+	return func(c *config) {
+		c.immutabilityCheck = true
+	}
+}
+
+// valueHashes holds the deep hash of the values stored during a run.
+type valueHashes struct {
+	mu     sync.Mutex
+	values map[reflect.Type]valueHash
+}
+
+type valueHash struct {
+	v   reflect.Value
+	sum uint64
+	// last is the hash of the value when last audited, see
+	// WithMutationAudit
+	last     uint64
+	producer string
+}
+
+func newValueHashes(provided []any) *valueHashes {
+	h := &valueHashes{values: map[reflect.Type]valueHash{}}
+	for _, in := range provided {
+		inT, inV := providedValue(in)
+		inTU, _ := unwrapOptional(inT)
+		h.track(inTU, inV, "provided input")
+	}
+	return h
+}
+
+// track records the hash of the value stored under key. A nil valueHashes
+// records nothing.
+func (h *valueHashes) track(key reflect.Type, v reflect.Value, producer string) {
+	if h == nil || v.Type().Implements(reflect.TypeOf((*awaiter)(nil)).Elem()) {
+		// Outputs still being produced change by design
+		return
+	}
+	sum := deepHash(v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.values[key] = valueHash{v: v, sum: sum, last: sum, producer: producer}
+}
+
+// changed hashes the value stored under key again and reports whether it
+// changed since it was last hashed, with its producer. A nil valueHashes
+// reports no change.
+func (h *valueHashes) changed(key reflect.Type) (producer string, ok bool) {
+	if h == nil {
+		return "", false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	vh, tracked := h.values[key]
+	if !tracked {
+		return "", false
+	}
+	sum := deepHash(vh.v)
+	if sum == vh.last {
+		return "", false
+	}
+	vh.last = sum
+	h.values[key] = vh
+	return vh.producer, true
+}
+
+// unattributed returns the mutations of the values that changed since they
+// were last hashed. A nil valueHashes returns none.
+func (h *valueHashes) unattributed() []Mutation {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []Mutation
+	for key, vh := range h.values {
+		if deepHash(vh.v) != vh.last {
+			out = append(out, Mutation{Type: key, Producer: vh.producer})
+		}
+	}
+	return out
+}
+
+// verify hashes the tracked values again and returns an error for every value
+// that changed. A nil valueHashes verifies nothing.
+func (h *valueHashes) verify(funcs []*function) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var mutated []string
+	for key, vh := range h.values {
+		if deepHash(vh.v) == vh.sum {
+			continue
+		}
+		var consumers []string
+		for _, fn := range funcs {
+			for _, in := range fn.ins {
+				if in.key == key {
+					consumers = append(consumers, fn.name)
+				}
+			}
+		}
+		mutated = append(mutated, fmt.Sprintf("%s produced by %s, consumed by %s",
+			key, vh.producer, strings.Join(consumers, " AND ")))
+	}
+	if len(mutated) == 0 {
+		return nil
+	}
+
+	sort.Strings(mutated)
+	return fmt.Errorf("stored values were mutated during the run: %s", strings.Join(mutated, "; "))
+}
+
+// deepHash hashes the value of v, following pointers, interfaces, slices and
+// maps. Channels and functions are hashed by identity.
+func deepHash(v reflect.Value) uint64 {
+	h := fnv.New64a()
+	hashValue(h, v, map[uintptr]bool{})
+	return h.Sum64()
+}
+
+func hashValue(h hash.Hash64, v reflect.Value, visited map[uintptr]bool) {
+	writeUint := func(u uint64) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], u)
+		h.Write(b[:])
+	}
+
+	if !v.IsValid() {
+		writeUint(0)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeUint(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i), visited)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		writeUint(uint64(v.Len()) + 1)
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i), visited)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i), visited)
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		if visited[v.Pointer()] {
+			writeUint(uint64(v.Pointer()))
+			return
+		}
+		visited[v.Pointer()] = true
+		hashValue(h, v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		h.Write([]byte(v.Elem().Type().String()))
+		hashValue(h, v.Elem(), visited)
+	case reflect.Map:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		if visited[v.Pointer()] {
+			writeUint(uint64(v.Pointer()))
+			return
+		}
+		visited[v.Pointer()] = true
+		// Sum the entry hashes so that the iteration order does not matter.
+		// Every entry is hashed with its own copy of visited, so that the
+		// values shared by several entries are hashed the same in each.
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			eh, seen := fnv.New64a(), maps.Clone(visited)
+			hashValue(eh, iter.Key(), seen)
+			hashValue(eh, iter.Value(), seen)
+			sum += eh.Sum64()
+		}
+		writeUint(uint64(v.Len()) + 1)
+		writeUint(sum)
+	default:
+		// Chan, Func and UnsafePointer
+		writeUint(uint64(v.Pointer()))
+	}
+}
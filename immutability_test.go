@@ -0,0 +1,98 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithImmutabilityCheck(t *testing.T) {
+	type (
+		ids    []int
+		sorted []int
+		lookup map[string]*int
+		count  int
+	)
+
+	t.Run("should return an error if a function mutates its input in place", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in ids) sorted {
+				in[0], in[1] = in[1], in[0]
+				return sorted(in)
+			},
+			WithImmutabilityCheck(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[sorted](context.Background(), ngn, ids{2, 1})
+		assertErrContains(t, err, "stored values were mutated during the run: warp_test.ids produced by provided input, consumed by github.com/dezlitz/warp_test.Test_WithImmutabilityCheck.func1.1")
+	})
+
+	t.Run("should return an error if a function mutates an output through a pointer", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func() lookup {
+				n := 1
+				return lookup{"a": &n}
+			},
+			func(l lookup) count {
+				*l["a"]++
+				return count(len(l))
+			},
+			WithImmutabilityCheck(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[count](context.Background(), ngn)
+		assertErrContains(t, err, "warp_test.lookup produced by github.com/dezlitz/warp_test.Test_WithImmutabilityCheck.func2.1")
+	})
+
+	t.Run("should not return an error if no stored value is mutated", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in ids) sorted {
+				out := append(sorted(nil), in...)
+				out[0], out[1] = out[1], out[0]
+				return out
+			},
+			WithImmutabilityCheck(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[sorted](context.Background(), ngn, ids{2, 1})
+		assert.NoError(t, err)
+		assert.Equal(t, sorted{1, 2}, out)
+	})
+
+	t.Run("should not return an error if map entries share a pointer", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(l lookup) count { return count(len(l)) },
+			WithImmutabilityCheck(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := 1
+		shared := lookup{}
+		for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			shared[k] = &n
+		}
+		for range 50 {
+			out, err := Run[count](context.Background(), ngn, shared)
+			assert.NoError(t, err)
+			assert.Equal(t, count(8), out)
+		}
+	})
+}
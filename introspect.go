@@ -0,0 +1,124 @@
+package warp
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+)
+
+// CanProduce reports whether a run provided with values of the provided types
+// can produce a value of the target type, without running any function.
+// Optional types are unwrapped. The answer is static: a function that returns
+// an error, or leaves an Optional output unset, at run time still leaves the
+// target unset.
+func (e *Engine) CanProduce(target reflect.Type, provided ...reflect.Type) bool {
+	if e == nil || !e.initialized || target == nil {
+		return false
+	}
+
+	target, _ = unwrapOptional(target)
+	stored := make(map[reflect.Type]bool, len(provided))
+	for _, t := range provided {
+		if t != nil {
+			t, _ = unwrapOptional(t)
+			stored[t] = true
+		}
+	}
+	r := e.schedule.reachableFrom(e.values, stored)
+	return e.schedule.produces(r, stored[target], target) == nil
+}
+
+// ExplainPath returns the names of the functions a run producing the target
+// type requires, in dependency order: the function producing target and,
+// transitively, the functions producing its required inputs. The producers of
+// Optional inputs are not required and not included.
+//
+// An error is returned if the engine has not been initialized or if no
+// function produces target.
+func (e *Engine) ExplainPath(target reflect.Type) ([]string, error) {
+	if e == nil || !e.initialized {
+		return nil, categorize(ErrNotInitialized, "error explaining engine that has not been initialized")
+	}
+	if target == nil {
+		return nil, errors.New("target type must not be nil")
+	}
+
+	target, _ = unwrapOptional(target)
+	producer, ok := e.producers[target]
+	if !ok {
+		return nil, categorize(ErrTargetNotProducible, "output type %s does not match any function output types", target)
+	}
+
+	required := map[*function]bool{}
+	var visit func(fn *function)
+	visit = func(fn *function) {
+		if required[fn] {
+			return
+		}
+		required[fn] = true
+		for _, in := range fn.ins {
+			if p, ok := e.producers[in.key]; ok && !in.optional && !in.zero {
+				visit(p)
+			}
+		}
+	}
+	visit(producer)
+
+	var funcs []*function
+	for _, fn := range e.funcs {
+		if required[fn] {
+			funcs = append(funcs, fn)
+		}
+	}
+	return sliceConvert(func(fn *function) string { return fn.name }, sortFunctions(funcs, e.producers)), nil
+}
+
+// FunctionInfo describes a function registered with an engine.
+type FunctionInfo struct {
+	// Name refers to the function, as in reports.
+	Name string
+	// Location is the file:line source location of the function.
+	Location string
+	// Inputs holds the parameters of the function, excluding
+	// context.Context and Invoker.
+	Inputs []TypeInfo
+	// Outputs holds the values produced by the function, excluding error and
+	// Cleanup.
+	Outputs []TypeInfo
+	// Tags holds the tags of the function, see Tag.
+	Tags []string
+}
+
+// TypeInfo describes a parameter or an output of a function.
+type TypeInfo struct {
+	// Type is the type of the value, without any Optional or Lazy wrapper.
+	Type reflect.Type
+	// Optional is true if the value is wrapped in an Optional.
+	Optional bool
+	// Lazy is true if the value is wrapped in a Lazy.
+	Lazy bool
+}
+
+// Functions describes the functions of the engine, adapters included, in
+// registration order, for tools such as documentation generators and
+// dashboards.
+func (e *Engine) Functions() []FunctionInfo {
+	if e == nil {
+		return nil
+	}
+
+	out := make([]FunctionInfo, len(e.funcs))
+	for i, fn := range e.funcs {
+		info := FunctionInfo{Name: fn.name, Location: fn.location, Tags: slices.Clone(fn.tags)}
+		for _, in := range fn.ins {
+			if !in.injected() {
+				info.Inputs = append(info.Inputs, TypeInfo{Type: in.key, Optional: in.optional || in.zero, Lazy: in.lazy})
+			}
+		}
+		for _, o := range fn.outs {
+			info.Outputs = append(info.Outputs, TypeInfo{Type: o.key, Optional: isOptional(fn.outputs[o.pos])})
+		}
+		out[i] = info
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_CanProduce(t *testing.T) {
+	type (
+		in      string
+		missing string
+		user    string
+		orders  string
+		summary string
+	)
+
+	ngn, err := Initialize(
+		func(i in) user { panic("must not run") },
+		func(m missing) orders { panic("must not run") },
+		func(u user, o Optional[orders]) summary { panic("must not run") },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should report whether the target can be produced", func(t *testing.T) {
+		t.Parallel()
+		assert.True(t, ngn.CanProduce(reflect.TypeOf(summary("")), reflect.TypeOf(in(""))))
+		assert.True(t, ngn.CanProduce(reflect.TypeOf(orders("")), reflect.TypeOf(Optional[missing]{})))
+		assert.False(t, ngn.CanProduce(reflect.TypeOf(orders("")), reflect.TypeOf(in(""))))
+		assert.False(t, ngn.CanProduce(reflect.TypeOf(summary(""))))
+		assert.True(t, ngn.CanProduce(reflect.TypeOf(in("")), reflect.TypeOf(in(""))))
+		assert.False(t, ngn.CanProduce(reflect.TypeOf(0), reflect.TypeOf(in(""))))
+	})
+
+	t.Run("should return false for an engine that has not been initialized", func(t *testing.T) {
+		t.Parallel()
+		var e *Engine
+		assert.False(t, e.CanProduce(reflect.TypeOf(summary(""))))
+	})
+}
+
+func Test_ExplainPath(t *testing.T) {
+	type (
+		in      string
+		missing string
+		user    string
+		orders  string
+		ads     string
+		summary string
+	)
+
+	ngn, err := Initialize(
+		func(u user) summary { panic("must not run") },
+		func(m missing) orders { panic("must not run") },
+		func(i in) user { panic("must not run") },
+		func(u user, o Optional[orders]) ads { panic("must not run") },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should return the functions required by the target in dependency order", func(t *testing.T) {
+		t.Parallel()
+		path, err := ngn.ExplainPath(reflect.TypeOf(summary("")))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"github.com/dezlitz/warp_test.Test_ExplainPath.func3(warp_test.in) warp_test.user",
+			"github.com/dezlitz/warp_test.Test_ExplainPath.func1(warp_test.user) warp_test.summary",
+		}, path)
+	})
+
+	t.Run("should not require the producers of optional inputs", func(t *testing.T) {
+		t.Parallel()
+		path, err := ngn.ExplainPath(reflect.TypeOf(ads("")))
+		assert.NoError(t, err)
+		if assert.Len(t, path, 2) {
+			assert.Equal(t, "github.com/dezlitz/warp_test.Test_ExplainPath.func3(warp_test.in) warp_test.user", path[0])
+			assert.Contains(t, path[1], "github.com/dezlitz/warp_test.Test_ExplainPath.func4(warp_test.user, warp.Optional[")
+		}
+	})
+
+	t.Run("should return an error if no function produces the target", func(t *testing.T) {
+		t.Parallel()
+		_, err := ngn.ExplainPath(reflect.TypeOf(in("")))
+		assertErr(t, err, "output type warp_test.in does not match any function output types")
+	})
+}
+
+func Test_EngineFunctions(t *testing.T) {
+	type (
+		in     string
+		user   string
+		query  string
+		page   []int
+		cursor int
+		ads    string
+	)
+
+	t.Run("should describe every function of the engine", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Tag(func(ctx context.Context, i in) (user, error) { return user(i), nil }, "<tag>"),
+			func(u user, q Optional[query]) Optional[ads] { return Optional[ads]{} },
+			Paginate[page, cursor](func(u user, c Optional[cursor]) (page, Optional[cursor]) {
+				return nil, Optional[cursor]{}
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		funcs := ngn.Functions()
+		if !assert.Len(t, funcs, 3) {
+			return
+		}
+		for _, fn := range funcs {
+			assert.Contains(t, fn.Location, "introspect_test.go:")
+		}
+		assert.Equal(t, "github.com/dezlitz/warp_test.Test_EngineFunctions.func1.1(context.Context, warp_test.in) (warp_test.user, error)", funcs[0].Name)
+		assert.Equal(t, []TypeInfo{{Type: reflect.TypeOf(in(""))}}, funcs[0].Inputs)
+		assert.Equal(t, []TypeInfo{{Type: reflect.TypeOf(user(""))}}, funcs[0].Outputs)
+		assert.Equal(t, []string{"<tag>"}, funcs[0].Tags)
+		assert.Equal(t, []TypeInfo{{Type: reflect.TypeOf(user(""))}, {Type: reflect.TypeOf(query("")), Optional: true}}, funcs[1].Inputs)
+		assert.Equal(t, []TypeInfo{{Type: reflect.TypeOf(ads("")), Optional: true}}, funcs[1].Outputs)
+		assert.Equal(t, []TypeInfo{{Type: reflect.TypeOf(user(""))}}, funcs[2].Inputs)
+		assert.Equal(t, []TypeInfo{{Type: reflect.TypeOf(Pages[page]{})}}, funcs[2].Outputs)
+	})
+}
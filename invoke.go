@@ -0,0 +1,101 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// defaultMaxInvokeDepth is the default maximum number of nested runs, see
+// WithMaxInvokeDepth.
+const defaultMaxInvokeDepth = 32
+
+// Invoker runs nested runs on the engine running the function, for recursive
+// workflows such as tree expansion. A function receives it by declaring an
+// Invoker parameter, which the engine fills in the same way as a
+// context.Context parameter. Use it with Invoke.
+type Invoker struct {
+	e        *Engine
+	maxDepth int
+	// chain holds the runs from the outermost run to the run of the
+	// function holding the Invoker
+	chain []invocation
+}
+
+// invocation identifies a run by its target and provided inputs.
+type invocation struct {
+	target   reflect.Type
+	provided []any
+}
+
+// WithMaxInvokeDepth limits the number of nested runs started with Invoke,
+// 32 by default. Invoke returns an error once the limit is reached.
+func WithMaxInvokeDepth(n int) Option {
+	return func(c *config) {
+		c.maxInvokeDepth = n
+	}
+}
+
+// Invoke runs the engine of inv for T, nested in the run of the function
+// holding inv. The nested run has the semantics of Run and shares the
+// context of the function. It is part of the enclosing run: it starts even
+// while the engine is shutting down, see Engine.Shutdown.
+//
+// An error is returned if the maximum invoke depth is reached, or if an
+// enclosing run is already producing T from inputs equal to provided, which
+// would recurse forever.
+func Invoke[T any](ctx context.Context, inv Invoker, provided ...any) (T, error) {
+	var out T
+	if inv.e == nil {
+		return out, errors.New("error invoking with an invoker that is not bound to a run")
+	}
+
+	if len(inv.chain) >= inv.maxDepth {
+		return out, fmt.Errorf("error invoking %s: maximum invoke depth %d reached", reflect.TypeOf((*T)(nil)).Elem(), inv.maxDepth)
+	}
+
+	next := newInvocation[T](provided)
+	for _, ancestor := range inv.chain {
+		if ancestor.target == next.target && reflect.DeepEqual(ancestor.provided, next.provided) {
+			return out, fmt.Errorf("error invoking %s: cycle detected, an enclosing run produces it from the same inputs", next.target)
+		}
+	}
+
+	chain := append(append([]invocation(nil), inv.chain...), next)
+	// Clipped, provided is copied rather than appended to in place
+	return run[T](ctx, inv.e, inv.e.schedule, append(slices.Clip(provided), withInvocations(chain)))
+}
+
+// newInvocation returns the invocation of a run for T, ignoring the
+// RunOptions among the provided inputs.
+func newInvocation[T any](provided []any) invocation {
+	in := invocation{target: reflect.TypeOf((*T)(nil)).Elem()}
+	if len(provided) > 0 && !slices.ContainsFunc(provided, isRunOption) {
+		in.provided = provided
+		return in
+	}
+	for _, p := range provided {
+		if _, ok := p.(RunOption); !ok {
+			in.provided = append(in.provided, p)
+		}
+	}
+	return in
+}
+
+// withInvocations sets the chain of enclosing runs of a nested run.
+func withInvocations(chain []invocation) RunOption {
+	return func(c *runConfig) {
+		c.invocations = chain
+	}
+}
+
+func validateFunctionOutputsNotInvoker(fnT reflect.Type) error {
+	for _, outT := range outputs(fnT) {
+		if isType[Invoker](outT) {
+			return invalid(RuleInvokerOutput, "must not have any warp.Invoker return value type(s)")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Invoke(t *testing.T) {
+	type (
+		path string
+		size int
+	)
+
+	children := map[path][]path{
+		"/":     {"/a", "/b"},
+		"/a":    {"/a/x", "/a/y"},
+		"/a/x":  nil,
+		"/a/y":  nil,
+		"/b":    {"/b/z"},
+		"/b/z":  nil,
+		"/loop": {"/loop"},
+	}
+
+	t.Run("should run nested runs on the same engine", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(ctx context.Context, inv Invoker, p path) (size, error) {
+				total := size(1)
+				for _, child := range children[p] {
+					s, err := Invoke[size](ctx, inv, child)
+					if err != nil {
+						return 0, err
+					}
+					total += s
+				}
+				return total, nil
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[size](context.Background(), ngn, path("/"))
+		assert.NoError(t, err)
+		assert.Equal(t, size(6), out)
+	})
+
+	t.Run("should return an error if a nested run is a cycle", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(ctx context.Context, inv Invoker, p path) (size, error) {
+				var total size
+				for _, child := range children[p] {
+					s, err := Invoke[size](ctx, inv, child)
+					if err != nil {
+						return 0, err
+					}
+					total += s
+				}
+				return total, nil
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[size](context.Background(), ngn, path("/loop"))
+		assertErr(t, err, "error invoking warp_test.size: cycle detected, an enclosing run produces it from the same inputs")
+	})
+
+	t.Run("should return an error once the maximum depth is reached", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(ctx context.Context, inv Invoker, p path) (size, error) {
+				return Invoke[size](ctx, inv, p+"/deeper")
+			},
+			WithMaxInvokeDepth(3),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[size](context.Background(), ngn, path("/"))
+		assertErr(t, err, "error invoking warp_test.size: maximum invoke depth 3 reached")
+	})
+
+	t.Run("should return an error if a function returns an Invoker", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(func(p path) Invoker { return Invoker{} })
+		assertErrContains(t, err, "must not have any warp.Invoker return value type(s)")
+	})
+
+	t.Run("should not write into the backing array of the provided inputs", func(t *testing.T) {
+		t.Parallel()
+		provided := make([]any, 1, 2)
+		ngn, err := Initialize(
+			func(ctx context.Context, inv Invoker, p path) (size, error) {
+				if p != "/" {
+					return 1, nil
+				}
+				provided[0] = path("/a")
+				return Invoke[size](ctx, inv, provided...)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[size](context.Background(), ngn, path("/"))
+		assert.NoError(t, err)
+		assert.Equal(t, size(1), out)
+		assert.Nil(t, provided[:2][1])
+	})
+
+	t.Run("should return an error if the invoker is not bound to a run", func(t *testing.T) {
+		t.Parallel()
+		_, err := Invoke[size](context.Background(), Invoker{}, path("/"))
+		assertErrContains(t, err, "not bound to a run")
+	})
+}
@@ -0,0 +1,102 @@
+package warp
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// latencySmoothing is the weight of the latest duration in the smoothed
+// estimates.
+const latencySmoothing = 0.1
+
+// LatencyEstimate is the estimated duration of a function, smoothed
+// exponentially over the calls of the function so that recent calls weigh
+// more than older ones.
+type LatencyEstimate struct {
+	// P50 is the estimated median duration.
+	P50 time.Duration `json:"p50"`
+	// P95 is the estimated 95th percentile duration.
+	P95 time.Duration `json:"p95"`
+	// Samples is the number of calls the estimate is based on.
+	Samples int64 `json:"samples"`
+}
+
+// WithLatencyEstimates seeds the latency estimates of the engine, typically
+// with the estimates saved by a previous process from Engine.LatencyEstimates.
+// Estimates of functions that are not registered are ignored.
+func WithLatencyEstimates(estimates map[string]LatencyEstimate) Option {
+	return func(c *config) {
+		c.latencies = estimates
+	}
+}
+
+// LatencyEstimates returns the latency estimates of the functions that have
+// been called, or seeded with WithLatencyEstimates, keyed by function name as
+// in reports. Only the calls that returned, successfully or not, are sampled:
+// skipped and cancelled functions and cached singletons are not.
+func (e *Engine) LatencyEstimates() map[string]LatencyEstimate {
+	out := map[string]LatencyEstimate{}
+	for _, fn := range e.funcs {
+		if est, ok := fn.latency.estimate(); ok {
+			out[fn.name] = est
+		}
+	}
+	return out
+}
+
+// latencyEstimator tracks the p50 and p95 durations of a function with a
+// stochastic quantile estimator: every sample moves the estimates towards it
+// by a step proportional to the smoothed deviation of the durations.
+type latencyEstimator struct {
+	mu       sync.Mutex
+	samples  int64
+	p50, p95 float64
+	// dev is the smoothed absolute deviation from p50, in nanoseconds
+	dev float64
+}
+
+func (l *latencyEstimator) seed(est LatencyEstimate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples, l.p50, l.p95 = est.Samples, float64(est.P50), float64(est.P95)
+	l.dev = l.p95 - l.p50
+}
+
+func (l *latencyEstimator) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	x := float64(d)
+	l.samples++
+	if l.samples == 1 {
+		l.p50, l.p95 = x, x
+		return
+	}
+
+	l.dev += latencySmoothing * (math.Abs(x-l.p50) - l.dev)
+	step := 2 * latencySmoothing * l.dev
+	l.p50 = moveQuantile(l.p50, x, 0.5, step)
+	l.p95 = math.Max(moveQuantile(l.p95, x, 0.95, step), l.p50)
+}
+
+func (l *latencyEstimator) estimate() (LatencyEstimate, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.samples == 0 {
+		return LatencyEstimate{}, false
+	}
+	return LatencyEstimate{P50: time.Duration(l.p50), P95: time.Duration(l.p95), Samples: l.samples}, true
+}
+
+// moveQuantile moves the estimate of quantile q towards x. The estimate
+// settles where a fraction q of the samples is below it.
+func moveQuantile(est, x, q, step float64) float64 {
+	if x > est {
+		return est + step*q
+	}
+	if x < est {
+		return math.Max(est-step*(1-q), 0)
+	}
+	return est
+}
@@ -0,0 +1,87 @@
+package warp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type (
+	latencyIn      string
+	latencyMissing string
+	latencyA       string
+	latencyB       string
+)
+
+func Test_LatencyEstimates(t *testing.T) {
+	const (
+		a = "github.com/dezlitz/warp_test.Test_LatencyEstimates.func1.1(warp_test.latencyIn) warp_test.latencyA"
+		b = "github.com/dezlitz/warp_test.Test_LatencyEstimates.func1.2(warp_test.latencyMissing) warp_test.latencyB"
+	)
+
+	fns := func(opts ...any) []any {
+		return append([]any{
+			func(i latencyIn) latencyA {
+				time.Sleep(10 * time.Millisecond)
+				return latencyA(i)
+			},
+			func(m latencyMissing) latencyB { return latencyB(m) },
+		}, opts...)
+	}
+
+	t.Run("should estimate the duration of the called functions", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(fns()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, ngn.LatencyEstimates())
+
+		for i := 0; i < 5; i++ {
+			_, err = Run[latencyA](context.Background(), ngn, latencyIn("<in>"))
+			assert.NoError(t, err)
+		}
+
+		estimates := ngn.LatencyEstimates()
+		assert.Len(t, estimates, 1)
+		est := estimates[a]
+		assert.Equal(t, int64(5), est.Samples)
+		assert.GreaterOrEqual(t, est.P50, 10*time.Millisecond)
+		assert.GreaterOrEqual(t, est.P95, est.P50)
+	})
+
+	t.Run("should resume from seeded estimates", func(t *testing.T) {
+		t.Parallel()
+		saved, err := json.Marshal(map[string]LatencyEstimate{
+			a:           {P50: time.Second, P95: 2 * time.Second, Samples: 100},
+			b:           {P50: time.Millisecond, P95: time.Millisecond, Samples: 3},
+			"<unknown>": {P50: time.Millisecond, Samples: 1},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var seeds map[string]LatencyEstimate
+		if err := json.Unmarshal(saved, &seeds); err != nil {
+			t.Fatal(err)
+		}
+
+		ngn, err := Initialize(fns(WithLatencyEstimates(seeds))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Run[latencyA](context.Background(), ngn, latencyIn("<in>"))
+		assert.NoError(t, err)
+
+		estimates := ngn.LatencyEstimates()
+		assert.Len(t, estimates, 2)
+		assert.Equal(t, LatencyEstimate{P50: time.Millisecond, P95: time.Millisecond, Samples: 3}, estimates[b])
+		assert.Equal(t, int64(101), estimates[a].Samples)
+		assert.Less(t, estimates[a].P50, time.Second)
+		assert.Less(t, estimates[a].P95, 2*time.Second)
+	})
+}
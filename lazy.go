@@ -0,0 +1,195 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// Lazy is a parameter type deferring the production of a value of type T
+// until the function receiving it calls Get. The functions producing T, and
+// the functions they depend on, only run if and when Get is called, unless
+// another function requires T or T is the target of the run, so expensive
+// branches that are only conditionally needed are not run for nothing.
+//
+// Get must be called by the function receiving the Lazy, not by the
+// goroutines it starts, before it returns.
+type Lazy[T any] struct {
+	in *lazyInput
+}
+
+// Get returns the value of type T, running its producer if it has not run yet
+// and waiting for it to return. An error is returned if ctx is done first or
+// if the value can not be produced.
+func (l Lazy[T]) Get(ctx context.Context) (T, error) {
+	var zero T
+	if l.in == nil {
+		return zero, errors.New("lazy input is not bound to a run")
+	}
+	v, err := l.in.get(ctx)
+	if err != nil {
+		return zero, err
+	}
+	return v.Interface().(T), nil
+}
+
+func (l *Lazy[T]) bind(in *lazyInput) {
+	l.in = in
+}
+
+func (*Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// lazy is implemented by the pointers to Lazy types.
+type lazy interface {
+	bind(in *lazyInput)
+	valueType() reflect.Type
+}
+
+// unwrapLazy returns the type of the value produced by a Lazy. If t is not a
+// Lazy then ok is false and t is returned unaltered.
+func unwrapLazy(t reflect.Type) (_ reflect.Type, ok bool) {
+	if !reflect.PointerTo(t).Implements(reflect.TypeOf((*lazy)(nil)).Elem()) {
+		return t, false
+	}
+	return reflect.New(t).Interface().(lazy).valueType(), true
+}
+
+// lazyInput is the value of a Lazy parameter in a run.
+type lazyInput struct {
+	rs  *runState
+	fn  *function
+	in  inputPlan
+	pos int
+}
+
+// newLazy returns the value of the Lazy parameter in of the function fn of
+// rs.
+func (rs *runState) newLazy(fn *function, in inputPlan) reflect.Value {
+	l := &lazyInput{rs: rs, fn: fn, in: in, pos: -1}
+	if in.signal != -1 {
+		l.pos = rs.schedule.producer[in.signal]
+	}
+	ptr := reflect.New(in.typ)
+	ptr.Interface().(lazy).bind(l)
+	return ptr.Elem()
+}
+
+func (l *lazyInput) get(ctx context.Context) (reflect.Value, error) {
+	value := valuePlan(l.in.key)
+	value.slot = l.in.slot
+	if l.pos != -1 {
+		if v, ok := loadValue(l.rs.storage, value); ok {
+			return v, nil
+		}
+
+		l.rs.force(l.pos)
+		l.rs.dispatch()
+		resume := l.rs.suspend(l.fn)
+		select {
+		case <-ctx.Done():
+			resume()
+			return reflect.Value{}, ctx.Err()
+		case <-l.rs.finished[l.pos]:
+			resume()
+		}
+	}
+
+	// The default value only stands in once the producer is done
+	value.def = l.in.def
+	if v, ok := loadValue(l.rs.storage, value); ok {
+		return v, nil
+	}
+	if l.pos == -1 {
+		return reflect.Value{}, fmt.Errorf("lazy input %s is not available: no function of the run produces it", l.in.key)
+	}
+	fn := l.rs.schedule.funcs[l.pos]
+	return reflect.Value{}, fmt.Errorf("lazy input %s is not available: function %s %s", l.in.key, fn.name, l.rs.status[l.pos])
+}
+
+// suspend releases the execution slots held by fn while it waits for a lazy
+// input, so that the producer can run under the Sequential execution policy,
+// cooperative execution and on the pool of fn, and returns the func taking
+// them back. The slot of a Limited function is kept: the call is still
+// executing. The slots are taken back even once the run is cancelled, as fn
+// releases them when it returns.
+func (rs *runState) suspend(fn *function) (resume func()) {
+	pool := rs.pools.gate(fn.workload)
+	pool.release()
+	rs.sequential.release()
+	rs.slots.release()
+	return func() {
+		ctx := context.Background()
+		_ = rs.slots.acquire(ctx, resumed)
+		_ = rs.sequential.acquire(ctx, resumed)
+		_ = pool.acquire(ctx, resumed)
+	}
+}
+
+// force launches the deferred function at position i, and the deferred
+// functions it depends on, once their inputs are resolved.
+func (rs *runState) force(i int) {
+	if !rs.schedule.deferred[i] || rs.forced[i].Swap(true) {
+		return
+	}
+	if !rs.started[i].Load() {
+		// not skipped up front
+		rs.progress.add()
+	}
+	for _, in := range rs.schedule.funcs[i].ins {
+		if in.signal != -1 && !in.lazy {
+			if p := rs.schedule.producer[in.signal]; p != -1 {
+				rs.force(p)
+			}
+		}
+	}
+	fn := rs.schedule.funcs[i]
+	if rs.pending[i].Load() == 0 || (fn.cached != nil && fn.cached()) {
+		rs.launch(i)
+	}
+}
+
+// ready launches the function at position i once its inputs are resolved,
+// unless it is deferred and not forced.
+func (rs *runState) ready(i int) {
+	if !rs.schedule.deferred[i] || rs.forced[i].Load() {
+		rs.launch(i)
+	}
+}
+
+// finish signals the lazy inputs waiting on the function at position i that
+// it will not produce anything more. If its outputs were not resolved, the
+// functions waiting on them will never start: they are finished too.
+func (rs *runState) finish(i int) {
+	if rs.finished == nil || rs.closed[i].Swap(true) {
+		return
+	}
+	close(rs.finished[i])
+	if rs.resolved[i].Load() {
+		return
+	}
+	for _, out := range rs.schedule.funcs[i].outs {
+		for _, c := range rs.schedule.consumers[out.signal] {
+			if !rs.started[c].Load() {
+				rs.finish(c)
+			}
+		}
+	}
+}
+
+// lazyState allocates the state of the lazy inputs of a run, if its schedule
+// has any.
+func (rs *runState) lazyState(s *schedule) {
+	if !s.lazy {
+		return
+	}
+	rs.forced = make([]atomic.Bool, len(s.funcs))
+	rs.closed = make([]atomic.Bool, len(s.funcs))
+	rs.finished = make([]chan struct{}, len(s.funcs))
+	for i := range rs.finished {
+		rs.finished[i] = make(chan struct{})
+	}
+}
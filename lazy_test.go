@@ -0,0 +1,121 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Lazy(t *testing.T) {
+	type (
+		request  string
+		cacheHit bool
+		features string
+		scores   string
+		response string
+	)
+
+	// initialize returns an engine computing scores only on cache misses
+	initialize := func(t *testing.T, calls *atomic.Int32, opts ...any) *Engine {
+		fns := []any{
+			func(r request) features {
+				calls.Add(1)
+				return features("features of " + r)
+			},
+			func(f features) (scores, error) {
+				calls.Add(1)
+				if f == "features of <fail>" {
+					return "", errors.New("<scoring failed>")
+				}
+				return scores("scores of " + f), nil
+			},
+			func(ctx context.Context, hit cacheHit, s Lazy[scores]) (response, error) {
+				if hit {
+					return "cached", nil
+				}
+				v, err := s.Get(ctx)
+				return response(v), err
+			},
+		}
+		ngn, err := Initialize(append(fns, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	t.Run("should not run the producers if the value is not needed", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn := initialize(t, &calls)
+
+		var report Report
+		out, err := Run[response](context.Background(), ngn, request("<r>"), cacheHit(true), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, response("cached"), out)
+		assert.Equal(t, int32(0), calls.Load())
+		assert.Equal(t, StatusNotStarted, report.Functions[0].Status)
+		assert.Equal(t, StatusNotStarted, report.Functions[1].Status)
+	})
+
+	t.Run("should run the producers once the value is needed", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn := initialize(t, &calls)
+
+		out, err := Run[response](context.Background(), ngn, request("<r>"), cacheHit(false))
+		assert.NoError(t, err)
+		assert.Equal(t, response("scores of features of <r>"), out)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("should run the producers under the sequential and cooperative executions", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn := initialize(t, &calls, WithCooperativeExecution(1))
+
+		out, err := Run[response](context.Background(), ngn, request("<r>"), cacheHit(false), WithRunMode(RunMode{Execution: Sequential}))
+		assert.NoError(t, err)
+		assert.Equal(t, response("scores of features of <r>"), out)
+	})
+
+	t.Run("should run the producers of the output of the run", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn := initialize(t, &calls)
+
+		out, err := Run[scores](context.Background(), ngn, request("<r>"), cacheHit(true))
+		assert.NoError(t, err)
+		assert.Equal(t, scores("scores of features of <r>"), out)
+	})
+
+	t.Run("should return an error if the value can not be produced", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn := initialize(t, &calls)
+
+		_, err := Run[response](context.Background(), ngn, request("<fail>"), cacheHit(false), WithRunMode(RunMode{Failure: FailAggregate}))
+		assertErrContains(t, err, "<scoring failed>")
+		assertErrContains(t, err, "lazy input warp_test.scores is not available: function")
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = Run[response](ctx, ngn, cacheHit(false), WithRunMode(RunMode{Failure: FailAggregate}))
+		assertErrContains(t, err, "lazy input warp_test.scores is not available")
+	})
+
+	t.Run("should return a validation error for lazy cycles", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(s Lazy[scores]) features { return "" },
+			func(f features) scores { return "" },
+		)
+		assertErrContains(t, err, "cyclic dependency detected")
+	})
+}
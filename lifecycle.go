@@ -0,0 +1,233 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// own registers a resource released by Engine.Close. A resource acquired after
+// the engine was closed is released immediately.
+func (e *Engine) own(release Cleanup) {
+	if release == nil {
+		return
+	}
+
+	e.mu.Lock()
+	if !e.closed {
+		e.owned.add(release)
+		e.mu.Unlock()
+		return
+	}
+	e.mu.Unlock()
+
+	_ = release(context.Background())
+}
+
+// Close releases the resources owned by the engine: the Cleanup outputs and
+// io.Closer outputs of singleton functions. Resources are released in reverse
+// order of acquisition and all release errors are joined.
+//
+// Once closed, the engine refuses to run and stops any background logging.
+// Calling Close more than once is a no-op. Close does not wait for in-flight
+// runs, use Shutdown to drain them first.
+func (e *Engine) Close() error {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.stopping = true
+	close(e.done)
+	e.mu.Unlock()
+
+	return e.owned.run(context.Background())
+}
+
+// WithStopHook registers hook to be called by Engine.Shutdown once the
+// in-flight runs are drained or abandoned, before the engine is closed, such
+// as to flush buffered metrics or deregister from service discovery. Hooks are
+// called once, in the order they are registered, and their errors are joined
+// to the error returned by Shutdown.
+func WithStopHook(hook func(ctx context.Context) error) Option {
+	return func(c *config) {
+		if hook != nil {
+			c.stopHooks = append(c.stopHooks, hook)
+		}
+	}
+}
+
+// abandonGrace bounds the time Shutdown waits for the runs it cancelled to
+// return, and the time given to the stop hooks once the shutdown deadline has
+// passed.
+const abandonGrace = 5 * time.Second
+
+// ShutdownReport describes the in-flight runs found by Engine.Shutdown.
+type ShutdownReport struct {
+	// Drained is the number of in-flight runs that finished before the
+	// shutdown deadline.
+	Drained int
+	// Abandoned holds the IDs of the in-flight runs that were cancelled
+	// because they were still running at the shutdown deadline, see
+	// RunIDFromContext.
+	Abandoned []string
+}
+
+// Shutdown gracefully stops the engine. It immediately stops accepting new
+// runs, other than the nested runs of the in-flight runs, see Invoke, waits
+// for the in-flight runs to finish until ctx is done, cancels the runs that
+// are still in flight at that point, calls the stop hooks and finally closes
+// the engine. The resources of the engine are only released once the
+// cancelled runs have returned, or after a grace period of 5 seconds if they
+// do not.
+//
+// The returned report tells how many runs were drained and which were
+// abandoned. If any run was abandoned the error wraps ctx.Err(); it is joined
+// with any error returned by the stop hooks and by Close.
+func (e *Engine) Shutdown(ctx context.Context) (ShutdownReport, error) {
+	var report ShutdownReport
+	if e == nil {
+		return report, nil
+	}
+
+	e.mu.Lock()
+	e.stopping = true
+	report.Drained = len(e.inflight)
+	if e.drained == nil {
+		e.drained = make(chan struct{})
+		if len(e.inflight) == 0 {
+			close(e.drained)
+		}
+	}
+	drained := e.drained
+	e.mu.Unlock()
+
+	var err error
+	hookCtx := ctx
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		e.mu.Lock()
+		for _, run := range e.inflight {
+			run.cancel()
+			report.Abandoned = append(report.Abandoned, run.id)
+		}
+		e.mu.Unlock()
+		slices.Sort(report.Abandoned)
+		report.Drained -= len(report.Abandoned)
+
+		if len(report.Abandoned) > 0 {
+			err = fmt.Errorf("abandoned %d in-flight run(s): %w", len(report.Abandoned), ctx.Err())
+		}
+
+		// The cancelled runs may still be using the resources of the engine
+		grace, cancel := context.WithTimeout(context.WithoutCancel(ctx), abandonGrace)
+		defer cancel()
+		select {
+		case <-drained:
+		case <-grace.Done():
+		}
+		hookCtx = grace
+	}
+
+	return report, errors.Join(err, e.stop(hookCtx), e.Close())
+}
+
+// stop calls the stop hooks of the engine, once.
+func (e *Engine) stop(ctx context.Context) error {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return nil
+	}
+	e.stopped = true
+	e.mu.Unlock()
+
+	var errs []error
+	for _, hook := range e.cfg.stopHooks {
+		errs = append(errs, hook(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// ShutdownOnSignal blocks until one of the signals is received or ctx is done
+// and then shuts the engine down, giving in-flight runs up to timeout to
+// finish. When no signals are given it listens for os.Interrupt and
+// syscall.SIGTERM, the signal sent by Kubernetes before it kills a pod.
+func (e *Engine) ShutdownOnSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) (ShutdownReport, error) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx, signals...)
+	<-sigCtx.Done()
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	return e.Shutdown(shutdownCtx)
+}
+
+// inflightRun is a run registered by startRun.
+type inflightRun struct {
+	id     string
+	cancel context.CancelFunc
+}
+
+// startRun registers a new in-flight run, identified by the run ID carried by
+// ctx. It returns the context the run must use, which is cancelled if the run
+// is abandoned by Shutdown, and a function that must be called once the run
+// has finished.
+//
+// A nested run, started with Invoke, counts as part of the in-flight run
+// enclosing it: it is not registered, and runs while the engine is shutting
+// down so that Shutdown can drain the enclosing run.
+func (e *Engine) startRun(ctx context.Context, nested bool) (context.Context, func(), error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil, nil, categorize(ErrClosed, "error running engine that has been closed")
+	}
+	if nested {
+		return ctx, func() {}, nil
+	}
+	if e.stopping {
+		return nil, nil, categorize(ErrShuttingDown, "error running engine that is shutting down")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	id := e.nextRunID
+	e.nextRunID++
+	if e.inflight == nil {
+		e.inflight = map[uint64]inflightRun{}
+	}
+	runID, _ := RunIDFromContext(ctx)
+	e.inflight[id] = inflightRun{id: runID, cancel: cancel}
+
+	return ctx, func() {
+		cancel()
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.inflight, id)
+		if len(e.inflight) == 0 && e.drained != nil {
+			select {
+			case <-e.drained:
+			default:
+				close(e.drained)
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,189 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_EngineShutdown(t *testing.T) {
+	type (
+		job    string
+		result string
+	)
+
+	t.Run("should drain in-flight runs and refuse new ones", func(t *testing.T) {
+		t.Parallel()
+		started, release := make(chan struct{}), make(chan struct{})
+		var closed atomic.Int32
+		ngn, err := Initialize(
+			func(in job) (result, Cleanup) {
+				close(started)
+				<-release
+				return result(in), nil
+			},
+			Singleton(func(in job) *closerType { return &closerType{&closed} }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runErr := make(chan error)
+		go func() {
+			_, err := Run[result](context.Background(), ngn, job("<job>"))
+			runErr <- err
+		}()
+		<-started
+
+		shutdown := make(chan ShutdownReport)
+		go func() {
+			report, err := ngn.Shutdown(context.Background())
+			assert.NoError(t, err)
+			shutdown <- report
+		}()
+
+		assert.Eventually(t, func() bool {
+			_, err := Run[result](context.Background(), ngn, job("<job>"))
+			return errors.Is(err, ErrShuttingDown)
+		}, time.Second, time.Millisecond)
+
+		close(release)
+		assert.NoError(t, <-runErr)
+		assert.Equal(t, ShutdownReport{Drained: 1}, <-shutdown)
+		assert.Equal(t, int32(1), closed.Load())
+	})
+
+	t.Run("should let the in-flight runs start nested runs while shutting down", func(t *testing.T) {
+		t.Parallel()
+		started, release := make(chan struct{}), make(chan struct{})
+		ngn, err := Initialize(
+			func(ctx context.Context, inv Invoker, in job) (result, error) {
+				if in != "<job>" {
+					return result(in), nil
+				}
+				close(started)
+				<-release
+				return Invoke[result](ctx, inv, job("<nested>"))
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runErr := make(chan error)
+		go func() {
+			res, err := Run[result](context.Background(), ngn, job("<job>"))
+			assert.Equal(t, result("<nested>"), res)
+			runErr <- err
+		}()
+		<-started
+
+		shutdown := make(chan ShutdownReport)
+		go func() {
+			report, err := ngn.Shutdown(context.Background())
+			assert.NoError(t, err)
+			shutdown <- report
+		}()
+
+		assert.Eventually(t, func() bool {
+			_, err := Run[result](context.Background(), ngn, job("<job>"))
+			return errors.Is(err, ErrShuttingDown)
+		}, time.Second, time.Millisecond)
+
+		close(release)
+		assert.NoError(t, <-runErr)
+		assert.Equal(t, ShutdownReport{Drained: 1}, <-shutdown)
+	})
+
+	t.Run("should abandon in-flight runs at the deadline", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		var closed, closedWhileRunning atomic.Int32
+		ngn, err := Initialize(
+			func(ctx context.Context, in job, c *closerType) (result, error) {
+				close(started)
+				<-ctx.Done()
+				// The resources of the engine are still in use
+				time.Sleep(20 * time.Millisecond)
+				closedWhileRunning.Store(closed.Load())
+				return "", ctx.Err()
+			},
+			Singleton(func(in job) *closerType { return &closerType{&closed} }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runErr := make(chan error)
+		go func() {
+			_, err := Run[result](context.Background(), ngn, job("<job>"), WithRunID("<run>"))
+			runErr <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		report, err := ngn.Shutdown(ctx)
+		assert.Equal(t, ShutdownReport{Abandoned: []string{"<run>"}}, report)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.True(t, errors.Is(<-runErr, context.Canceled))
+		assert.Equal(t, int32(0), closedWhileRunning.Load(), "resources released before the run returned")
+		assert.Equal(t, int32(1), closed.Load())
+	})
+
+	t.Run("should call the stop hooks once before closing the engine", func(t *testing.T) {
+		t.Parallel()
+		var calls []string
+		var closed atomic.Int32
+		ngn, err := Initialize(
+			Singleton(func(in job) *closerType { return &closerType{&closed} }),
+			WithStopHook(func(ctx context.Context) error {
+				calls = append(calls, fmt.Sprintf("first, closed %d", closed.Load()))
+				return nil
+			}),
+			WithStopHook(func(ctx context.Context) error {
+				calls = append(calls, "second")
+				return errors.New("<hook error>")
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Run[*closerType](context.Background(), ngn, job("<job>"))
+		assert.NoError(t, err)
+
+		_, err = ngn.Shutdown(context.Background())
+		assertErr(t, err, "<hook error>")
+		_, err = ngn.Shutdown(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first, closed 0", "second"}, calls)
+		assert.Equal(t, int32(1), closed.Load())
+	})
+
+	t.Run("should shut down when the context is done before a signal", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(in job) result { return result(in) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		report, err := ngn.ShutdownOnSignal(ctx, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, ShutdownReport{}, report)
+
+		_, err = Run[result](context.Background(), ngn, job("<job>"))
+		assertErr(t, err, "error running engine that has been closed")
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
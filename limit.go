@@ -0,0 +1,23 @@
+package warp
+
+import "errors"
+
+// Limited bounds the number of calls of fn executing at once to n, across
+// all the runs of the engine, so that a function putting load on a fragile
+// dependency, such as a database, can be protected without limiting the runs
+// themselves. A call over the limit waits for a previous one to return, by
+// priority and critical path, see Prioritized and Estimated, and the time it
+// waits is reported in FunctionEvent.Delay. It stops waiting once its run is
+// cancelled, and is not called.
+//
+// A Limited function running other runs with the Invoker holds its slot while
+// they execute: it must not be called by them.
+func Limited(fn any, n int) *Provider {
+	p := asProvider(fn)
+	if n < 1 {
+		p.err = errors.New("concurrency limit must be positive")
+		return p
+	}
+	p.limit = n
+	return p
+}
@@ -0,0 +1,141 @@
+package warp_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Limited(t *testing.T) {
+	type (
+		in  int
+		a   int
+		out int
+	)
+
+	t.Run("should bound the calls of a function across concurrent runs", func(t *testing.T) {
+		t.Parallel()
+		var active, peak, unlimited atomic.Int32
+		ngn, err := Initialize(
+			Limited(func(i in) a {
+				n := active.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				active.Add(-1)
+				return a(i)
+			}, 2),
+			func(a a) out {
+				unlimited.Add(1)
+				return out(a)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := range 6 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := Run[out](context.Background(), ngn, in(i))
+				assert.NoError(t, err)
+				assert.Equal(t, out(i), res)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(2), peak.Load())
+		assert.Equal(t, int32(6), unlimited.Load())
+	})
+
+	t.Run("should report the time a call waited for the limit", func(t *testing.T) {
+		t.Parallel()
+		l := &delayListener{delays: map[string]time.Duration{}}
+		entered, release := make(chan struct{}, 2), make(chan struct{})
+		ngn, err := Initialize(
+			Limited(func(i in) out { entered <- struct{}{}; <-release; return out(i) }, 1),
+			WithListener(l),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := Run[out](context.Background(), ngn, in(i))
+				assert.NoError(t, err)
+			}()
+			if i == 0 {
+				<-entered
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		// The call waiting for the limit returns last
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		assert.Len(t, l.delays, 1)
+		for _, delay := range l.delays {
+			assert.GreaterOrEqual(t, delay, 10*time.Millisecond)
+		}
+	})
+
+	t.Run("should stop waiting for the limit once the run is cancelled", func(t *testing.T) {
+		t.Parallel()
+		entered, release := make(chan struct{}), make(chan struct{})
+		ngn, err := Initialize(
+			Limited(func(i in) out {
+				if i == 0 {
+					close(entered)
+					<-release
+				}
+				return out(i)
+			}, 1),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := Run[out](context.Background(), ngn, in(0))
+			assert.NoError(t, err)
+		}()
+		<-entered
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = Run[out](ctx, ngn, in(1))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// The cancelled call gave up its place: the slot is not lost
+		close(release)
+		<-done
+		res, err := Run[out](context.Background(), ngn, in(2))
+		assert.NoError(t, err)
+		assert.Equal(t, out(2), res)
+	})
+
+	t.Run("should reject a limit lower than one", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Limited(func(i in) out { return out(i) }, 0))
+		assertErr(t, err, "input validation error: concurrency limit must be positive")
+	})
+}
@@ -0,0 +1,52 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LintWarning describes a function of the engine that can never run.
+type LintWarning struct {
+	// Function is the name of the function.
+	Function string
+	// Location is the file:line source location of the function.
+	Location string
+	// Missing holds the required inputs of the function that neither a
+	// provided value nor a function that can run supplies.
+	Missing []reflect.Type
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("function %s can never run: %s", w.Function, describeMissing(w.Missing))
+}
+
+// Lint returns a warning per function of the engine that can never run when
+// the runs are provided with values of the provided types: a required input
+// of the function is neither provided, nor produced by a function that can
+// run, nor given a Default. The functions depending on such a function are
+// reported too. Calling Lint with the types the application provides to its
+// runs catches the functions left behind by a refactoring, such as the
+// consumers of a type nothing produces or provides anymore. Optional types
+// are unwrapped, and warnings are in registration order.
+func (e *Engine) Lint(provided ...reflect.Type) []LintWarning {
+	if e == nil || !e.initialized {
+		return nil
+	}
+
+	stored := make(map[reflect.Type]bool, len(provided))
+	for _, t := range provided {
+		if t != nil {
+			t, _ = unwrapOptional(t)
+			stored[t] = true
+		}
+	}
+
+	var out []LintWarning
+	r := e.schedule.reachableFrom(e.values, stored)
+	for i, fn := range e.schedule.funcs {
+		if !r.reachable[i] && len(r.missing[i]) > 0 {
+			out = append(out, LintWarning{Function: fn.name, Location: fn.location, Missing: r.missing[i]})
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package warp_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Lint(t *testing.T) {
+	type (
+		userID   string
+		legacyID string
+		locale   string
+		profile  string
+		archive  string
+		page     string
+	)
+
+	ngn, err := Initialize(
+		func(id userID, l Optional[locale]) profile { return profile(id) },
+		func(id legacyID) archive { return archive(id) },
+		func(a archive, p profile) page { return page(a) + page(p) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should report the functions that can never run", func(t *testing.T) {
+		t.Parallel()
+		warnings := ngn.Lint(reflect.TypeOf(userID("")))
+
+		if assert.Len(t, warnings, 2) {
+			assert.Contains(t, warnings[0].Function, "Test_Lint.func2")
+			assert.NotEmpty(t, warnings[0].Location)
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(legacyID(""))}, warnings[0].Missing)
+			assert.Equal(t, "function "+warnings[0].Function+" can never run: input warp_test.legacyID is not available", warnings[0].String())
+
+			assert.Contains(t, warnings[1].Function, "Test_Lint.func3")
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(archive(""))}, warnings[1].Missing)
+		}
+	})
+
+	t.Run("should not report the functions that can run", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, ngn.Lint(reflect.TypeOf(userID("")), reflect.TypeOf(Optional[legacyID]{})))
+	})
+
+	t.Run("should not report the functions whose inputs have a default", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(id legacyID) archive { return archive(id) },
+			Default(legacyID("<id>")),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, ngn.Lint())
+	})
+
+	t.Run("should not report anything for an engine not initialized", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, (&Engine{}).Lint())
+	})
+}
@@ -0,0 +1,110 @@
+package warp
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// EventListener is notified of the progress of the runs of an engine. It is
+// the extension point for audit logs, metrics emitters and progress displays.
+// Register it with WithListener.
+//
+// The methods are called synchronously by the goroutine running the run or
+// the function, so they must be fast and safe for concurrent use.
+type EventListener interface {
+	// OnRunStart is called once the provided inputs of a run are validated,
+	// before any function runs.
+	OnRunStart(ctx context.Context, ev RunEvent)
+	// OnRunEnd is called once every function of the run has returned and
+	// the cleanups have run.
+	OnRunEnd(ctx context.Context, ev RunEvent)
+	// OnFunctionStart is called before a function checks its inputs.
+	OnFunctionStart(ctx context.Context, ev FunctionEvent)
+	// OnFunctionEnd is called once a started function has returned or has
+	// been skipped because an input turned out to be missing, with its
+	// status.
+	OnFunctionEnd(ctx context.Context, ev FunctionEvent)
+	// OnFunctionSkipped is called for a function skipped before it started,
+	// because its inputs can not be produced by the run.
+	OnFunctionSkipped(ctx context.Context, ev FunctionEvent)
+}
+
+// RunEvent describes a run.
+type RunEvent struct {
+	// Target is the type produced by the run.
+	Target reflect.Type
+	// Err is the error returned by the run, set on OnRunEnd.
+	Err error
+	// Duration is the duration of the run, set on OnRunEnd.
+	Duration time.Duration
+}
+
+// FunctionEvent describes a function of a run.
+type FunctionEvent struct {
+	// Name refers to the function, as in reports.
+	Name string
+	// Status is the outcome of the function, set on OnFunctionEnd and
+	// OnFunctionSkipped.
+	Status Status
+	// Err is the error returned by the function, set on OnFunctionEnd.
+	Err error
+	// Duration is the duration of the function, set on OnFunctionEnd.
+	Duration time.Duration
+	// Delay is the time the function waited to be scheduled once its inputs
+	// were available, set on OnFunctionStart and OnFunctionEnd.
+	Delay time.Duration
+}
+
+// NopListener implements every EventListener method as a no-op. Embed it to
+// implement only the methods of interest.
+type NopListener struct{}
+
+func (NopListener) OnRunStart(context.Context, RunEvent)             {}
+func (NopListener) OnRunEnd(context.Context, RunEvent)               {}
+func (NopListener) OnFunctionStart(context.Context, FunctionEvent)   {}
+func (NopListener) OnFunctionEnd(context.Context, FunctionEvent)     {}
+func (NopListener) OnFunctionSkipped(context.Context, FunctionEvent) {}
+
+// WithListener registers l with the engine. Listeners are notified in
+// registration order.
+func WithListener(l EventListener) Option {
+	return func(c *config) {
+		if l != nil {
+			c.listeners = append(c.listeners, l)
+		}
+	}
+}
+
+// listeners dispatches events to the registered listeners.
+type listeners []EventListener
+
+func (ls listeners) runStart(ctx context.Context, ev RunEvent) {
+	for _, l := range ls {
+		l.OnRunStart(ctx, ev)
+	}
+}
+
+func (ls listeners) runEnd(ctx context.Context, ev RunEvent) {
+	for _, l := range ls {
+		l.OnRunEnd(ctx, ev)
+	}
+}
+
+func (ls listeners) functionStart(ctx context.Context, ev FunctionEvent) {
+	for _, l := range ls {
+		l.OnFunctionStart(ctx, ev)
+	}
+}
+
+func (ls listeners) functionEnd(ctx context.Context, ev FunctionEvent) {
+	for _, l := range ls {
+		l.OnFunctionEnd(ctx, ev)
+	}
+}
+
+func (ls listeners) functionSkipped(ctx context.Context, ev FunctionEvent) {
+	for _, l := range ls {
+		l.OnFunctionSkipped(ctx, ev)
+	}
+}
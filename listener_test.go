@@ -0,0 +1,93 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+// recordingListener records the events it receives.
+type recordingListener struct {
+	NopListener
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *recordingListener) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *recordingListener) OnRunStart(_ context.Context, ev RunEvent) {
+	l.add(fmt.Sprintf("run start %s", ev.Target))
+}
+
+func (l *recordingListener) OnRunEnd(_ context.Context, ev RunEvent) {
+	l.add(fmt.Sprintf("run end %s %v", ev.Target, ev.Err))
+}
+
+func (l *recordingListener) OnFunctionEnd(_ context.Context, ev FunctionEvent) {
+	l.add(fmt.Sprintf("function end %s %v", ev.Status, ev.Err))
+}
+
+func (l *recordingListener) OnFunctionSkipped(_ context.Context, ev FunctionEvent) {
+	l.add(fmt.Sprintf("function skipped %s", ev.Status))
+}
+
+func Test_WithListener(t *testing.T) {
+	type (
+		in      string
+		missing string
+		a       string
+		b       string
+	)
+
+	t.Run("should notify listeners of the run and function events", func(t *testing.T) {
+		t.Parallel()
+		l := &recordingListener{}
+		ngn, err := Initialize(
+			func(i in) a { return a(i) },
+			func(m missing) b { return b(m) },
+			WithListener(l),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[a](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"run start warp_test.a",
+			"function skipped skipped",
+			"function end succeeded <nil>",
+			"run end warp_test.a <nil>",
+		}, l.events)
+	})
+
+	t.Run("should notify listeners of function errors", func(t *testing.T) {
+		t.Parallel()
+		l := &recordingListener{}
+		ngn, err := Initialize(
+			func(i in) (a, error) { return "", errors.New("<error>") },
+			WithListener(l),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[a](context.Background(), ngn, in("<in>"))
+		assertErr(t, err, "<error>")
+		assert.Equal(t, []string{
+			"run start warp_test.a",
+			"function end failed <error>",
+			"run end warp_test.a <error>",
+		}, l.events)
+	})
+}
@@ -0,0 +1,74 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Service is the set of the exported methods of a service registered with
+// Methods.
+type Service struct {
+	svc any
+}
+
+// Methods registers every exported method of svc as a function of the
+// engine, bound to svc, so a service struct with many constructor-like
+// methods is registered in one go. Pass a pointer to the struct to register
+// its methods with a pointer receiver too. Every method is validated like any
+// other function, and is named after the method in reports and errors.
+//
+// The returned Service is passed to Initialize in place of the functions.
+func Methods(svc any) *Service {
+	return &Service{svc: svc}
+}
+
+// providers returns a Provider per exported method of the service, in the
+// order of the method set, or a single Provider holding the error if the
+// service can not be registered.
+func (s *Service) providers() []*Provider {
+	svcV := reflect.ValueOf(s.svc)
+	if !svcV.IsValid() || !isStructOrPointer(svcV.Type()) || (svcV.Kind() == reflect.Pointer && svcV.IsNil()) {
+		return []*Provider{{fn: s.svc, err: fmt.Errorf("methods input must be a struct or a pointer to a struct, got %T", s.svc)}}
+	}
+	if svcV.NumMethod() == 0 {
+		return []*Provider{{fn: s.svc, err: fmt.Errorf("service %s has no exported methods", svcV.Type())}}
+	}
+
+	out := make([]*Provider, svcV.NumMethod())
+	for i := range out {
+		method, bound := svcV.Type().Method(i), svcV.Method(i)
+		out[i] = &Provider{
+			fn:       bound.Interface(),
+			name:     runtime.FuncForPC(method.Func.Pointer()).Name() + strings.TrimPrefix(bound.Type().String(), "func"),
+			location: locate(method.Func),
+		}
+	}
+	return out
+}
+
+// isStructOrPointer reports whether t is a struct or a pointer to a struct.
+func isStructOrPointer(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// splitServices replaces the Services passed to Initialize with the Providers
+// of their methods.
+func splitServices(args []any) []any {
+	out := make([]any, 0, len(args))
+	for _, arg := range args {
+		s, ok := arg.(*Service)
+		if !ok || s == nil {
+			out = append(out, arg)
+			continue
+		}
+		for _, p := range s.providers() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package warp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type (
+	dsn        string
+	connection string
+	repository string
+)
+
+type storage struct {
+	prefix string
+}
+
+func (s storage) Connect(d dsn) connection { return connection(s.prefix + string(d)) }
+
+func (s *storage) Repository(c connection) (repository, error) {
+	return repository("repository of " + c), nil
+}
+
+func (s storage) unexported(repository) outType1 { return "" }
+
+type noMethods struct{}
+
+type invalidService struct{}
+
+func (invalidService) Close() {}
+
+func Test_Methods(t *testing.T) {
+	t.Run("should register every exported method bound to the service", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Methods(&storage{prefix: "db:"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[repository](context.Background(), ngn, dsn("<dsn>"))
+		assert.NoError(t, err)
+		assert.Equal(t, repository("repository of db:<dsn>"), out)
+	})
+
+	t.Run("should only register the methods with a value receiver of a struct value", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Methods(storage{prefix: "db:"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[connection](context.Background(), ngn, dsn("<dsn>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, connection("db:<dsn>"), out)
+		if assert.Len(t, report.Functions, 1) {
+			assert.True(t, strings.HasPrefix(report.Functions[0].Name, "github.com/dezlitz/warp_test.storage.Connect(warp_test.dsn)"), report.Functions[0].Name)
+		}
+	})
+
+	t.Run("should validate the methods like any function", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Methods(invalidService{}))
+		assertErrContains(t, err, "invalidService.Close")
+		assertErrContains(t, err, "must not have no return type(s)")
+	})
+
+	t.Run("should reject a service that is not a struct", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Methods("<not-a-service>"))
+		assertErr(t, err, "input validation error: methods input must be a struct or a pointer to a struct, got string")
+
+		_, err = Initialize(Methods((*storage)(nil)))
+		assertErr(t, err, "input validation error: methods input must be a struct or a pointer to a struct, got *warp_test.storage")
+
+		_, err = Initialize(Methods(noMethods{}))
+		assertErr(t, err, "input validation error: service warp_test.noMethods has no exported methods")
+	})
+}
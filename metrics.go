@@ -0,0 +1,176 @@
+package warp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBuckets are the upper bounds in seconds of the latency histogram
+// buckets used when none are given to NewMetrics.
+var defaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects per-function execution metrics: counters of runs, errors,
+// skips and cancellations, the total time the functions waited to be
+// scheduled, and a latency histogram of the functions that were called.
+// Metrics is an EventListener, register it with WithMetrics.
+//
+// Metrics is an http.Handler serving the metrics in the Prometheus text
+// exposition format, so it can be scraped directly:
+//
+//	metrics := warp.NewMetrics()
+//	engine, err := warp.Initialize(fns, warp.WithMetrics(metrics))
+//	http.Handle("/metrics", metrics)
+type Metrics struct {
+	NopListener
+	buckets []float64
+
+	mu        sync.Mutex
+	functions map[string]*functionMetrics
+}
+
+type functionMetrics struct {
+	runs, errors, skips, cancellations uint64
+	// delay is the total scheduling delay in seconds, see FunctionEvent.Delay
+	delay float64
+	// buckets holds the number of observations per bucket, not cumulated
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewMetrics returns an empty Metrics. buckets are the upper bounds in seconds
+// of the latency histogram buckets, from 1ms to 10s by default.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = defaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{buckets: buckets, functions: map[string]*functionMetrics{}}
+}
+
+// WithMetrics records the execution metrics of the functions of the engine in
+// m. A Metrics may be shared by several engines.
+func WithMetrics(m *Metrics) Option {
+	if m == nil {
+		return func(*config) {}
+	}
+	return WithListener(m)
+}
+
+// OnFunctionEnd records the outcome of a function.
+func (m *Metrics) OnFunctionEnd(_ context.Context, ev FunctionEvent) {
+	m.observe(ev.Name, ev.Status, ev.Duration, ev.Delay)
+}
+
+// OnFunctionSkipped records the skip of a function.
+func (m *Metrics) OnFunctionSkipped(_ context.Context, ev FunctionEvent) {
+	m.observe(ev.Name, ev.Status, 0, 0)
+}
+
+func (m *Metrics) observe(name string, status Status, d, delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fm, ok := m.functions[name]
+	if !ok {
+		fm = &functionMetrics{buckets: make([]uint64, len(m.buckets))}
+		m.functions[name] = fm
+	}
+	fm.delay += delay.Seconds()
+
+	switch status {
+	case StatusSkipped:
+		fm.skips++
+		return
+	case StatusCancelled:
+		fm.cancellations++
+		return
+	case StatusFailed:
+		fm.errors++
+	case StatusSucceeded:
+	default:
+		return
+	}
+
+	fm.runs++
+	seconds := d.Seconds()
+	fm.count++
+	fm.sum += seconds
+	for i, le := range m.buckets {
+		if seconds <= le {
+			fm.buckets[i]++
+			break
+		}
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.functions))
+	for name := range m.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var cw bytes.Buffer
+	for _, counter := range []struct {
+		name, help string
+		value      func(*functionMetrics) uint64
+	}{
+		{"warp_function_runs_total", "Number of calls of the function.", func(fm *functionMetrics) uint64 { return fm.runs }},
+		{"warp_function_errors_total", "Number of calls of the function that returned an error.", func(fm *functionMetrics) uint64 { return fm.errors }},
+		{"warp_function_skips_total", "Number of runs in which the function was skipped.", func(fm *functionMetrics) uint64 { return fm.skips }},
+		{"warp_function_cancellations_total", "Number of runs in which the function was cancelled.", func(fm *functionMetrics) uint64 { return fm.cancellations }},
+	} {
+		fmt.Fprintf(&cw, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+		for _, name := range names {
+			fmt.Fprintf(&cw, "%s{function=%s} %d\n", counter.name, quoteLabel(name), counter.value(m.functions[name]))
+		}
+	}
+
+	const delay = "warp_function_scheduling_delay_seconds_total"
+	fmt.Fprintf(&cw, "# HELP %s Time the function waited to be scheduled once its inputs were available.\n# TYPE %s counter\n", delay, delay)
+	for _, name := range names {
+		fmt.Fprintf(&cw, "%s{function=%s} %s\n", delay, quoteLabel(name), strconv.FormatFloat(m.functions[name].delay, 'g', -1, 64))
+	}
+
+	const histogram = "warp_function_duration_seconds"
+	fmt.Fprintf(&cw, "# HELP %s Duration of the calls of the function.\n# TYPE %s histogram\n", histogram, histogram)
+	for _, name := range names {
+		fm, label := m.functions[name], quoteLabel(name)
+		var cumulative uint64
+		for i, le := range m.buckets {
+			cumulative += fm.buckets[i]
+			fmt.Fprintf(&cw, "%s_bucket{function=%s,le=\"%s\"} %d\n", histogram, label, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&cw, "%s_bucket{function=%s,le=\"+Inf\"} %d\n", histogram, label, fm.count)
+		fmt.Fprintf(&cw, "%s_sum{function=%s} %s\n", histogram, label, strconv.FormatFloat(fm.sum, 'g', -1, 64))
+		fmt.Fprintf(&cw, "%s_count{function=%s} %d\n", histogram, label, fm.count)
+	}
+
+	return cw.WriteTo(w)
+}
+
+// quoteLabel quotes a label value, escaping backslashes, double quotes and
+// line feeds.
+func quoteLabel(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
@@ -0,0 +1,70 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type (
+	metricsIn      string
+	metricsMissing string
+	metricsA       string
+	metricsB       string
+	metricsC       string
+)
+
+func Test_Metrics(t *testing.T) {
+	t.Run("should expose per-function counters and latency histograms", func(t *testing.T) {
+		t.Parallel()
+		metrics := NewMetrics(0.01, 1)
+		ngn, err := Initialize(
+			func(i metricsIn) (metricsA, error) {
+				if i == "<fail>" {
+					return "", errors.New("<error>")
+				}
+				time.Sleep(20 * time.Millisecond)
+				return metricsA(i), nil
+			},
+			func(m metricsMissing) metricsB { return metricsB(m) },
+			func(a metricsA, b Optional[metricsB]) metricsC { return metricsC(a) },
+			WithMetrics(metrics),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[metricsC](context.Background(), ngn, metricsIn("<in>"))
+		assert.NoError(t, err)
+		_, err = Run[metricsC](context.Background(), ngn, metricsIn("<fail>"))
+		assertErr(t, err, "<error>")
+
+		rec := httptest.NewRecorder()
+		metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body := rec.Body.String()
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+
+		const (
+			a = `{function="github.com/dezlitz/warp_test.Test_Metrics.func1.1(warp_test.metricsIn) (warp_test.metricsA, error)"`
+			b = `{function="github.com/dezlitz/warp_test.Test_Metrics.func1.2(warp_test.metricsMissing) warp_test.metricsB"`
+		)
+		assert.Contains(t, body, "# TYPE warp_function_runs_total counter\n")
+		assert.Contains(t, body, "warp_function_runs_total"+a+"} 2\n")
+		assert.Contains(t, body, "warp_function_errors_total"+a+"} 1\n")
+		assert.Contains(t, body, "warp_function_skips_total"+b+"} 2\n")
+		assert.Contains(t, body, "warp_function_runs_total"+b+"} 0\n")
+		assert.Contains(t, body, "# TYPE warp_function_scheduling_delay_seconds_total counter\n")
+		assert.Contains(t, body, "warp_function_scheduling_delay_seconds_total"+b+"} 0\n")
+		assert.Contains(t, body, "# TYPE warp_function_duration_seconds histogram\n")
+		assert.Contains(t, body, "warp_function_duration_seconds_bucket"+a+`,le="0.01"} 1`+"\n")
+		assert.Contains(t, body, "warp_function_duration_seconds_bucket"+a+`,le="1"} 2`+"\n")
+		assert.Contains(t, body, "warp_function_duration_seconds_bucket"+a+`,le="+Inf"} 2`+"\n")
+		assert.Contains(t, body, "warp_function_duration_seconds_count"+a+"} 2\n")
+	})
+}
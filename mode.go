@@ -0,0 +1,152 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FailurePolicy decides what a run does when a function returns an error.
+type FailurePolicy int
+
+const (
+	// FailFast cancels the run on the first error and returns it. This is the
+	// default.
+	FailFast FailurePolicy = iota
+	// FailAggregate lets every function that does not depend on a failed
+	// function finish, and returns all the errors joined in registration
+	// order.
+	FailAggregate
+	// FailDrain stops starting functions on the first error and returns it
+	// once the functions in flight have returned, without cancelling them,
+	// so that they do not abandon what they were writing half-written.
+	FailDrain
+)
+
+// InputPolicy decides what a run does when a function can not run because one
+// of its required inputs is not available.
+type InputPolicy int
+
+const (
+	// Lenient skips the function and the functions depending on it. This is
+	// the default.
+	Lenient InputPolicy = iota
+	// Strict fails the run. Unreachable functions are detected before any
+	// function runs.
+	Strict
+)
+
+// ExecutionPolicy decides how the functions of a run are executed.
+type ExecutionPolicy int
+
+const (
+	// Concurrent runs every function as soon as its inputs are ready. This is
+	// the default.
+	Concurrent ExecutionPolicy = iota
+	// Sequential runs one function at a time, in dependency order, which
+	// makes runs easier to debug and to reproduce.
+	Sequential
+)
+
+// CancelPolicy decides what a run does with a function cancelled with
+// RunHandle.CancelFunction.
+type CancelPolicy int
+
+const (
+	// CancelAsError handles the cancelled function as a failed function.
+	// This is the default.
+	CancelAsError CancelPolicy = iota
+	// CancelAsSkip handles the cancelled function as a skipped function: its
+	// outputs are not available and the run proceeds.
+	CancelAsSkip
+)
+
+// RunMode is the policy of a run. The zero RunMode is the default policy:
+// FailFast, Lenient, Concurrent and CancelAsError.
+type RunMode struct {
+	Failure   FailurePolicy
+	Inputs    InputPolicy
+	Execution ExecutionPolicy
+	Cancel    CancelPolicy
+}
+
+// WithDefaultRunMode sets the RunMode of the runs of the engine that do not
+// pass WithRunMode.
+func WithDefaultRunMode(m RunMode) Option {
+	return func(c *config) {
+		c.mode = m
+	}
+}
+
+// WithRunMode sets the RunMode of a single run.
+func WithRunMode(m RunMode) RunOption {
+	return func(c *runConfig) {
+		c.mode = &m
+	}
+}
+
+// WithRequireTarget makes the run fail before any function runs if the target
+// type of the run can not be produced from the provided inputs, instead of
+// returning its zero value once the functions producing it have been skipped.
+// The check is static: a function that turns out to skip or not to set an
+// Optional output at run time still leaves the target unset.
+func WithRequireTarget() RunOption {
+	return func(c *runConfig) {
+		c.requireTarget = true
+	}
+}
+
+// validate returns an error if the mode holds an unknown policy.
+func (m RunMode) validate() error {
+	if m.Failure < FailFast || m.Failure > FailDrain {
+		return fmt.Errorf("invalid run mode: unknown failure policy %d", m.Failure)
+	}
+	if m.Inputs < Lenient || m.Inputs > Strict {
+		return fmt.Errorf("invalid run mode: unknown input policy %d", m.Inputs)
+	}
+	if m.Execution < Concurrent || m.Execution > Sequential {
+		return fmt.Errorf("invalid run mode: unknown execution policy %d", m.Execution)
+	}
+	if m.Cancel < CancelAsError || m.Cancel > CancelAsSkip {
+		return fmt.Errorf("invalid run mode: unknown cancel policy %d", m.Cancel)
+	}
+	return nil
+}
+
+// skip records that fn did not run because its inputs of the missing types
+// are not available. Under the Strict input policy the skip fails the run.
+func (rs *runState) skip(fn *function, missing []reflect.Type) error {
+	rs.report.recordMissing(fn, missing)
+	if rs.mode.Inputs == Strict {
+		err := fmt.Errorf("function %s can not run: %s", fn.name, describeMissing(missing))
+		rs.record(fn, StatusFailed, err)
+		return err
+	}
+
+	rs.resolve(fn)
+	rs.record(fn, StatusSkipped, nil)
+	return nil
+}
+
+// missingInputs returns the required inputs of fn whose values are not
+// available.
+func (rs *runState) missingInputs(fn *function) []reflect.Type {
+	var out []reflect.Type
+	for _, in := range fn.ins {
+		if in.injected() || in.lazy {
+			continue
+		}
+		if _, ok := loadValue(rs.storage, in); !ok {
+			out = append(out, in.key)
+		}
+	}
+	return out
+}
+
+// describeMissing describes missing input types in an error message.
+func describeMissing(missing []reflect.Type) string {
+	if len(missing) == 1 {
+		return fmt.Sprintf("input %s is not available", missing[0])
+	}
+	return fmt.Sprintf("inputs %s are not available", strings.Join(sliceConvert(reflect.Type.String, missing), ", "))
+}
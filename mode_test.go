@@ -0,0 +1,226 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunMode(t *testing.T) {
+	type (
+		in      string
+		missing string
+		a       string
+		b       string
+		c       string
+		d       string
+	)
+
+	t.Run("should return every error under the FailAggregate policy", func(t *testing.T) {
+		t.Parallel()
+		var ran atomic.Bool
+		ngn, err := Initialize(
+			func(i in) (a, error) { return "", errors.New("<a-error>") },
+			func(i in) (b, error) {
+				time.Sleep(10 * time.Millisecond)
+				return "", errors.New("<b-error>")
+			},
+			func(ctx context.Context, i in) (c, error) {
+				time.Sleep(20 * time.Millisecond)
+				ran.Store(ctx.Err() == nil)
+				return c(i), nil
+			},
+			func(x a, y c) d { return d(x) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[d](context.Background(), ngn, in("<in>"),
+			WithRunMode(RunMode{Failure: FailAggregate}), WithReport(&report))
+		assertErr(t, err, "<a-error>\n<b-error>")
+		assert.True(t, ran.Load())
+		if assert.Len(t, report.Functions, 4) {
+			assert.Equal(t, StatusSucceeded, report.Functions[2].Status)
+			assert.Equal(t, StatusNotStarted, report.Functions[3].Status)
+		}
+	})
+
+	t.Run("should let the functions in flight finish under the FailDrain policy", func(t *testing.T) {
+		t.Parallel()
+		var (
+			drained atomic.Bool
+			called  atomic.Bool
+			started = make(chan struct{})
+		)
+		ngn, err := Initialize(
+			func(i in) (a, error) {
+				<-started
+				return "", errors.New("<a-error>")
+			},
+			func(ctx context.Context, i in) b {
+				close(started)
+				time.Sleep(20 * time.Millisecond)
+				drained.Store(ctx.Err() == nil)
+				return b(i)
+			},
+			func(y b) c { called.Store(true); return c(y) },
+			func(x a, y c) d { return d(x) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[d](context.Background(), ngn, in("<in>"),
+			WithRunMode(RunMode{Failure: FailDrain}), WithReport(&report))
+		assertErr(t, err, "<a-error>")
+		assert.True(t, drained.Load())
+		assert.False(t, called.Load())
+		if assert.Len(t, report.Functions, 4) {
+			assert.Equal(t, StatusSucceeded, report.Functions[1].Status)
+			assert.Equal(t, StatusCancelled, report.Functions[2].Status)
+		}
+	})
+
+	t.Run("should fail before running any function under the Strict input policy", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			func(i in) a {
+				calls.Add(1)
+				return a(i)
+			},
+			func(m missing) b { return b(m) },
+			func(x a, y Optional[b]) c { return c(x) },
+			WithDefaultRunMode(RunMode{Inputs: Strict}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[c](context.Background(), ngn, in("<in>"))
+		assertErrContains(t, err, "can not run: input warp_test.missing is not available")
+		assert.Equal(t, int32(0), calls.Load())
+
+		out, err := Run[c](context.Background(), ngn, in("<in>"), WithRunMode(RunMode{}))
+		assert.NoError(t, err)
+		assert.Equal(t, c("<in>"), out)
+	})
+
+	t.Run("should fail on a skip at run time under the Strict input policy", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) Optional[a] { return Optional[a]{} },
+			func(x a) b { return b(x) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[b](context.Background(), ngn, in("<in>"), WithRunMode(RunMode{Inputs: Strict}))
+		assertErrContains(t, err, "can not run: input warp_test.a is not available")
+	})
+
+	t.Run("should run one function at a time under the Sequential execution policy", func(t *testing.T) {
+		t.Parallel()
+		var running, overlaps atomic.Int32
+		track := func() {
+			if running.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+		}
+		ngn, err := Initialize(
+			func(i in) a { track(); return a(i) },
+			func(i in) b { track(); return b(i) },
+			func(i in) c { track(); return c(i) },
+			func(x a, y b, z c) d { track(); return d(string(x) + string(y) + string(z)) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[d](context.Background(), ngn, in("<in>"), WithRunMode(RunMode{Execution: Sequential}))
+		assert.NoError(t, err)
+		assert.Equal(t, d("<in><in><in>"), out)
+		assert.Equal(t, int32(0), overlaps.Load())
+	})
+
+	t.Run("should return an error for an unknown policy", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(i in) a { return a(i) })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[a](context.Background(), ngn, in("<in>"), WithRunMode(RunMode{Failure: 7}))
+		assertErr(t, err, "invalid run mode: unknown failure policy 7")
+	})
+}
+
+func Test_WithRequireTarget(t *testing.T) {
+	type (
+		in      string
+		missing string
+		a       string
+		b       string
+	)
+
+	t.Run("should fail before running any function if the target can not be produced", func(t *testing.T) {
+		t.Parallel()
+		var called atomic.Bool
+		ngn, err := Initialize(
+			func(i in) a { called.Store(true); return a(i) },
+			func(m missing) b { return b(m) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[b](context.Background(), ngn, in("<in>"), WithRequireTarget())
+		assertErr(t, err, "target warp_test.b can not be produced: function github.com/dezlitz/warp_test.Test_WithRequireTarget.func1.2(warp_test.missing) warp_test.b can not run: input warp_test.missing is not available")
+		assert.False(t, called.Load())
+
+		out, err := Run[b](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+		assert.Zero(t, out)
+	})
+
+	t.Run("should run if the target can be produced", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) a { return a(i) },
+			func(x a, m Optional[missing]) b { return b(x) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[b](context.Background(), ngn, in("<in>"), WithRequireTarget())
+		assert.NoError(t, err)
+		assert.Equal(t, b("<in>"), out)
+	})
+
+	t.Run("should fail if the target is not produced by the tagged functions", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Tag(func(i in) a { return a(i) }, "<tag>"),
+			func(i in) b { return b(i) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RunTagged[b](context.Background(), ngn, "<tag>", in("<in>"), WithRequireTarget())
+		assertErr(t, err, "target warp_test.b can not be produced: no function of the run produces it")
+	})
+}
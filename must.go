@@ -0,0 +1,23 @@
+package warp
+
+import "context"
+
+// MustInitialize is like Initialize but panics if the engine can not be
+// initialized. It simplifies the wiring of an engine in main functions and
+// examples, where an invalid graph is a programming error.
+func MustInitialize(fns ...any) *Engine {
+	e, err := Initialize(fns...)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// MustRunOne is like RunOne but panics if the run fails.
+func MustRunOne[T any](ctx context.Context, e *Engine, provided ...any) T {
+	out, err := RunOne[T](ctx, e, provided...)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
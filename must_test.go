@@ -0,0 +1,41 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Must(t *testing.T) {
+	type (
+		in  string
+		out string
+	)
+
+	t.Run("should return the engine and the output", func(t *testing.T) {
+		t.Parallel()
+		ngn := MustInitialize(func(i in) out { return out(i) })
+		assert.Equal(t, out("<in>"), MustRunOne[out](context.Background(), ngn, in("<in>")))
+	})
+
+	t.Run("should panic if the engine can not be initialized", func(t *testing.T) {
+		t.Parallel()
+		defer func() {
+			err, _ := recover().(error)
+			assertErrContains(t, err, "engine must be initialized with at least one function")
+		}()
+		MustInitialize()
+	})
+
+	t.Run("should panic if the run fails", func(t *testing.T) {
+		t.Parallel()
+		ngn := MustInitialize(func(i in) (out, error) { return "", errors.New("<failed>") })
+		assert.PanicsWithError(t, "<failed>", func() {
+			MustRunOne[out](context.Background(), ngn, in("<in>"))
+		})
+	})
+}
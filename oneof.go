@@ -0,0 +1,117 @@
+package warp
+
+import (
+	"reflect"
+	"slices"
+)
+
+// OneOf is a parameter satisfied by a value of either of two types, so that a
+// function accepts alternative representations of its input, such as a value
+// loaded from a cache or fetched from a service, without being registered
+// twice.
+//
+//	func render(p warp.OneOf[CachedPage, FreshPage]) HTML
+//
+// The function runs once the functions producing A and B have returned, if
+// at least one of the values is available, and is skipped otherwise. OneOf
+// holds every available value: First is preferred when both are.
+type OneOf[A, B any] struct {
+	first    A
+	second   B
+	isFirst  bool
+	isSecond bool
+}
+
+// First returns the value of type A and whether it is available.
+func (o OneOf[A, B]) First() (A, bool) {
+	return o.first, o.isFirst
+}
+
+// Second returns the value of type B and whether it is available.
+func (o OneOf[A, B]) Second() (B, bool) {
+	return o.second, o.isSecond
+}
+
+func (o OneOf[A, B]) optionals() (first, second any) {
+	return Optional[A]{Val: o.first, IsSet: o.isFirst}, Optional[B]{Val: o.second, IsSet: o.isSecond}
+}
+
+func (o OneOf[A, B]) fromOptionals(first, second any) any {
+	a, b := first.(Optional[A]), second.(Optional[B])
+	return OneOf[A, B]{first: a.Val, second: b.Val, isFirst: a.IsSet, isSecond: b.IsSet}
+}
+
+type oneOf interface {
+	optionals() (first, second any)
+	fromOptionals(first, second any) any
+}
+
+var oneOfT = reflect.TypeOf((*oneOf)(nil)).Elem()
+
+// isOneOf reports whether t is a OneOf type.
+func isOneOf(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(oneOfT)
+}
+
+// expandOneOfs replaces the function of p, if it accepts OneOf parameters,
+// with a view function accepting an Optional parameter per alternative
+// instead. The positions of the alternatives of every OneOf are kept in
+// p.oneOf, for the function to be skipped when none is available.
+func expandOneOfs(p *Provider) {
+	fnV := reflect.ValueOf(p.fn)
+	if p.err != nil || fnV.Kind() != reflect.Func || fnV.IsNil() {
+		return
+	}
+	fnT := fnV.Type()
+
+	var (
+		isOneOfs = make([]bool, fnT.NumIn())
+		viewIns  []reflect.Type
+		zero     []bool
+		groups   [][]int
+	)
+	for i, inT := range inputs(fnT) {
+		if !isOneOf(inT) {
+			viewIns = append(viewIns, inT)
+			zero = append(zero, len(p.zero) > i && p.zero[i])
+			continue
+		}
+		isOneOfs[i] = true
+		first, second := reflect.Zero(inT).Interface().(oneOf).optionals()
+		groups = append(groups, []int{len(viewIns), len(viewIns) + 1})
+		viewIns = append(viewIns, reflect.TypeOf(first), reflect.TypeOf(second))
+		zero = append(zero, false, false)
+	}
+	if !slices.Contains(isOneOfs, true) {
+		return
+	}
+
+	p.name, p.location, p.zero, p.oneOf = p.ref(), p.locate(), zero, groups
+	viewT := reflect.FuncOf(viewIns, outputs(fnT), false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		callArgs := make([]reflect.Value, fnT.NumIn())
+		for i := range callArgs {
+			if !isOneOfs[i] {
+				callArgs[i], args = args[0], args[1:]
+				continue
+			}
+			v := reflect.Zero(fnT.In(i)).Interface().(oneOf).fromOptionals(args[0].Interface(), args[1].Interface())
+			callArgs[i], args = reflect.ValueOf(v), args[2:]
+		}
+		return fnV.Call(callArgs)
+	}).Interface()
+}
+
+// unavailable returns the types of the alternatives of the first OneOf
+// parameter of fn none of which is available in ins, nil if there is none.
+func (fn *function) unavailable(ins []reflect.Value) []reflect.Type {
+	for _, group := range fn.oneOf {
+		available := slices.ContainsFunc(group, func(i int) bool {
+			return ins[i].FieldByName("IsSet").Bool()
+		})
+		if !available {
+			return sliceConvert(func(i int) reflect.Type { return fn.ins[i].key }, group)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_OneOf(t *testing.T) {
+	type (
+		key       string
+		useCache  bool
+		cached    string
+		fresh     string
+		page      string
+		pageInput struct {
+			In
+
+			Key    key
+			Source OneOf[cached, fresh]
+		}
+	)
+	render := func(p OneOf[cached, fresh]) page {
+		if c, ok := p.First(); ok {
+			return page("cached " + c)
+		}
+		f, _ := p.Second()
+		return page("fresh " + f)
+	}
+	ngn, err := Initialize(
+		func(k key, c useCache) Optional[cached] {
+			return Optional[cached]{Val: cached(k), IsSet: bool(c)}
+		},
+		func(k key, c useCache) Optional[fresh] {
+			return Optional[fresh]{Val: fresh(k), IsSet: !bool(c)}
+		},
+		render,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should run with the available alternative", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[page](context.Background(), ngn, key("<k>"), useCache(true))
+		assert.NoError(t, err)
+		assert.Equal(t, page("cached <k>"), out)
+
+		out, err = Run[page](context.Background(), ngn, key("<k>"), useCache(false))
+		assert.NoError(t, err)
+		assert.Equal(t, page("fresh <k>"), out)
+	})
+
+	t.Run("should run with a provided alternative", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(render)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[page](context.Background(), ngn, fresh("<k>"))
+		assert.NoError(t, err)
+		assert.Equal(t, page("fresh <k>"), out)
+	})
+
+	t.Run("should skip the function if no alternative is available", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(k key) Optional[cached] { return Optional[cached]{} },
+			render,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[page](context.Background(), ngn, key("<k>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Zero(t, out)
+		assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+		assert.Equal(t, []SkippedFunction{{Name: report.Functions[1].Name, Missing: []reflect.Type{reflect.TypeOf(cached("")), reflect.TypeOf(fresh(""))}}}, report.Skipped())
+	})
+
+	t.Run("should accept OneOf fields of parameter structs", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(p pageInput) page {
+			c, _ := p.Source.First()
+			return page(string(p.Key) + " " + string(c))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[page](context.Background(), ngn, key("<k>"), cached("<c>"))
+		assert.NoError(t, err)
+		assert.Equal(t, page("<k> <c>"), out)
+	})
+}
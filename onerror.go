@@ -0,0 +1,153 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrorDecision is what a run does with the error returned by a function, see
+// OnError.
+type ErrorDecision int
+
+const (
+	// AbortRun fails the run with the error, as the FailurePolicy of the run
+	// dictates.
+	AbortRun ErrorDecision = iota
+	// RetryFunction calls the function again with the same inputs.
+	RetryFunction
+	// SkipOutputs continues the run without the outputs of the function, as
+	// for ErrSkip and BestEffort functions.
+	SkipOutputs
+)
+
+func (d ErrorDecision) String() string {
+	switch d {
+	case AbortRun:
+		return "abort run"
+	case RetryFunction:
+		return "retry function"
+	case SkipOutputs:
+		return "skip outputs"
+	}
+	return "unknown"
+}
+
+// FunctionError describes the error returned by a function, see OnError.
+type FunctionError struct {
+	// Name refers to the function, as in reports.
+	Name string
+	// Err is the error returned by the function.
+	Err error
+	// Attempt is the number of calls of the function in the run so far,
+	// starting at 1.
+	Attempt int
+	// Default is the decision taken without the handler: SkipOutputs for
+	// ErrSkip and BestEffort functions, AbortRun otherwise.
+	Default ErrorDecision
+}
+
+// OnError registers handler to decide what runs do with the errors returned
+// by their functions, unifying retries, tolerated failures and alerting in a
+// single place. The handler is called synchronously by the goroutine running
+// the function, every time the function returns an error, so it must be safe
+// for concurrent use; returning fe.Default keeps the default behaviour.
+//
+// The handler is not called for the errors caused by the cancellation of the
+// run, and the function is not retried once the run is cancelled. A function
+// is called up to 10 times in total, with no wait between its calls, unless
+// set otherwise with WithRetryLimit: once the limit is reached it fails with
+// the error of its last call, whatever the handler decides.
+func OnError(handler func(ctx context.Context, fe FunctionError) ErrorDecision) Option {
+	return func(c *config) {
+		c.onError = handler
+	}
+}
+
+// defaultRetryAttempts is the default maximum number of calls of a function
+// retried by the OnError handler, see WithRetryLimit.
+const defaultRetryAttempts = 10
+
+// WithRetryLimit bounds the calls of a function retried by the OnError handler
+// to attempts in total, 10 by default, waiting backoff before the second call
+// and doubling the wait before every further call. Retries stop when the run
+// is cancelled.
+func WithRetryLimit(attempts int, backoff time.Duration) Option {
+	return func(c *config) {
+		c.retry = retryPolicy{attempts: max(attempts, 1), backoff: backoff}
+	}
+}
+
+// retryPolicy bounds the retries decided by the OnError handler.
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// attempt calls fn with ins, retrying it as long as the error handler decides
+// so, within the retry limit of the run, and returns the outputs of the last
+// call with the decision on its error. The faults injected into the run fail
+// or delay the calls, see WithFaults.
+func (rs *runState) attempt(ctx context.Context, fn *function, ins []reflect.Value, errPos int) ([]reflect.Value, ErrorDecision, error) {
+	backoff := rs.retry.backoff
+	for attempt := 1; ; attempt++ {
+		var outValues []reflect.Value
+		err := rs.faults.inject(ctx, fn)
+		if err == nil {
+			outValues = rs.call(fn, ins)
+			err = getError(outValues, errPos)
+		} else {
+			outValues = zeroOutputs(fn)
+		}
+		if err == nil {
+			return outValues, AbortRun, nil
+		}
+		if decision := rs.decide(ctx, fn, err, attempt); decision != RetryFunction {
+			return outValues, decision, err
+		}
+		if attempt >= rs.retry.attempts {
+			return outValues, AbortRun, fmt.Errorf("failed after %d attempts: %w", attempt, err)
+		}
+		if !rs.clock.sleep(ctx, backoff) {
+			return outValues, AbortRun, fmt.Errorf("%w: not retried: %w", ctx.Err(), err)
+		}
+		backoff *= 2
+	}
+}
+
+// decide returns the decision on the error returned by fn.
+func (rs *runState) decide(ctx context.Context, fn *function, err error, attempt int) ErrorDecision {
+	if ctx.Err() != nil {
+		return AbortRun
+	}
+	fe := FunctionError{Name: fn.name, Err: err, Attempt: attempt}
+	if errors.Is(err, ErrSkip) || fn.bestEffort {
+		fe.Default = SkipOutputs
+	}
+	if rs.onError == nil {
+		return fe.Default
+	}
+	return rs.onError(ctx, fe)
+}
+
+// failed handles the error returned by fn according to decision and returns
+// the error failing the run, nil if the run continues.
+func (rs *runState) failed(ctx context.Context, fn *function, err error, decision ErrorDecision) error {
+	switch {
+	case decision == SkipOutputs && errors.Is(err, ErrSkip):
+		rs.resolve(fn)
+		rs.record(fn, StatusSkipped, err)
+		return nil
+	case decision == SkipOutputs && rs.budget.exceeded():
+		err = fmt.Errorf("more than %d tolerated failures: %w", rs.budget.max, err)
+	case decision == SkipOutputs:
+		rs.resolve(fn)
+		rs.record(fn, StatusFailed, err)
+		rs.report.recordTolerated(fn)
+		return nil
+	}
+	rs.recordError(ctx, fn, err)
+	return err
+}
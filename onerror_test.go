@@ -0,0 +1,164 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_OnError(t *testing.T) {
+	type (
+		in  string
+		a   string
+		out string
+	)
+
+	t.Run("should retry the function", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			func(i in) (a, error) {
+				if calls.Add(1) < 3 {
+					return "", errors.New("<flaky>")
+				}
+				return a(i), nil
+			},
+			func(v a) out { return out(v) },
+			OnError(func(_ context.Context, fe FunctionError) ErrorDecision {
+				if fe.Attempt < 3 {
+					return RetryFunction
+				}
+				return fe.Default
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Run[out](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+		assert.Equal(t, out("<in>"), res)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("should skip the outputs of the function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) (a, error) { return "", errors.New("<error>") },
+			func(v Optional[a]) out { return out("set: " + map[bool]string{true: "yes", false: "no"}[v.IsSet]) },
+			OnError(func(context.Context, FunctionError) ErrorDecision { return SkipOutputs }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		res, err := Run[out](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, out("set: no"), res)
+		assert.True(t, report.Functions[0].Tolerated)
+	})
+
+	t.Run("should abort the run and pass the default decision", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mu   sync.Mutex
+			seen []FunctionError
+		)
+		ngn, err := Initialize(
+			BestEffort(func(i in) (a, error) { return "", errors.New("<error>") }),
+			func(v Optional[a]) out { return out(v.Val) },
+			OnError(func(_ context.Context, fe FunctionError) ErrorDecision {
+				mu.Lock()
+				defer mu.Unlock()
+				seen = append(seen, fe)
+				return AbortRun
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[out](context.Background(), ngn, in("<in>"))
+		assertErr(t, err, "<error>")
+		if assert.Len(t, seen, 1) {
+			assert.Equal(t, SkipOutputs, seen[0].Default)
+			assert.Equal(t, 1, seen[0].Attempt)
+			assert.Contains(t, seen[0].Name, "Test_OnError")
+		}
+	})
+
+	t.Run("should not call the handler for cancellations", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			func(ctx context.Context, i in) (a, error) { return "", ctx.Err() },
+			func(v a) out { return out(v) },
+			OnError(func(context.Context, FunctionError) ErrorDecision {
+				calls.Add(1)
+				return RetryFunction
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = Run[out](ctx, ngn, in("<in>"))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("should stop retrying the function at the retry limit", func(t *testing.T) {
+		t.Parallel()
+		retry := OnError(func(context.Context, FunctionError) ErrorDecision { return RetryFunction })
+		for name, tc := range map[string]struct {
+			opts  []any
+			calls int32
+		}{
+			"by default": {calls: 10},
+			"when set":   {opts: []any{WithRetryLimit(3, 0)}, calls: 3},
+		} {
+			var calls atomic.Int32
+			ngn, err := Initialize(append([]any{
+				func(i in) (a, error) { calls.Add(1); return "", errors.New("<flaky>") },
+				retry,
+			}, tc.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Run[a](context.Background(), ngn, in("<in>"))
+			assertErrContains(t, err, "failed after")
+			assertErrContains(t, err, "<flaky>")
+			assert.Equal(t, tc.calls, calls.Load(), name)
+		}
+	})
+
+	t.Run("should stop waiting to retry the function once the run is cancelled", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			func(i in) (a, error) { calls.Add(1); return "", errors.New("<flaky>") },
+			OnError(func(context.Context, FunctionError) ErrorDecision { return RetryFunction }),
+			WithRetryLimit(5, time.Hour),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = Run[a](ctx, ngn, in("<in>"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -1,15 +1,22 @@
 package warp
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"reflect"
 )
 
 // Optional is a wrapper for an optional parameter in the function run by
-// engine.
+// engine. Types derived from an Optional, such as type MaybeUser
+// Optional[User], are handled as optionals too, though they do not have its
+// methods.
 type Optional[T any] struct {
-	Val   T
-	IsSet bool
+	Val T
+	// The tag tells the types derived from an Optional apart from the other
+	// structs with Val and IsSet fields, see isOptional
+	IsSet bool `warp:"optional"`
 }
 
 func (o Optional[T]) isOptional() {}
@@ -20,14 +27,98 @@ func (o Optional[T]) Value() (T, bool) {
 	return o.Val, o.IsSet
 }
 
+// Equal reports whether o and other are both unset, or both set to equal
+// values. Values are compared with their own Equal method if they have one,
+// such as time.Time, and with reflect.DeepEqual otherwise. cmp.Equal uses
+// Equal to compare Optional values, see also warptest.EquateOptionals.
+func (o Optional[T]) Equal(other Optional[T]) bool {
+	if !o.IsSet || !other.IsSet {
+		return o.IsSet == other.IsSet
+	}
+	if eq, ok := any(o.Val).(interface{ Equal(T) bool }); ok {
+		return eq.Equal(other.Val)
+	}
+	return reflect.DeepEqual(o.Val, other.Val)
+}
+
+// MarshalJSON encodes the value if it is set, null otherwise.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.IsSet {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Val)
+}
+
+// UnmarshalJSON decodes null as an unset value, and any other JSON value as a
+// set value.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Optional[T]{}
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Optional[T]{Val: v, IsSet: true}
+	return nil
+}
+
+// MarshalText encodes the value if it is set, with its own MarshalText method
+// if it has one, as is if it is a string and as JSON otherwise, such as a
+// number. An unset value is encoded as an empty text.
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	if !o.IsSet {
+		return []byte{}, nil
+	}
+	if m, ok := any(o.Val).(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	if v := reflect.ValueOf(o.Val); v.Kind() == reflect.String {
+		return []byte(v.String()), nil
+	}
+	return json.Marshal(o.Val)
+}
+
+// UnmarshalText decodes an empty text as an unset value, and any other text as
+// a set value, decoded as MarshalText encodes it.
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*o = Optional[T]{}
+		return nil
+	}
+	var v T
+	if u, ok := any(&v).(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText(text); err != nil {
+			return err
+		}
+	} else if rv := reflect.ValueOf(&v).Elem(); rv.Kind() == reflect.String {
+		rv.SetString(string(text))
+	} else if err := json.Unmarshal(text, &v); err != nil {
+		return err
+	}
+	*o = Optional[T]{Val: v, IsSet: true}
+	return nil
+}
+
 type optional interface {
 	isOptional()
 }
 
-// isOptional returns true if the type is an explicit Optional type.
-// Custom types derived from Optional[T] are not supported.
+// isOptional returns true if the type is an Optional type, or a type derived
+// from one, such as type MaybeUser Optional[User]. A derived type does not
+// have the methods of Optional, so it is recognized by its fields, the Val
+// field followed by the IsSet field tagged as in Optional.
 func isOptional(t reflect.Type) bool {
-	return t.Implements(reflect.TypeOf((*optional)(nil)).Elem())
+	if t.Implements(reflect.TypeOf((*optional)(nil)).Elem()) {
+		return true
+	}
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	isSet := t.Field(1)
+	return t.Field(0).Name == "Val" && isSet.Name == "IsSet" &&
+		isSet.Type == reflect.TypeOf(false) && isSet.Tag == `warp:"optional"`
 }
 
 // unwrapOptional returns the type of the value wrapped by an Optional[T]. If the value
@@ -0,0 +1,239 @@
+package warp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_OptionalJSON(t *testing.T) {
+	type user struct {
+		Name  Optional[string] `json:"name"`
+		Age   Optional[int]    `json:"age"`
+		Email Optional[string] `json:"email"`
+	}
+
+	t.Run("should encode the unset values as null", func(t *testing.T) {
+		t.Parallel()
+		data, err := json.Marshal(user{Name: Optional[string]{Val: "<name>", IsSet: true}, Age: Optional[int]{Val: 42, IsSet: true}})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name": "<name>", "age": 42, "email": null}`, string(data))
+	})
+
+	t.Run("should decode null and missing values as unset", func(t *testing.T) {
+		t.Parallel()
+		var u user
+		err := json.Unmarshal([]byte(`{"name": "<name>", "age": null}`), &u)
+		assert.NoError(t, err)
+		assert.Equal(t, user{Name: Optional[string]{Val: "<name>", IsSet: true}}, u)
+	})
+
+	t.Run("should fail to decode a value of the wrong type", func(t *testing.T) {
+		t.Parallel()
+		var u user
+		err := json.Unmarshal([]byte(`{"age": "<age>"}`), &u)
+		assertErrContains(t, err, "cannot unmarshal string into Go value of type int")
+	})
+}
+
+func Test_OptionalText(t *testing.T) {
+	t.Run("should encode the set values as text", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			in   any
+			text string
+		}{
+			{Optional[string]{Val: "<s>", IsSet: true}, "<s>"},
+			{Optional[int]{Val: 42, IsSet: true}, "42"},
+			{Optional[netip.Addr]{Val: netip.MustParseAddr("10.0.0.1"), IsSet: true}, "10.0.0.1"},
+			{Optional[int]{}, ""},
+		} {
+			text, err := tc.in.(interface{ MarshalText() ([]byte, error) }).MarshalText()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.text, string(text))
+		}
+	})
+
+	t.Run("should decode the text as a set value", func(t *testing.T) {
+		t.Parallel()
+		var (
+			s    Optional[string]
+			i    Optional[int]
+			addr Optional[netip.Addr]
+		)
+		assert.NoError(t, s.UnmarshalText([]byte("<s>")))
+		assert.NoError(t, i.UnmarshalText([]byte("42")))
+		assert.NoError(t, addr.UnmarshalText([]byte("10.0.0.1")))
+		assert.Equal(t, Optional[string]{Val: "<s>", IsSet: true}, s)
+		assert.Equal(t, Optional[int]{Val: 42, IsSet: true}, i)
+		assert.Equal(t, Optional[netip.Addr]{Val: netip.MustParseAddr("10.0.0.1"), IsSet: true}, addr)
+
+		assert.NoError(t, i.UnmarshalText(nil))
+		assert.Equal(t, Optional[int]{}, i)
+	})
+
+	t.Run("should fail to decode an invalid text", func(t *testing.T) {
+		t.Parallel()
+		var i Optional[int]
+		assert.Error(t, i.UnmarshalText([]byte("<i>")))
+	})
+}
+
+func Test_OptionalEqual(t *testing.T) {
+	type quote struct {
+		Price     int
+		FetchedAt time.Time
+	}
+	now := time.Now()
+
+	t.Run("should compare the optionals", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			a, b  Optional[quote]
+			equal bool
+		}{
+			{Optional[quote]{}, Optional[quote]{}, true},
+			{Optional[quote]{Val: quote{Price: 1}}, Optional[quote]{}, true},
+			{Optional[quote]{Val: quote{Price: 1}, IsSet: true}, Optional[quote]{Val: quote{Price: 1}, IsSet: true}, true},
+			{Optional[quote]{Val: quote{Price: 1}, IsSet: true}, Optional[quote]{Val: quote{Price: 2}, IsSet: true}, false},
+			{Optional[quote]{IsSet: true}, Optional[quote]{}, false},
+		} {
+			assert.Equal(t, tc.equal, tc.a.Equal(tc.b))
+		}
+	})
+
+	t.Run("should compare the values with their Equal method", func(t *testing.T) {
+		t.Parallel()
+		a := Optional[time.Time]{Val: now, IsSet: true}
+		b := Optional[time.Time]{Val: now.In(time.FixedZone("<zone>", 3600)), IsSet: true}
+		assert.True(t, a.Equal(b))
+	})
+}
+
+func Test_DerivedOptional(t *testing.T) {
+	type (
+		id        string
+		user      string
+		maybeUser Optional[user]
+		greeting  string
+		profile   string
+	)
+	ngn, err := Initialize(
+		func(i id) maybeUser {
+			if i == "" {
+				return maybeUser{}
+			}
+			return maybeUser{Val: user("user " + i), IsSet: true}
+		},
+		func(u maybeUser) greeting {
+			if !u.IsSet {
+				return "hello stranger"
+			}
+			return greeting("hello " + u.Val)
+		},
+		func(u user) profile { return profile("profile of " + u) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should handle a derived optional output as an optional", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[profile](context.Background(), ngn, id("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, profile("profile of user <id>"), out)
+
+		out, err = Run[profile](context.Background(), ngn, id(""))
+		assert.NoError(t, err)
+		assert.Zero(t, out)
+	})
+
+	t.Run("should handle a derived optional input as an optional", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[greeting](context.Background(), ngn, id("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello user <id>"), out)
+
+		out, err = Run[greeting](context.Background(), ngn, id(""))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello stranger"), out)
+	})
+}
+
+func Test_MismatchedOptionals(t *testing.T) {
+	type (
+		id        string
+		user      string
+		maybeUser Optional[user]
+		greeting  string
+		lookalike struct {
+			Val   user
+			IsSet bool
+		}
+	)
+	greet := func(u user, isSet bool) greeting {
+		if !isSet {
+			return "hello stranger"
+		}
+		return greeting("hello " + u)
+	}
+
+	t.Run("should pass an Optional output to a derived optional input", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i id) Optional[user] { return Optional[user]{user(i), i != ""} },
+			func(u maybeUser) greeting { return greet(u.Val, u.IsSet) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[greeting](context.Background(), ngn, id("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello <id>"), out)
+
+		out, err = Run[greeting](context.Background(), ngn, id(""))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello stranger"), out)
+	})
+
+	t.Run("should pass a derived optional output to an Optional input", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i id) maybeUser { return maybeUser{user(i), i != ""} },
+			func(u Optional[user]) greeting { return greet(u.Val, u.IsSet) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[greeting](context.Background(), ngn, id("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello <id>"), out)
+
+		out, err = Run[greeting](context.Background(), ngn, id(""))
+		assert.NoError(t, err)
+		assert.Equal(t, greeting("hello stranger"), out)
+	})
+
+	t.Run("should not handle a struct with Val and IsSet fields as an optional", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i id) lookalike { return lookalike{Val: user(i)} },
+			func(i id) user { return user(i) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[lookalike](context.Background(), ngn, id("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, lookalike{Val: "<id>"}, out)
+	})
+}
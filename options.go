@@ -0,0 +1,148 @@
+package warp
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Option configures an Engine. Options are passed to Initialize alongside the
+// functions.
+type Option func(*config)
+
+// config holds the engine wide settings applied by Options.
+type config struct {
+	coldLogger        *slog.Logger
+	coldInterval      time.Duration
+	immutabilityCheck bool
+	deepCopy          bool
+	mutationAudit     *mutationAudit
+	redactions        []*Redaction
+	mode              RunMode
+	maxInvokeDepth    int
+	listeners         []EventListener
+	shed              *shedder
+	latencies         map[string]LatencyEstimate
+	deadlineLogger    *slog.Logger
+	codecs            codecs
+	interfaceBinding  bool
+	cooperative       *cooperation
+	onError           func(context.Context, FunctionError) ErrorDecision
+	retry             retryPolicy
+	defaults          map[reflect.Type]reflect.Value
+	watchdog          time.Duration
+	clock             clock
+	flags             FlagSource
+	cpuPool, ioPool   int
+	stopHooks         []func(context.Context) error
+}
+
+// Options combines opts into a single Option, so that a set of options, such
+// as the defaults of an application, can be shared by several engines. The
+// options are applied in order.
+func Options(opts ...Option) Option {
+	return func(c *config) {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(c)
+			}
+		}
+	}
+}
+
+// splitOptions separates the Options and Redactions from the functions passed
+// to Initialize and applies them to a new config.
+func splitOptions(args []any) ([]any, *config) {
+	var (
+		fns = make([]any, 0, len(args))
+		cfg = &config{maxInvokeDepth: defaultMaxInvokeDepth, retry: retryPolicy{attempts: defaultRetryAttempts}}
+	)
+	for _, arg := range args {
+		if opt, ok := arg.(Option); ok {
+			if opt != nil {
+				opt(cfg)
+			}
+			continue
+		}
+		if r, ok := arg.(*Redaction); ok {
+			cfg.redactions = append(cfg.redactions, r)
+			continue
+		}
+		fns = append(fns, arg)
+	}
+	return fns, cfg
+}
+
+// RunOption configures a single run. Run options are passed to Run alongside
+// the provided inputs.
+type RunOption func(*runConfig)
+
+// runConfig holds the settings of a single run applied by RunOptions.
+type runConfig struct {
+	report *Report
+	mode   *RunMode
+	handle *RunHandle
+	// requireTarget is set by WithRequireTarget
+	requireTarget bool
+	// maxFailures is set by WithMaxFailures
+	maxFailures *int
+	checkpoint  *Checkpoint
+	// restored holds the values of the checkpoint of a resumed run
+	restored map[reflect.Type]reflect.Value
+	// invocations is the chain of enclosing runs of a nested run
+	invocations []invocation
+	// progress is set by WithProgress
+	progress func(Progress)
+	// runID and metadata are set by WithRunID and WithMetadata
+	runID    string
+	metadata map[string]string
+	faults   faults
+	// teardown is set by WithTeardown
+	teardown bool
+	// shortCircuit is set by WithShortCircuit
+	shortCircuit bool
+	conversion   Conversion
+	// collect is set by WithCollect
+	collect bool
+	// disabled holds the types disabled by WithDisabled
+	disabled []reflect.Type
+}
+
+// RunOptions combines opts into a single RunOption, so that a set of run
+// options can be shared by several runs. The options are applied in order.
+func RunOptions(opts ...RunOption) RunOption {
+	return func(c *runConfig) {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(c)
+			}
+		}
+	}
+}
+
+// splitRunOptions separates the RunOptions from the inputs provided to a run
+// and applies them to a new runConfig.
+func splitRunOptions(args []any) ([]any, *runConfig) {
+	cfg := &runConfig{}
+	if !slices.ContainsFunc(args, isRunOption) {
+		return args, cfg
+	}
+	provided := make([]any, 0, len(args))
+	for _, arg := range args {
+		if opt, ok := arg.(RunOption); ok {
+			if opt != nil {
+				opt(cfg)
+			}
+			continue
+		}
+		provided = append(provided, arg)
+	}
+	return provided, cfg
+}
+
+func isRunOption(arg any) bool {
+	_, ok := arg.(RunOption)
+	return ok
+}
@@ -0,0 +1,46 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Options(t *testing.T) {
+	type (
+		in  string
+		out string
+	)
+
+	t.Run("should apply the combined options in order", func(t *testing.T) {
+		t.Parallel()
+		defaults := Options(Default(in("<first>")), nil, Default(in("<default>")))
+		ngn, err := Initialize(func(i in) out { return out(i) }, defaults)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Run[out](context.Background(), ngn)
+		assert.NoError(t, err)
+		assert.Equal(t, out("<default>"), res)
+	})
+
+	t.Run("should apply the combined run options", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(i in) out { return out(i) })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		var progress []Progress
+		opts := RunOptions(WithReport(&report), nil, WithProgress(func(p Progress) { progress = append(progress, p) }))
+		_, err = Run[out](context.Background(), ngn, in("<in>"), opts)
+		assert.NoError(t, err)
+		assert.Len(t, report.Functions, 1)
+		assert.Len(t, progress, 2)
+	})
+}
@@ -0,0 +1,117 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Out is embedded in a struct to make it a result struct: a function
+// returning a result struct produces each of its exported fields as if it
+// were a separate output of the function, so constructors of several values
+// need no long return tuples.
+//
+//	type Clients struct {
+//		warp.Out
+//
+//		Users    *UsersClient
+//		Payments *PaymentsClient
+//		Audit    warp.Optional[*AuditClient]
+//		conn     *grpc.ClientConn
+//	}
+//
+//	func NewClients(cfg Config) (Clients, error)
+//
+// The fields follow the rules of the outputs, two fields can not have the
+// same type: an Optional field is produced if set. A field tagged `warp:"-"`
+// is not produced, nor are the unexported fields.
+type Out struct{}
+
+var outStructT = reflect.TypeOf(Out{})
+
+// isResultStruct reports whether t is a struct embedding Out.
+func isResultStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous && f.Type == outStructT {
+			return true
+		}
+	}
+	return false
+}
+
+// resultFields returns the indexes of the fields of the result struct t
+// produced as outputs.
+func resultFields(t reflect.Type) ([]int, error) {
+	var out []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if (f.Anonymous && f.Type == outStructT) || !f.IsExported() {
+			continue
+		}
+		switch tag := f.Tag.Get("warp"); tag {
+		case "":
+		case "-":
+			continue
+		default:
+			return nil, fmt.Errorf("result struct %s field %s has unknown tag %q", t, f.Name, tag)
+		}
+		if !isValueType(f.Type) {
+			return nil, fmt.Errorf("result struct %s field %s can not be of type %s", t, f.Name, f.Type)
+		}
+		out = append(out, i)
+	}
+	return out, nil
+}
+
+// expandResults replaces the function of p, if it returns result structs,
+// with a view function returning their fields instead.
+func expandResults(p *Provider) {
+	fnV := reflect.ValueOf(p.fn)
+	if p.err != nil || fnV.Kind() != reflect.Func || fnV.IsNil() {
+		return
+	}
+	fnT := fnV.Type()
+
+	var (
+		structs  = make([][]int, fnT.NumOut())
+		isStruct = make([]bool, fnT.NumOut())
+		viewOuts []reflect.Type
+	)
+	for i, outT := range outputs(fnT) {
+		if !isResultStruct(outT) {
+			viewOuts = append(viewOuts, outT)
+			continue
+		}
+		fields, err := resultFields(outT)
+		if err != nil {
+			p.err = fmt.Errorf("function %s: %w", p.ref(), err)
+			return
+		}
+		structs[i], isStruct[i] = fields, true
+		for _, f := range fields {
+			viewOuts = append(viewOuts, outT.Field(f).Type)
+		}
+	}
+	if !slices.Contains(isStruct, true) {
+		return
+	}
+
+	p.name, p.location = p.ref(), p.locate()
+	viewT := reflect.FuncOf(inputs(fnT), viewOuts, false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		results := make([]reflect.Value, 0, len(viewOuts))
+		for i, v := range fnV.Call(args) {
+			if !isStruct[i] {
+				results = append(results, v)
+				continue
+			}
+			for _, f := range structs[i] {
+				results = append(results, v.Field(f))
+			}
+		}
+		return results
+	}).Interface()
+}
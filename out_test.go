@@ -0,0 +1,104 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Out(t *testing.T) {
+	type (
+		dsn      string
+		users    string
+		payments string
+		audit    string
+		internal string
+		page     string
+	)
+	type clients struct {
+		Out
+
+		Users    users
+		Payments payments
+		Audit    Optional[audit]
+		Internal internal `warp:"-"`
+		conn     dsn
+	}
+
+	ngn, err := Initialize(
+		func(d dsn) (clients, error) {
+			if d == "<bad>" {
+				return clients{}, errors.New("bad dsn")
+			}
+			return clients{Users: users("users@" + d), Payments: payments("payments@" + d), conn: d}, nil
+		},
+		func(u users, p payments, a Optional[audit], i Optional[internal]) page {
+			return page(string(u) + " " + string(p) + " " + string(a.Val) + string(i.Val))
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should produce the fields as outputs", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[page](context.Background(), ngn, dsn("<dsn>"))
+		assert.NoError(t, err)
+		assert.Equal(t, page("users@<dsn> payments@<dsn> "), out)
+	})
+
+	t.Run("should run to a single field", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[payments](context.Background(), ngn, dsn("<dsn>"))
+		assert.NoError(t, err)
+		assert.Equal(t, payments("payments@<dsn>"), out)
+	})
+
+	t.Run("should not produce the excluded fields", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[internal](context.Background(), ngn, dsn("<dsn>"))
+		assertErr(t, err, "output type warp_test.internal does not match any provided input types")
+	})
+
+	t.Run("should return the error of the function", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run[page](context.Background(), ngn, dsn("<bad>"))
+		assertErrContains(t, err, "bad dsn")
+	})
+
+	t.Run("should fail with an unknown tag", func(t *testing.T) {
+		t.Parallel()
+		type badClients struct {
+			Out
+
+			Users users `warp:"group"`
+		}
+		_, err := Initialize(func(d dsn) badClients { return badClients{} })
+		assertErrContains(t, err, `field Users has unknown tag "group"`)
+	})
+
+	t.Run("should fail with an error field", func(t *testing.T) {
+		t.Parallel()
+		type badClients struct {
+			Out
+
+			Users users
+			Err   error
+		}
+		_, err := Initialize(func(d dsn) badClients { return badClients{} })
+		assertErrContains(t, err, "field Err can not be of type error")
+	})
+
+	t.Run("should fail with fields of a type already produced", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(d dsn) clients { return clients{} },
+			func(d dsn) users { return "" },
+		)
+		assert.Error(t, err)
+	})
+}
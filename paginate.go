@@ -0,0 +1,208 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Pages streams the pages fetched by a paginated function, see Paginate. Any
+// number of consumers may read the same Pages concurrently, each of them
+// receives every page in order.
+type Pages[P any] struct {
+	s *pageStream[P]
+}
+
+// Each calls fn for every page, as soon as it is fetched, until all pages have
+// been fetched. It returns the error of fn, the error that stopped the
+// pagination, or ctx.Err() if ctx is done first.
+func (p Pages[P]) Each(ctx context.Context, fn func(P) error) error {
+	if p.s == nil {
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		page, ok, err := p.s.get(ctx, i)
+		if err != nil || !ok {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+}
+
+// Collect waits for all pages to be fetched and returns them.
+func (p Pages[P]) Collect(ctx context.Context) ([]P, error) {
+	var out []P
+	err := p.Each(ctx, func(page P) error {
+		out = append(out, page)
+		return nil
+	})
+	return out, err
+}
+
+// await blocks until the pagination has finished and returns its error. The
+// engine awaits Pages outputs so that a run only finishes once every page has
+// been fetched, and fails if fetching a page fails.
+func (p Pages[P]) await(ctx context.Context) error {
+	if p.s == nil {
+		return nil
+	}
+	_, _, err := p.s.get(ctx, -1)
+	return err
+}
+
+// awaiter is implemented by outputs that are still being produced after the
+// function returning them has returned.
+type awaiter interface {
+	await(ctx context.Context) error
+}
+
+// pageStream holds the pages fetched so far. changed is closed and replaced
+// every time the stream changes to wake up the readers.
+type pageStream[P any] struct {
+	mu      sync.Mutex
+	pages   []P
+	done    bool
+	err     error
+	changed chan struct{}
+}
+
+func (s *pageStream[P]) push(page P) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages = append(s.pages, page)
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *pageStream[P]) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done, s.err = true, err
+	close(s.changed)
+}
+
+// get waits for the page at index i. ok is false once the stream is done and
+// has no page at i. A negative i waits for the stream to be done.
+func (s *pageStream[P]) get(ctx context.Context, i int) (page P, ok bool, err error) {
+	for {
+		s.mu.Lock()
+		if i >= 0 && i < len(s.pages) {
+			page = s.pages[i]
+			s.mu.Unlock()
+			return page, true, nil
+		}
+		if s.done {
+			s.mu.Unlock()
+			return page, false, s.err
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return page, false, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Paginate registers a paginated function fetching pages of type P with a
+// cursor of type C. fn must accept an Optional[C] parameter holding the cursor
+// of the page to fetch, which is unset for the first page, and must return
+// (P, Optional[C]) or (P, Optional[C], error) where the returned cursor points
+// to the next page.
+//
+// The engine calls fn again and again, passing it the cursor it returned,
+// until the returned cursor is unset. Its other parameters are resolved once,
+// as for any function. The function outputs Pages[P]: downstream functions
+// taking a Pages[P] parameter start as soon as the first page is requested and
+// receive the pages as they are fetched. The run fails if fetching a page
+// fails.
+func Paginate[P, C any](fn any) *Provider {
+	var (
+		pageT   = reflect.TypeOf((*P)(nil)).Elem()
+		cursorT = reflect.TypeOf(Optional[C]{})
+		pagesT  = reflect.TypeOf(Pages[P]{})
+		fnV     = reflect.ValueOf(fn)
+	)
+
+	p := &Provider{fn: fn}
+	if fn == nil || fnV.Kind() != reflect.Func {
+		p.err = errors.New("paginated input must be a function")
+		return p
+	}
+	p.name, p.location = referTo(fnV), locate(fnV)
+
+	fnT := fnV.Type()
+	outs := outputs(fnT)
+	if (len(outs) != 2 && len(outs) != 3) || outs[0] != pageT || outs[1] != cursorT || (len(outs) == 3 && !isType[error](outs[2])) {
+		p.err = fmt.Errorf("paginated function %s must return (%s, %s) or (%s, %s, error)", p.name, pageT, cursorT, pageT, cursorT)
+		return p
+	}
+	cursorPos := getPosOfType[Optional[C]](inputs(fnT))
+	if cursorPos == -1 {
+		p.err = fmt.Errorf("paginated function %s must accept a %s parameter", p.name, cursorT)
+		return p
+	}
+	ctxPos := getPosOfType[context.Context](inputs(fnT))
+
+	// The engine sees a function taking a context and the other parameters of
+	// fn and returning Pages[P]
+	viewIns := []reflect.Type{reflect.TypeOf((*context.Context)(nil)).Elem()}
+	for i, inT := range inputs(fnT) {
+		if i != cursorPos && i != ctxPos {
+			viewIns = append(viewIns, inT)
+		}
+	}
+	view := reflect.FuncOf(viewIns, []reflect.Type{pagesT}, false)
+
+	p.fn = reflect.MakeFunc(view, func(args []reflect.Value) []reflect.Value {
+		ctx := args[0].Interface().(context.Context)
+		s := &pageStream[P]{changed: make(chan struct{})}
+
+		go func() {
+			var cursor Optional[C]
+			for {
+				if err := ctx.Err(); err != nil {
+					s.finish(err)
+					return
+				}
+
+				callArgs := make([]reflect.Value, 0, fnT.NumIn())
+				next := 1
+				for i := 0; i < fnT.NumIn(); i++ {
+					switch i {
+					case cursorPos:
+						callArgs = append(callArgs, reflect.ValueOf(cursor))
+					case ctxPos:
+						callArgs = append(callArgs, args[0])
+					default:
+						callArgs = append(callArgs, args[next])
+						next++
+					}
+				}
+
+				outValues := fnV.Call(callArgs)
+				if err := getError(outValues, getPosOfType[error](outs)); err != nil {
+					s.finish(err)
+					return
+				}
+				s.push(outValues[0].Interface().(P))
+
+				if cursor = outValues[1].Interface().(Optional[C]); !cursor.IsSet {
+					s.finish(nil)
+					return
+				}
+			}
+		}()
+
+		return []reflect.Value{reflect.ValueOf(Pages[P]{s})}
+	}).Interface()
+
+	return p
+}
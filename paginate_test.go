@@ -0,0 +1,109 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Paginate(t *testing.T) {
+	type (
+		query  string
+		page   []int
+		cursor int
+		total  int
+	)
+
+	fetch := func(_ context.Context, q query, c Optional[cursor]) (page, Optional[cursor], error) {
+		at, _ := c.Value()
+		if q == "<fail>" && at == 1 {
+			return nil, Optional[cursor]{}, errors.New("<fetch-error>")
+		}
+		p := page{int(at) * 2, int(at)*2 + 1}
+		if at == 2 {
+			return p, Optional[cursor]{}, nil
+		}
+		return p, Optional[cursor]{Val: at + 1, IsSet: true}, nil
+	}
+
+	t.Run("should stream every page to downstream functions", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Paginate[page, cursor](fetch),
+			func(ctx context.Context, pages Pages[page]) (total, error) {
+				var sum total
+				err := pages.Each(ctx, func(p page) error {
+					for _, v := range p {
+						sum += total(v)
+					}
+					return nil
+				})
+				return sum, err
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[total](context.Background(), ngn, query("<q>"))
+		assert.NoError(t, err)
+		assert.Equal(t, total(15), out)
+	})
+
+	t.Run("should return all fetched pages when pages are the target", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Paginate[page, cursor](func(c Optional[cursor], q query) (page, Optional[cursor]) {
+				at, _ := c.Value()
+				if at == 1 {
+					return page{1}, Optional[cursor]{}
+				}
+				return page{0}, Optional[cursor]{Val: 1, IsSet: true}
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[Pages[page]](context.Background(), ngn, query("<q>"))
+		assert.NoError(t, err)
+		pages, err := out.Collect(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []page{{0}, {1}}, pages)
+	})
+
+	t.Run("should fail the run if fetching a page fails", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Paginate[page, cursor](fetch),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[Pages[page]](context.Background(), ngn, query("<fail>"))
+		assertErr(t, err, "<fetch-error>")
+	})
+
+	t.Run("should return an error if the function does not return a page and a cursor", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			Paginate[page, cursor](func(Optional[cursor]) page { return nil }),
+		)
+
+		assertErrContains(t, err, "must return (warp_test.page, warp.Optional[")
+	})
+
+	t.Run("should return an error if the function does not accept a cursor", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			Paginate[page, cursor](func(query) (page, Optional[cursor]) { return nil, Optional[cursor]{} }),
+		)
+
+		assertErrContains(t, err, "must accept a warp.Optional[")
+	})
+}
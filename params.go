@@ -0,0 +1,130 @@
+package warp
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// In is embedded in a struct to make it a parameter struct: a function
+// accepting a parameter struct receives its exported fields as if they were
+// parameters of the function, so functions with many inputs stay readable.
+//
+//	type ServerParams struct {
+//		warp.In
+//
+//		Config  Config
+//		Logger  *slog.Logger
+//		Metrics *Metrics `warp:"optional"`
+//	}
+//
+//	func NewServer(p ServerParams) *Server
+//
+// A field tagged `warp:"optional"` is an optional input: it is left to its
+// zero value instead of skipping the function when no value is available, as
+// an Optional field would be left unset. The fields follow the rules of the
+// parameters, two fields can not have the same type.
+type In struct{}
+
+var inStructT = reflect.TypeOf(In{})
+
+// paramField is a field of a parameter struct received as a parameter.
+type paramField struct {
+	// index is the index of the field in the struct.
+	index int
+	// optional is true if the field is tagged `warp:"optional"` and is not
+	// an Optional already.
+	optional bool
+}
+
+// isParamStruct reports whether t is a struct embedding In.
+func isParamStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous && f.Type == inStructT {
+			return true
+		}
+	}
+	return false
+}
+
+// paramFields returns the fields of the parameter struct t received as
+// parameters.
+func paramFields(t reflect.Type) ([]paramField, error) {
+	var out []paramField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type == inStructT {
+			continue
+		}
+		if !f.IsExported() {
+			return nil, fmt.Errorf("parameter struct %s field %s must be exported", t, f.Name)
+		}
+		field := paramField{index: i}
+		switch tag := f.Tag.Get("warp"); tag {
+		case "":
+		case "optional":
+			field.optional = !isOptional(f.Type)
+		default:
+			return nil, fmt.Errorf("parameter struct %s field %s has unknown tag %q", t, f.Name, tag)
+		}
+		out = append(out, field)
+	}
+	return out, nil
+}
+
+// expandParams replaces the function of p, if it accepts parameter structs,
+// with a view function accepting their fields instead.
+func expandParams(p *Provider) {
+	fnV := reflect.ValueOf(p.fn)
+	if p.err != nil || fnV.Kind() != reflect.Func || fnV.IsNil() {
+		return
+	}
+	fnT := fnV.Type()
+
+	var (
+		structs  = make([][]paramField, fnT.NumIn())
+		isStruct = make([]bool, fnT.NumIn())
+		viewIns  []reflect.Type
+		zero     []bool
+	)
+	for i, inT := range inputs(fnT) {
+		if !isParamStruct(inT) {
+			viewIns, zero = append(viewIns, inT), append(zero, false)
+			continue
+		}
+		fields, err := paramFields(inT)
+		if err != nil {
+			p.err = fmt.Errorf("function %s: %w", p.ref(), err)
+			return
+		}
+		structs[i], isStruct[i] = fields, true
+		for _, f := range fields {
+			viewIns, zero = append(viewIns, inT.Field(f.index).Type), append(zero, f.optional)
+		}
+	}
+	if !slices.Contains(isStruct, true) {
+		return
+	}
+
+	p.name, p.location, p.zero = p.ref(), p.locate(), zero
+	viewT := reflect.FuncOf(viewIns, outputs(fnT), false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		callArgs := make([]reflect.Value, fnT.NumIn())
+		for i := range callArgs {
+			if !isStruct[i] {
+				callArgs[i], args = args[0], args[1:]
+				continue
+			}
+			s := reflect.New(fnT.In(i)).Elem()
+			for _, f := range structs[i] {
+				s.Field(f.index).Set(args[0])
+				args = args[1:]
+			}
+			callArgs[i] = s
+		}
+		return fnV.Call(callArgs)
+	}).Interface()
+}
@@ -0,0 +1,117 @@
+package warp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_In(t *testing.T) {
+	type (
+		host    string
+		port    int
+		token   string
+		region  string
+		address string
+	)
+	type params struct {
+		In
+
+		Host   host
+		Port   port
+		Token  token `warp:"optional"`
+		Region Optional[region]
+	}
+
+	ngn, err := Initialize(
+		func(ctx context.Context, p params) address {
+			return address(fmt.Sprintf("%s:%d?token=%s&region=%s&set=%t", p.Host, p.Port, p.Token, p.Region.Val, p.Region.IsSet))
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should receive the fields as parameters", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[address](context.Background(), ngn, host("<host>"), port(80), token("<token>"), region("<region>"))
+		assert.NoError(t, err)
+		assert.Equal(t, address("<host>:80?token=<token>&region=<region>&set=true"), out)
+	})
+
+	t.Run("should leave the missing optional fields to their zero value", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[address](context.Background(), ngn, host("<host>"), port(80))
+		assert.NoError(t, err)
+		assert.Equal(t, address("<host>:80?token=&region=&set=false"), out)
+	})
+
+	t.Run("should skip the function if a required field is missing", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		out, err := Run[address](context.Background(), ngn, host("<host>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, address(""), out)
+		assert.Equal(t, StatusSkipped, report.Functions[0].Status)
+	})
+
+	t.Run("should wire the fields to the functions producing them", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(h host) port { return port(len(h)) },
+			func(p params) address { return address(fmt.Sprintf("%s:%d", p.Host, p.Port)) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Run[address](context.Background(), ngn, host("<host>"))
+		assert.NoError(t, err)
+		assert.Equal(t, address("<host>:6"), out)
+	})
+
+	t.Run("should keep the name of the function", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		_, err := Run[address](context.Background(), ngn, host("<host>"), port(80), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Contains(t, report.Functions[0].Name, "Test_In")
+	})
+
+	t.Run("should fail with an unexported field", func(t *testing.T) {
+		t.Parallel()
+		type badParams struct {
+			In
+
+			host host
+		}
+		_, err := Initialize(func(p badParams) address { return address(p.host) })
+		assertErrContains(t, err, "field host must be exported")
+	})
+
+	t.Run("should fail with an unknown tag", func(t *testing.T) {
+		t.Parallel()
+		type badParams struct {
+			In
+
+			Host host `warp:"required"`
+		}
+		_, err := Initialize(func(p badParams) address { return address(p.Host) })
+		assertErrContains(t, err, `field Host has unknown tag "required"`)
+	})
+
+	t.Run("should fail with fields of the same type", func(t *testing.T) {
+		t.Parallel()
+		type badParams struct {
+			In
+
+			Primary   host
+			Secondary host
+		}
+		_, err := Initialize(func(p badParams) address { return address(p.Primary + p.Secondary) })
+		assertErrContains(t, err, "Test_In.func9.1(warp_test.badParams) warp_test.address caused validation error: function takes the same parameter type warp_test.host more than once")
+	})
+}
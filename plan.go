@@ -0,0 +1,160 @@
+package warp
+
+import (
+	"context"
+	"reflect"
+)
+
+// inputPlan is the precomputed wiring of a function parameter.
+type inputPlan struct {
+	// typ is the declared parameter type.
+	typ reflect.Type
+	// key is the storage key of the parameter value: typ without any
+	// Optional wrapper.
+	key reflect.Type
+	// optional is true if typ is an Optional.
+	optional bool
+	// zero is true if the parameter receives the zero value of typ when no
+	// value is available, as an optional field of a parameter struct.
+	zero bool
+	// context is true if the parameter receives the run context.
+	context bool
+	// invoker is true if the parameter receives the Invoker of the run.
+	invoker bool
+	// lazy is true if typ is a Lazy, key is then the type of its value.
+	lazy bool
+	// signal is the index of key in the schedule, -1 if no function produces
+	// it.
+	signal int
+	// slot is the index of key in the storage of a run, -1 if it has none,
+	// see valueIndex.
+	slot int
+	// def is the default value of key registered with Default, invalid if
+	// it has none.
+	def reflect.Value
+}
+
+// injected reports whether the parameter is filled by the engine rather than
+// by a value.
+func (in inputPlan) injected() bool {
+	return in.context || in.invoker
+}
+
+// outputPlan is the precomputed wiring of a function value result.
+type outputPlan struct {
+	// pos is the position of the result.
+	pos int
+	// key is the storage key of the result value.
+	key reflect.Type
+	// signal is the index of key in the schedule, and its slot in the
+	// storage of a run.
+	signal int
+}
+
+// valuePlan returns the plan of a parameter of type t, wired to no slot.
+func valuePlan(t reflect.Type) inputPlan {
+	return inputPlan{typ: t, key: t, signal: -1, slot: -1}
+}
+
+// planInputs precomputes the wiring of the parameters of a function. Signals
+// are assigned by compilePlan once all functions are known.
+func planInputs(inputs []reflect.Type) []inputPlan {
+	out := make([]inputPlan, len(inputs))
+	for i, inT := range inputs {
+		key, optional := unwrapOptional(inT)
+		key, lazy := unwrapLazy(key)
+		out[i] = inputPlan{
+			typ:      inT,
+			key:      key,
+			optional: optional,
+			context:  isType[context.Context](inT),
+			invoker:  isType[Invoker](inT),
+			lazy:     lazy,
+			signal:   -1,
+			slot:     -1,
+		}
+	}
+	return out
+}
+
+// planOutputs precomputes the wiring of the value results of a function.
+func planOutputs(outputs []reflect.Type) []outputPlan {
+	out := make([]outputPlan, 0, len(outputs))
+	for i, outT := range outputs {
+		if isValueType(outT) {
+			key, _ := unwrapOptional(outT)
+			out = append(out, outputPlan{pos: i, key: key, signal: -1})
+		}
+	}
+	return out
+}
+
+// compilePlan assigns a signal index to every produced type and wires the
+// function parameters to the signals of the types they consume. It returns
+// the number of signals and the index of the storage slots: the produced
+// types have their signal as slot, the types only consumed the following
+// slots.
+func compilePlan(funcs []*function) (int, *valueIndex) {
+	index := &valueIndex{slots: map[reflect.Type]int{}}
+	for _, fn := range funcs {
+		for i, out := range fn.outs {
+			index.slots[out.key] = len(index.types)
+			index.types = append(index.types, out.key)
+			fn.outs[i].signal = index.slots[out.key]
+		}
+	}
+	numSignals := len(index.types)
+
+	for _, fn := range funcs {
+		for i, in := range fn.ins {
+			if in.injected() {
+				continue
+			}
+			slot, ok := index.slots[in.key]
+			if !ok {
+				slot = len(index.types)
+				index.slots[in.key] = slot
+				index.types = append(index.types, in.key)
+			}
+			fn.ins[i].slot = slot
+			if slot < numSignals {
+				fn.ins[i].signal = slot
+			}
+		}
+	}
+
+	return numSignals, index
+}
+
+// sortFunctions returns funcs sorted in dependency order: every function comes
+// after the functions producing its inputs. Independent functions keep their
+// registration order.
+func sortFunctions(funcs []*function, producers map[reflect.Type]*function) []*function {
+	var (
+		out     = make([]*function, 0, len(funcs))
+		visited = make(map[*function]bool, len(funcs))
+		inSet   = make(map[*function]bool, len(funcs))
+	)
+	for _, fn := range funcs {
+		inSet[fn] = true
+	}
+
+	var visit func(fn *function)
+	visit = func(fn *function) {
+		if visited[fn] {
+			return
+		}
+		visited[fn] = true
+		for _, in := range fn.ins {
+			if producer, ok := producers[in.key]; ok && inSet[producer] {
+				visit(producer)
+			}
+		}
+		out = append(out, fn)
+	}
+	for _, fn := range funcs {
+		visit(fn)
+	}
+
+	return out
+}
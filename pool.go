@@ -0,0 +1,86 @@
+package warp
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runSlots holds the scheduling state of the functions of a run, one slot
+// per function of its schedule, and the scratch space of its reachability,
+// see reachable.
+type runSlots struct {
+	pending  []atomic.Int32
+	started  []atomic.Bool
+	resolved []atomic.Bool
+	status   []Status
+	errs     []error
+	// args holds, per function, the arguments it is called with
+	args [][]reflect.Value
+	// launched holds, per function, when it was launched
+	launched []time.Time
+	queue    readyQueue
+	// work is the work method of the runState owning the slots
+	work func() error
+	reachability
+}
+
+func newRunSlots(s *schedule, index *valueIndex) *runSlots {
+	n := len(s.funcs)
+	return &runSlots{
+		pending:      make([]atomic.Int32, n),
+		started:      make([]atomic.Bool, n),
+		resolved:     make([]atomic.Bool, n),
+		status:       make([]Status, n),
+		errs:         make([]error, n),
+		args:         make([][]reflect.Value, n),
+		launched:     make([]time.Time, n),
+		queue:        readyQueue{items: make([]int, 0, n)},
+		reachability: newReachability(s, index),
+	}
+}
+
+// clear resets the slots for the next run.
+func (slots *runSlots) clear() {
+	for i := range slots.pending {
+		slots.pending[i].Store(0)
+		slots.started[i].Store(false)
+		slots.resolved[i].Store(false)
+		clear(slots.args[i][:cap(slots.args[i])])
+	}
+	clear(slots.status)
+	clear(slots.errs)
+	slots.queue.clear()
+}
+
+// statePool pools the state of the runs of a schedule, its storage and its
+// slots included, so that the runs of a warm engine do not allocate them.
+type statePool struct {
+	pool sync.Pool
+}
+
+// get returns a cleared runState for a run of s storing its values by index.
+func (p *statePool) get(s *schedule, index *valueIndex) *runState {
+	if rs, ok := p.pool.Get().(*runState); ok {
+		return rs
+	}
+	rs := &runState{storage: newValueStore(index), pooled: newRunSlots(s, index)}
+	// The runState is reset in place, see release: work stays bound to it
+	rs.pooled.work = rs.work
+	return rs
+}
+
+// release returns rs to the pool of the schedule s once the run is over. The
+// state of a run with Lazy parameters is never reused, as a Lazy value can
+// outlive its run.
+func (rs *runState) release(s *schedule) {
+	if s.lazy {
+		return
+	}
+	storage, slots := rs.storage, rs.pooled
+	storage.clear()
+	slots.clear()
+	*rs = runState{storage: storage, pooled: slots}
+	s.states.pool.Put(rs)
+}
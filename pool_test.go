@@ -0,0 +1,104 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunStateReuse(t *testing.T) {
+	type (
+		in     int
+		half   int
+		double int
+		sum    int
+	)
+
+	errOdd := errors.New("odd input")
+	ngn, err := Initialize(
+		func(i in) (half, error) {
+			if i%2 == 1 {
+				return 0, errOdd
+			}
+			return half(i / 2), nil
+		},
+		func(i in) double { return double(i * 2) },
+		func(h half, d double) sum { return sum(int(h) + int(d)) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("does not leak the state of a run into the next ones", func(t *testing.T) {
+		t.Parallel()
+		for i := range 6 {
+			var report Report
+			out, err := Run[sum](context.Background(), ngn, in(i), WithRunMode(RunMode{Failure: FailAggregate}), WithReport(&report))
+			if i%2 == 1 {
+				assert.ErrorIs(t, err, errOdd)
+				assert.Equal(t, StatusFailed, report.Functions[0].Status)
+				assert.Equal(t, StatusNotStarted, report.Functions[2].Status)
+				continue
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, sum(i/2+i*2), out)
+			for _, fr := range report.Functions {
+				assert.Equal(t, StatusSucceeded, fr.Status, fr.Name)
+			}
+		}
+	})
+
+	t.Run("runs concurrently", func(t *testing.T) {
+		t.Parallel()
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out, err := Run[sum](context.Background(), ngn, in(2*i))
+				assert.NoError(t, err)
+				assert.Equal(t, sum(i+4*i), out)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func Test_RunAllocations(t *testing.T) {
+	type (
+		in   int
+		out1 int
+		out2 int
+		out3 int
+		out4 int
+	)
+
+	ngn, err := Initialize(
+		func(i in) out1 { return out1(i) },
+		func(o out1) out2 { return out2(o) },
+		func(o out1, i Optional[in]) out3 { return out3(o) },
+		func(ctx context.Context, o2 out2, o3 out3) (out4, error) { return out4(o2) + out4(o3), nil },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not parallel: AllocsPerRun counts the allocations of all the goroutines
+	if raceEnabled {
+		t.Skip("the race detector makes sync.Pool drop pooled run states")
+	}
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := Run[out4](ctx, ngn, in(1)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// The goroutine, call and outputs of each function, and the contexts of
+	// the run, but neither its storage nor its scheduling state
+	assert.LessOrEqual(t, allocs, float64(30))
+}
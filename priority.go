@@ -0,0 +1,180 @@
+package warp
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Prioritized sets the scheduling priority of fn. When the number of
+// functions of a run executing at once is limited, by the Sequential
+// execution policy or by WithCooperativeExecution, the ready functions with
+// the highest priority are started first, so the functions on the critical
+// path of the run do not queue behind side branches. Functions have a
+// priority of zero by default, and functions of equal priority start by
+// critical path, see Estimated, then in the order they became ready.
+// Priorities have no effect on unlimited runs.
+func Prioritized(fn any, priority int) *Provider {
+	p := asProvider(fn)
+	p.priority = priority
+	return p
+}
+
+// Estimated sets the estimated duration of fn. When the number of functions of
+// a run executing at once is limited, the ready functions of equal priority
+// are started by the length of their critical path: the longest sum of the
+// estimated durations along the functions depending on them, their own
+// included. Starting the heads of the longest chains first shortens the
+// duration of the whole run. Functions are estimated to take no time by
+// default.
+func Estimated(fn any, cost time.Duration) *Provider {
+	p := asProvider(fn)
+	if cost < 0 {
+		p.err = errors.New("estimated duration must not be negative")
+		return p
+	}
+	p.cost = cost
+	return p
+}
+
+// precedence orders the functions waiting for a slot of a gate: by priority,
+// then by critical path.
+type precedence struct {
+	priority int
+	path     time.Duration
+}
+
+// resumed is the precedence of a function taking back its execution slot
+// after waiting for a lazy input: it already started, so it goes first.
+var resumed = precedence{priority: math.MaxInt}
+
+// precedence returns the precedence of the function at position i.
+func (s *schedule) precedence(i int) precedence {
+	return precedence{priority: s.funcs[i].priority, path: s.paths[i]}
+}
+
+// criticalPaths returns, per function, the longest sum of the estimated
+// durations of the functions along a chain of consumers starting with it.
+func (s *schedule) criticalPaths() []time.Duration {
+	paths := make([]time.Duration, len(s.funcs))
+	for k := len(s.order) - 1; k >= 0; k-- {
+		i := s.order[k]
+		var longest time.Duration
+		for _, out := range s.funcs[i].outs {
+			for _, c := range s.consumers[out.signal] {
+				longest = max(longest, paths[c])
+			}
+		}
+		paths[i] = s.funcs[i].cost + longest
+	}
+	return paths
+}
+
+// gate bounds the number of functions of a run executing at once, handing
+// the free slots to the waiting functions by precedence. A nil gate is
+// unbounded.
+type gate struct {
+	mu      sync.Mutex
+	free    int
+	waiting waiters
+	seq     uint64
+}
+
+func newGate(slots int) *gate {
+	return &gate{free: slots}
+}
+
+// acquire waits for a free slot, which is handed to the waiting function of
+// the highest precedence first. It gives up once ctx is done, returning its
+// error.
+func (g *gate) acquire(ctx context.Context, p precedence) error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	if g.free > 0 && len(g.waiting) == 0 {
+		g.free--
+		g.mu.Unlock()
+		return nil
+	}
+	w := &waiter{precedence: p, seq: g.seq, ready: make(chan struct{})}
+	g.seq++
+	heap.Push(&g.waiting, w)
+	g.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	g.mu.Lock()
+	select {
+	case <-w.ready:
+		// The slot was handed over meanwhile
+		g.mu.Unlock()
+		g.release()
+	default:
+		heap.Remove(&g.waiting, w.index)
+		g.mu.Unlock()
+	}
+	return ctx.Err()
+}
+
+// release frees the slot held by the calling function.
+func (g *gate) release() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.waiting) == 0 {
+		g.free++
+		return
+	}
+	close(heap.Pop(&g.waiting).(*waiter).ready)
+}
+
+// waiter is a function waiting for a slot of a gate.
+type waiter struct {
+	precedence
+	seq   uint64
+	ready chan struct{}
+	// index is the position of the waiter in the heap
+	index int
+}
+
+// waiters is a heap of waiters, the highest priority first, then the longest
+// critical path, then the earliest.
+type waiters []*waiter
+
+func (w waiters) Len() int { return len(w) }
+
+func (w waiters) Less(i, j int) bool {
+	if w[i].priority != w[j].priority {
+		return w[i].priority > w[j].priority
+	}
+	if w[i].path != w[j].path {
+		return w[i].path > w[j].path
+	}
+	return w[i].seq < w[j].seq
+}
+
+func (w waiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index, w[j].index = i, j
+}
+
+func (w *waiters) Push(x any) {
+	x.(*waiter).index = len(*w)
+	*w = append(*w, x.(*waiter))
+}
+
+func (w *waiters) Pop() any {
+	old := *w
+	x := old[len(old)-1]
+	*w = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,127 @@
+package warp_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Prioritized(t *testing.T) {
+	type (
+		in  int
+		a   int
+		b   int
+		c   int
+		d   int
+		out int
+	)
+
+	// startOrder runs four functions of different priorities under a limit of
+	// one function at a time and returns the order in which they started.
+	// Each function holds its slot long enough for the others to wait for it.
+	startOrder := func(t *testing.T, opt Option, runOpts ...any) []string {
+		var mu sync.Mutex
+		var started []string
+		work := func(name string) {
+			mu.Lock()
+			started = append(started, name)
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+		}
+		ngn, err := Initialize(
+			Prioritized(func(i in) a { work("a"); return a(i) }, 1),
+			Prioritized(func(i in) b { work("b"); return b(i) }, 3),
+			Prioritized(func(i in) c { work("c"); return c(i) }, 2),
+			func(i in) d { work("d"); return d(i) },
+			func(a a, b b, c c, d d) out { return out(int(a) + int(b) + int(c) + int(d)) },
+			opt,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Run[out](context.Background(), ngn, append([]any{in(1)}, runOpts...)...)
+		assert.NoError(t, err)
+		assert.Equal(t, out(4), res)
+		return started
+	}
+	// assertByPriority asserts that the functions started by priority, except
+	// the first one, which started before the others were ready to wait
+	assertByPriority := func(t *testing.T, started []string) {
+		if assert.Len(t, started, 4) {
+			expected := slices.DeleteFunc([]string{"b", "c", "a", "d"}, func(name string) bool { return name == started[0] })
+			assert.Equal(t, expected, started[1:])
+		}
+	}
+
+	t.Run("should start the ready functions by priority under cooperative execution", func(t *testing.T) {
+		t.Parallel()
+		assertByPriority(t, startOrder(t, WithCooperativeExecution(1)))
+	})
+
+	t.Run("should start the ready functions by priority under the Sequential execution policy", func(t *testing.T) {
+		t.Parallel()
+		assertByPriority(t, startOrder(t, nil, WithRunMode(RunMode{Execution: Sequential})))
+	})
+}
+
+func Test_Estimated(t *testing.T) {
+	type (
+		in  int
+		a   int
+		b   int
+		c   int
+		d   int
+		out int
+	)
+
+	t.Run("should start the ready functions by critical path", func(t *testing.T) {
+		t.Parallel()
+		var mu sync.Mutex
+		var started []string
+		work := func(name string) {
+			mu.Lock()
+			started = append(started, name)
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+		}
+		ngn, err := Initialize(
+			Estimated(func(i in) a { work("short"); return a(i) }, time.Millisecond),
+			Estimated(func(i in) b { work("head"); return b(i) }, 10*time.Millisecond),
+			Estimated(func(b b) c { work("tail"); return c(b) }, 50*time.Millisecond),
+			Estimated(func(i in) d { work("mid"); return d(i) }, 30*time.Millisecond),
+			func(a a, c c, d d) out { return out(int(a) + int(c) + int(d)) },
+			WithCooperativeExecution(1),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Run[out](context.Background(), ngn, in(1))
+		assert.NoError(t, err)
+		assert.Equal(t, out(3), res)
+
+		// the first function starts before the others are ready to wait, and
+		// the tail only becomes ready once the head returns
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, started, 4)
+		started = slices.DeleteFunc(started, func(name string) bool { return name == "tail" })
+		if assert.Len(t, started, 3) {
+			expected := slices.DeleteFunc([]string{"head", "mid", "short"}, func(name string) bool { return name == started[0] })
+			assert.Equal(t, expected, started[1:])
+		}
+	})
+
+	t.Run("should reject a negative estimated duration", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Estimated(func(in) a { return 0 }, -time.Second))
+		assertErr(t, err, "input validation error: estimated duration must not be negative")
+	})
+}
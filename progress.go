@@ -0,0 +1,73 @@
+package warp
+
+import "sync"
+
+// Progress is the progress of a run.
+type Progress struct {
+	// Completed is the number of functions of the run that have returned,
+	// been skipped or been cancelled.
+	Completed int
+	// Total is the number of functions the run can call. It grows when a
+	// Lazy parameter forces a deferred function to run.
+	Total int
+}
+
+// WithProgress calls report with the progress of the run: once before any
+// function runs, then every time a function completes. The calls are
+// serialized, in order of completion, by the goroutine of the function that
+// completed, so report must be fast. Functions skipped before the run starts,
+// because their inputs can not be produced, are not counted.
+//
+// To deliver the progress to a channel, send to the channel from report
+// without blocking the run, or drain the channel in another goroutine.
+func WithProgress(report func(Progress)) RunOption {
+	return func(c *runConfig) {
+		c.progress = report
+	}
+}
+
+// progress tracks the progress of a run. A nil progress tracks nothing.
+type progress struct {
+	mu     sync.Mutex
+	report func(Progress)
+	state  Progress
+}
+
+func newProgress(report func(Progress)) *progress {
+	if report == nil {
+		return nil
+	}
+	return &progress{report: report}
+}
+
+// start reports the number of functions the run can call.
+func (p *progress) start(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Total = total
+	p.report(p.state)
+}
+
+// add counts a function forced to run.
+func (p *progress) add() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Total++
+}
+
+// complete counts a completed function.
+func (p *progress) complete() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Completed++
+	p.report(p.state)
+}
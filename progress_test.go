@@ -0,0 +1,65 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithProgress(t *testing.T) {
+	type (
+		in      string
+		missing string
+		a       string
+		b       string
+		c       string
+		out     string
+	)
+
+	ngn, err := Initialize(
+		func(i in) a { return a(i) },
+		func(i in) b { return b(i) },
+		func(m missing) c { return c(m) },
+		func(a a, b b, c Optional[c]) out { return out(a) + out(b) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should report the functions completed out of the runnable ones", func(t *testing.T) {
+		t.Parallel()
+		var events []Progress
+		_, err := Run[out](context.Background(), ngn, in("<in>"), WithProgress(func(p Progress) {
+			events = append(events, p)
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, []Progress{
+			{Completed: 0, Total: 3},
+			{Completed: 1, Total: 3},
+			{Completed: 2, Total: 3},
+			{Completed: 3, Total: 3},
+		}, events)
+	})
+
+	t.Run("should count the deferred functions once forced", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) a { return a(i) },
+			func(ctx context.Context, l Lazy[a]) (out, error) {
+				v, err := l.Get(ctx)
+				return out(v), err
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var last Progress
+		_, err = Run[out](context.Background(), ngn, in("<in>"), WithProgress(func(p Progress) { last = p }))
+		assert.NoError(t, err)
+		assert.Equal(t, Progress{Completed: 2, Total: 2}, last)
+	})
+}
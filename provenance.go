@@ -0,0 +1,113 @@
+package warp
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Provenance tells where a value of a run came from: the function that
+// produced it and, recursively, the values that function was called with.
+type Provenance struct {
+	// Type is the unwrapped type of the value.
+	Type reflect.Type
+	// Value is the value, after redaction.
+	Value any
+	// Producer refers to the function that produced the value, as in
+	// validation errors. It is empty for a value the run started with, see
+	// Report.Provided.
+	Producer string
+	// Inputs holds the provenance of the values the producer was called
+	// with, in the order of its parameters, see FunctionReport.Fed.
+	Inputs []*Provenance
+}
+
+// Provenance returns the provenance of the value of type t of the run, nil if
+// the run neither started with nor produced one. Values shared by several
+// functions have a single Provenance, reachable from each of them.
+func (r Report) Provenance(t reflect.Type) *Provenance {
+	t, _ = unwrapOptional(t)
+	return r.provenance(t, map[reflect.Type]*Provenance{})
+}
+
+func (r Report) provenance(t reflect.Type, seen map[reflect.Type]*Provenance) *Provenance {
+	if p, ok := seen[t]; ok {
+		return p
+	}
+	if v, ok := r.Provided[t]; ok {
+		seen[t] = &Provenance{Type: t, Value: v}
+		return seen[t]
+	}
+	for _, fr := range r.Functions {
+		i := slices.Index(fr.Outputs, t)
+		if i == -1 || fr.Values == nil || slices.Contains(fr.Unset, t) {
+			continue
+		}
+		p := &Provenance{Type: t, Value: fr.Values[i], Producer: fr.Name}
+		seen[t] = p
+		for _, inT := range fr.Fed {
+			if in := r.provenance(inT, seen); in != nil {
+				p.Inputs = append(p.Inputs, in)
+			}
+		}
+		return p
+	}
+	return nil
+}
+
+// Provenance returns the provenance of the value of type t of the run, see
+// Report.Provenance.
+func (r RunResult[T]) Provenance(t reflect.Type) *Provenance {
+	return r.Report.Provenance(t)
+}
+
+// fed returns the types of the values stored in storage that the inputs ins
+// of fn were loaded from.
+func fed(fn *function, storage *valueStore) []reflect.Type {
+	var out []reflect.Type
+	for _, in := range fn.ins {
+		if in.injected() || in.lazy {
+			continue
+		}
+		v, ok := storage.load(in)
+		if !ok {
+			continue
+		}
+		if isOptional(v.Type()) && !v.FieldByName("IsSet").Bool() {
+			continue
+		}
+		out = append(out, in.key)
+	}
+	return out
+}
+
+// recordFed records the types of the values fn is called with. A nil report
+// records nothing.
+func (r *runReport) recordFed(fn *function, storage *valueStore) {
+	if r == nil {
+		return
+	}
+	types := fed(fn, storage)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Fed = types
+	}
+}
+
+// recordProvided records the values the run starts with, stored in storage.
+// A nil report records nothing.
+func (r *runReport) recordProvided(storage *valueStore) {
+	if r == nil {
+		return
+	}
+	provided := map[reflect.Type]any{}
+	storage.Range(func(key reflect.Type, v reflect.Value) bool {
+		if !isOptional(v.Type()) || v.FieldByName("IsSet").Bool() {
+			provided[key] = r.redactions.redact(&function{}, v)
+		}
+		return true
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.provided = provided
+}
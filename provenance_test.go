@@ -0,0 +1,87 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Provenance(t *testing.T) {
+	type (
+		token    string
+		userID   int
+		user     string
+		coupon   string
+		discount int
+		quote    string
+	)
+
+	ngn, err := Initialize(
+		func(t token) userID { return 42 },
+		func(id userID) user { return "alice" },
+		func(u user, c Optional[coupon]) discount {
+			if c.IsSet {
+				return 10
+			}
+			return 0
+		},
+		func(u user, d discount) quote { return "quote" },
+		Redact[token](),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("traces a value back to the provided inputs", func(t *testing.T) {
+		t.Parallel()
+		result := RunDetailed[quote](context.Background(), ngn, token("secret"))
+		assert.NoError(t, result.Err)
+
+		p := result.Provenance(reflect.TypeFor[quote]())
+		if !assert.NotNil(t, p) {
+			return
+		}
+		assert.Equal(t, quote("quote"), p.Value)
+		assert.Contains(t, p.Producer, "Test_Provenance")
+		if !assert.Len(t, p.Inputs, 2) {
+			return
+		}
+		u, d := p.Inputs[0], p.Inputs[1]
+		assert.Equal(t, user("alice"), u.Value)
+		assert.Equal(t, discount(0), d.Value)
+		assert.Same(t, u, d.Inputs[0], "values shared by several functions have a single provenance")
+		assert.Len(t, d.Inputs, 1, "unset Optional inputs are left out")
+
+		id := u.Inputs[0]
+		assert.Equal(t, userID(42), id.Value)
+		assert.Equal(t, &Provenance{Type: reflect.TypeFor[token](), Value: "[REDACTED]"}, id.Inputs[0])
+	})
+
+	t.Run("lists the Optional inputs that are set", func(t *testing.T) {
+		t.Parallel()
+		result := RunDetailed[quote](context.Background(), ngn, token("secret"), coupon("SAVE10"))
+		assert.NoError(t, result.Err)
+
+		p := result.Report.Provenance(reflect.TypeFor[Optional[discount]]())
+		if !assert.NotNil(t, p) {
+			return
+		}
+		assert.Equal(t, discount(10), p.Value)
+		assert.Equal(t, []reflect.Type{reflect.TypeFor[user](), reflect.TypeFor[coupon]()}, result.Report.Functions[2].Fed)
+		assert.Equal(t, &Provenance{Type: reflect.TypeFor[coupon](), Value: coupon("SAVE10")}, p.Inputs[1])
+	})
+
+	t.Run("returns nil for a value the run did not produce", func(t *testing.T) {
+		t.Parallel()
+		result := RunDetailed[quote](context.Background(), ngn, coupon("SAVE10"))
+		assert.NoError(t, result.Err)
+		assert.Nil(t, result.Provenance(reflect.TypeFor[token]()))
+		assert.Nil(t, result.Provenance(reflect.TypeFor[quote]()))
+		assert.Equal(t, &Provenance{Type: reflect.TypeFor[coupon](), Value: coupon("SAVE10")}, result.Provenance(reflect.TypeFor[coupon]()))
+		assert.Nil(t, result.Report.Functions[0].Fed)
+	})
+}
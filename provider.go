@@ -0,0 +1,81 @@
+package warp
+
+import (
+	"reflect"
+	"time"
+)
+
+// Provider is a function registered with the engine together with options
+// that change how the engine runs it. A Provider is passed to Initialize in
+// place of the plain function.
+//
+// Providers are built by wrapping a function, or another Provider, with one of
+// the package level helpers such as Singleton.
+type Provider struct {
+	fn         any
+	name       string
+	location   string
+	err        error
+	singleton  bool
+	sink       bool
+	resilient  bool
+	shadowed   bool
+	bestEffort bool
+	priority   int
+	cost       time.Duration
+	flag       string
+	workload   workload
+	limit      int
+	tags       []string
+	redactions []*Redaction
+	// zero holds, per parameter of fn, whether it receives its zero value
+	// when no value is available, see In.
+	zero []bool
+	// oneOf holds the positions of the parameters of fn standing for the
+	// alternatives of every OneOf parameter.
+	oneOf [][]int
+}
+
+// asProvider returns a copy of fn if it is a *Provider, otherwise it wraps fn
+// in a new Provider with the default options.
+func asProvider(fn any) *Provider {
+	if p, ok := fn.(*Provider); ok && p != nil {
+		cp := *p
+		cp.tags = append([]string(nil), p.tags...)
+		cp.redactions = append([]*Redaction(nil), p.redactions...)
+		return &cp
+	}
+	return &Provider{fn: fn}
+}
+
+func asProviders(fns []any) []*Provider {
+	out := make([]*Provider, len(fns))
+	for i, fn := range fns {
+		out[i] = asProvider(fn)
+	}
+	return out
+}
+
+// ref returns the name used to refer to the provider function.
+func (p *Provider) ref() string {
+	if p.name != "" {
+		return p.name
+	}
+	return referTo(reflect.ValueOf(p.fn))
+}
+
+// locate returns the source location of the provider function.
+func (p *Provider) locate() string {
+	if p.location != "" {
+		return p.location
+	}
+	return locate(reflect.ValueOf(p.fn))
+}
+
+func providerFuncs(ps []*Provider) []any {
+	out := make([]any, len(ps))
+	for i, p := range ps {
+		out[i] = p.fn
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Race registers candidates, functions of the same type returning an error,
+// as a single function producing their outputs: every call calls all of them
+// at once, with the same inputs, and returns the outputs of the first one
+// succeeding, so that a lookup served by several regions or backends is as
+// fast as the fastest of them.
+//
+// The context of the candidates, passed to those accepting one, is cancelled
+// once a candidate has succeeded, and the function returns without waiting
+// for the other candidates. If every candidate fails, the function fails
+// with their errors joined. Candidates can not return a Cleanup, as the
+// Cleanup of a losing candidate would not be called.
+//
+// The function keeps the name of the first candidate prefixed with "race".
+func Race(candidates ...any) *Provider {
+	p := &Provider{}
+	if len(candidates) == 0 {
+		p.err = errors.New("race must have at least one candidate")
+		return p
+	}
+	p.fn = candidates[0]
+	fns := make([]reflect.Value, len(candidates))
+	for i, c := range candidates {
+		fns[i] = reflect.ValueOf(c)
+		if c == nil || fns[i].Kind() != reflect.Func {
+			p.err = errors.New("race candidates must be functions")
+			return p
+		}
+	}
+	p.name, p.location = "race "+referTo(fns[0]), locate(fns[0])
+
+	fnT := fns[0].Type()
+	outs := outputs(fnT)
+	errPos := getPosOfType[error](outs)
+	switch {
+	case errPos == -1:
+		p.err = errors.New("race candidates must return an error")
+		return p
+	case getPosOfType[Cleanup](outs) != -1:
+		p.err = errors.New("race candidates can not return a Cleanup")
+		return p
+	}
+	for _, fnV := range fns[1:] {
+		if fnV.Type() != fnT {
+			p.err = fmt.Errorf("race candidates must have the same type, %s is not %s", fnV.Type(), fnT)
+			return p
+		}
+	}
+
+	// The view function accepts the run context, in first position unless
+	// the candidates already accept one
+	ins, ctxPos := inputs(fnT), getPosOfType[context.Context](inputs(fnT))
+	if ctxPos == -1 {
+		ins = append([]reflect.Type{reflect.TypeOf((*context.Context)(nil)).Elem()}, ins...)
+	}
+	viewT := reflect.FuncOf(ins, outs, false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		ctx := args[max(ctxPos, 0)].Interface().(context.Context)
+		if ctxPos == -1 {
+			args = args[1:]
+		}
+		return race(ctx, fns, args, ctxPos, errPos)
+	}).Interface()
+	return p
+}
+
+// race calls fns at once with args and returns the outputs of the first one
+// succeeding. The candidates accepting a context get args with ctxPos set to
+// a context cancelled once race returns.
+func race(ctx context.Context, fns, args []reflect.Value, ctxPos, errPos int) []reflect.Value {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if ctxPos != -1 {
+		args = append([]reflect.Value(nil), args...)
+		args[ctxPos] = reflect.ValueOf(ctx)
+	}
+
+	// results is buffered so that the losing candidates never block
+	results := make(chan []reflect.Value, len(fns))
+	for _, fn := range fns {
+		go func() {
+			results <- fn.Call(args)
+		}()
+	}
+
+	errs := make([]error, 0, len(fns))
+	for range fns {
+		select {
+		case out := <-results:
+			err := getError(out, errPos)
+			if err == nil {
+				return out
+			}
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return failedResults(outputs(fns[0].Type()), errPos, ctx.Err())
+		}
+	}
+	return failedResults(outputs(fns[0].Type()), errPos, fmt.Errorf("all %d race candidates failed: %w", len(fns), errors.Join(errs...)))
+}
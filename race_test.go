@@ -0,0 +1,105 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Race(t *testing.T) {
+	type (
+		key   string
+		value string
+	)
+	// region returns a candidate answering after delay, or failing with err
+	region := func(name string, delay time.Duration, err error, cancelled chan<- string) func(context.Context, key) (value, error) {
+		return func(ctx context.Context, k key) (value, error) {
+			select {
+			case <-ctx.Done():
+				if cancelled != nil {
+					cancelled <- name
+				}
+				return "", ctx.Err()
+			case <-time.After(delay):
+			}
+			if err != nil {
+				return "", err
+			}
+			return value(name + " " + string(k)), nil
+		}
+	}
+
+	t.Run("should return the outputs of the first candidate succeeding", func(t *testing.T) {
+		t.Parallel()
+		cancelled := make(chan string, 1)
+		ngn, err := Initialize(Race(
+			region("eu", time.Hour, nil, cancelled),
+			region("us", 0, nil, nil),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[value](context.Background(), ngn, key("<k>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, value("us <k>"), out)
+		assert.Equal(t, "eu", <-cancelled)
+		assert.True(t, strings.HasPrefix(report.Functions[0].Name, "race github.com/dezlitz/warp_test.Test_Race"))
+	})
+
+	t.Run("should skip the failing candidates", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Race(
+			region("eu", 0, errors.New("<unavailable>"), nil),
+			region("us", 10*time.Millisecond, nil, nil),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[value](context.Background(), ngn, key("<k>"))
+		assert.NoError(t, err)
+		assert.Equal(t, value("us <k>"), out)
+	})
+
+	t.Run("should fail with the errors of the candidates if all fail", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(Race(
+			func(k key) (value, error) { return "", errors.New("<eu>") },
+			func(k key) (value, error) { return "", errors.New("<eu>") },
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[value](context.Background(), ngn, key("<k>"))
+		assertErr(t, err, "all 2 race candidates failed: <eu>\n<eu>")
+	})
+
+	t.Run("should return an error if the candidates are invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			candidates []any
+			err        string
+		}{
+			{nil, "race must have at least one candidate"},
+			{[]any{"<not a function>"}, "race candidates must be functions"},
+			{[]any{func(k key) value { return "" }}, "race candidates must return an error"},
+			{[]any{func(k key) (value, Cleanup, error) { return "", nil, nil }}, "race candidates can not return a Cleanup"},
+			{
+				[]any{func(k key) (value, error) { return "", nil }, func(ctx context.Context, k key) (value, error) { return "", nil }},
+				"race candidates must have the same type, func(context.Context, warp_test.key) (warp_test.value, error) is not func(warp_test.key) (warp_test.value, error)",
+			},
+		} {
+			_, err := Initialize(Race(tc.candidates...))
+			assertErrContains(t, err, tc.err)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package warp
+
+import (
+	"reflect"
+)
+
+// Redaction masks the values of a type before they are recorded by the engine
+// diagnostics, such as the values of run reports, so that sensitive data never
+// leaks into logs or captured runs. Values passed to functions are never
+// redacted.
+//
+// Pass a Redaction to Initialize to apply it to every function, or wrap a
+// function with Redacted to apply it to the values that function produces.
+type Redaction struct {
+	typ  reflect.Type
+	mask func(reflect.Value) any
+}
+
+// Redactor is implemented by the values that mask themselves in the engine
+// diagnostics, so that a type holding a secret, such as a token or a
+// password, is never recorded in clear by any engine, whatever its
+// Redactions. A Redaction for the type of the value takes precedence.
+//
+// A struct, or a pointer to a struct, not implementing Redactor can instead
+// tag its secret fields: it is then recorded as a copy in which the fields
+// tagged `warp:"redact"` are replaced with "[REDACTED]" if they are strings
+// and with their zero value otherwise.
+//
+//	type Credentials struct {
+//		User     string
+//		Password string `warp:"redact"`
+//	}
+type Redactor interface {
+	// Redact returns the value as it may be recorded.
+	Redact() any
+}
+
+// Redact returns a Redaction replacing the values of type T with the string
+// "[REDACTED]".
+func Redact[T any]() *Redaction {
+	return RedactWith(func(T) any { return "[REDACTED]" })
+}
+
+// RedactWith returns a Redaction replacing the values of type T with the
+// result of mask, for instance to keep the last characters of a token.
+func RedactWith[T any](mask func(T) any) *Redaction {
+	return &Redaction{
+		typ:  reflect.TypeOf((*T)(nil)).Elem(),
+		mask: func(v reflect.Value) any { return mask(v.Interface().(T)) },
+	}
+}
+
+// Redacted applies redactions to the values produced by fn, in addition to and
+// in place of the redactions passed to Initialize for the same types.
+func Redacted(fn any, redactions ...*Redaction) *Provider {
+	p := asProvider(fn)
+	p.redactions = append(p.redactions, redactions...)
+	return p
+}
+
+// redactions maps types to the Redaction of their values.
+type redactions map[reflect.Type]*Redaction
+
+func newRedactions(rs []*Redaction) redactions {
+	out := redactions{}
+	for _, r := range rs {
+		if r != nil {
+			out[r.typ] = r
+		}
+	}
+	return out
+}
+
+// redact returns the value of v as it may be recorded: masked if fn or the
+// engine has a Redaction for its type. Optional values are masked if set.
+func (global redactions) redact(fn *function, v reflect.Value) any {
+	vU := v
+	if isOptional(v.Type()) {
+		if !v.FieldByName("IsSet").Bool() {
+			return v.Interface()
+		}
+		vU = v.FieldByName("Val")
+	}
+
+	if r, ok := fn.redactions[vU.Type()]; ok {
+		return r.mask(vU)
+	}
+	if r, ok := global[vU.Type()]; ok {
+		return r.mask(vU)
+	}
+	if r, ok := vU.Interface().(Redactor); ok && !isNil(vU) {
+		return r.Redact()
+	}
+	if masked, ok := maskFields(vU); ok {
+		return masked
+	}
+	return v.Interface()
+}
+
+// maskFields returns a copy of the struct v, or of the struct v points to,
+// with its fields tagged `warp:"redact"` masked, and whether it has any.
+func maskFields(v reflect.Value) (any, bool) {
+	s := v
+	if s.Kind() == reflect.Pointer {
+		if s.IsNil() {
+			return nil, false
+		}
+		s = s.Elem()
+	}
+	if s.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	var cp reflect.Value
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Type().Field(i)
+		if f.Tag.Get("warp") != "redact" || !f.IsExported() {
+			continue
+		}
+		if !cp.IsValid() {
+			cp = reflect.New(s.Type())
+			cp.Elem().Set(s)
+		}
+		field := cp.Elem().Field(i)
+		if field.Kind() == reflect.String {
+			field.SetString("[REDACTED]")
+		} else {
+			field.SetZero()
+		}
+	}
+	if !cp.IsValid() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Pointer {
+		return cp.Interface(), true
+	}
+	return cp.Elem().Interface(), true
+}
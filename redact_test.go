@@ -0,0 +1,135 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Redact(t *testing.T) {
+	type (
+		login    string
+		password string
+		token    string
+		session  string
+	)
+
+	t.Run("should redact reported values of a type for every function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(l login) (password, Optional[token]) {
+				return password("<secret>"), Optional[token]{Val: "<token>", IsSet: true}
+			},
+			func(p password, t Optional[token]) session { return session("<session>") },
+			Redact[password](),
+			RedactWith(func(t token) any { return "..." + string(t[len(t)-3:]) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[session](context.Background(), ngn, login("<login>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, session("<session>"), out)
+		if assert.Len(t, report.Functions, 2) {
+			assert.Equal(t, []any{"[REDACTED]", "...en>"}, report.Functions[0].Values)
+			assert.Equal(t, []any{session("<session>")}, report.Functions[1].Values)
+		}
+	})
+
+	t.Run("should apply the redactions of a function in place of the global ones", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Redacted(func(l login) password { return password("<secret>") },
+				RedactWith(func(password) any { return "<masked>" })),
+			Redacted(func(p password) session { return session("<session>") },
+				Redact[session]()),
+			Redact[password](),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[session](context.Background(), ngn, login("<login>"), WithReport(&report))
+		assert.NoError(t, err)
+		if assert.Len(t, report.Functions, 2) {
+			assert.Equal(t, []any{"<masked>"}, report.Functions[0].Values)
+			assert.Equal(t, []any{"[REDACTED]"}, report.Functions[1].Values)
+		}
+	})
+
+	t.Run("should redact the values implementing Redactor", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(l login) redactedKey { return redactedKey("<key>") },
+			func(k redactedKey) session { return session("<session>") },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[session](context.Background(), ngn, login("<login>"), WithReport(&report))
+		assert.NoError(t, err)
+		if assert.Len(t, report.Functions, 2) {
+			assert.Equal(t, []any{"key ending in ey>"}, report.Functions[0].Values)
+		}
+	})
+
+	t.Run("should apply a Redaction in place of Redactor", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(l login) redactedKey { return redactedKey("<key>") },
+			Redact[redactedKey](),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[redactedKey](context.Background(), ngn, login("<login>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"[REDACTED]"}, report.Functions[0].Values)
+	})
+
+	t.Run("should redact the struct fields tagged redact", func(t *testing.T) {
+		t.Parallel()
+		type credentials struct {
+			User     string
+			Password password `warp:"redact"`
+			PIN      int      `warp:"redact"`
+		}
+		type account struct{ Creds *credentials }
+		ngn, err := Initialize(
+			func(l login) (credentials, *credentials) {
+				c := credentials{User: "alice", Password: "<secret>", PIN: 1234}
+				return c, &c
+			},
+			func(c credentials) account { return account{Creds: &c} },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[account](context.Background(), ngn, login("<login>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, password("<secret>"), out.Creds.Password, "values passed to functions are not redacted")
+		if assert.Len(t, report.Functions, 2) {
+			masked := credentials{User: "alice", Password: "[REDACTED]"}
+			assert.Equal(t, []any{masked, &masked}, report.Functions[0].Values)
+		}
+	})
+}
+
+// redactedKey is a secret masking itself in the diagnostics.
+type redactedKey string
+
+func (k redactedKey) Redact() any {
+	return "key ending in " + string(k[len(k)-3:])
+}
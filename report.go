@@ -0,0 +1,457 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a function in a run.
+type Status int
+
+const (
+	// StatusNotStarted means the function never got to check its inputs.
+	StatusNotStarted Status = iota
+	// StatusSucceeded means the function ran and stored its outputs.
+	StatusSucceeded
+	// StatusFailed means the function returned an error.
+	StatusFailed
+	// StatusSkipped means the function did not run because one of its
+	// required inputs was not available.
+	StatusSkipped
+	// StatusCancelled means the function was interrupted because the run was
+	// cancelled, by its context or by the failure of another function.
+	StatusCancelled
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusNotStarted:
+		return "not started"
+	case StatusSucceeded:
+		return "succeeded"
+	case StatusFailed:
+		return "failed"
+	case StatusSkipped:
+		return "skipped"
+	case StatusCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// Report describes what happened during a run. Pass WithReport to Run to
+// receive one.
+type Report struct {
+	// Functions holds the report of every function of the run, in
+	// registration order.
+	Functions []FunctionReport
+	// Err is the error returned by the run.
+	Err error
+	// Degraded is true if the run was degraded because the engine was
+	// overloaded, see WithLoadShedding.
+	Degraded bool
+	// Duration is the duration of the run, cleanups included.
+	Duration time.Duration
+	// RunID is the ID of the run, see RunIDFromContext.
+	RunID string
+	// Provided holds the values the run started with, the provided inputs
+	// and the values restored from a Checkpoint, by unwrapped type, after
+	// redaction.
+	Provided map[reflect.Type]any
+	// Mutations holds the stored values mutated during the run, found by
+	// WithMutationAudit.
+	Mutations []Mutation
+}
+
+// FunctionReport describes what happened to a single function during a run.
+type FunctionReport struct {
+	// Name refers to the function, as in validation errors.
+	Name string
+	// Inputs holds the parameter types of the function, including any
+	// Optional wrapper and excluding context.Context and Invoker.
+	Inputs []reflect.Type
+	// Outputs holds the unwrapped types of the values the function produces.
+	Outputs []reflect.Type
+	// Values holds the values the function stored, in the order of Outputs,
+	// after redaction. It is nil if the function did not store its outputs.
+	Values []any
+	// Unset holds the types of the Optional outputs the function returned
+	// unset, gating off the functions requiring them. See Branched.
+	Unset []reflect.Type
+	// Fed holds the unwrapped types of the values of the run the function
+	// was called with, in the order of its parameters. The parameters
+	// receiving no value of the run, such as an unset Optional or a default
+	// value, are left out. It is nil if the function was not called. See
+	// Report.Provenance.
+	Fed []reflect.Type
+	// Status is the outcome of the function.
+	Status Status
+	// Err is the error returned by the function, if any.
+	Err error
+	// Missing holds the required input types that were not available when
+	// the function was skipped. It is empty for a function skipped because
+	// its outputs were provided or because the run was degraded.
+	Missing []reflect.Type
+	// SkipReason tells why the function was switched off for the run, such
+	// as "flag checkout-v2 is off" for a Flagged function, "run degraded"
+	// for a function shed by WithLoadShedding or the type disabled by
+	// WithDisabled. It is empty otherwise.
+	SkipReason string
+	// Duration is the time the function took to return, zero if it was not
+	// started.
+	Duration time.Duration
+	// Resilience describes how a Resilient function produced its outputs,
+	// nil for other functions and for functions that were not called.
+	Resilience *ResilienceReport
+	// Shadow describes how the shadow of a Shadow function compared with
+	// it, nil for other functions and for functions that did not succeed.
+	Shadow *ShadowReport
+	// Tolerated is true if the function failed but the run continued
+	// without its outputs, see BestEffort.
+	Tolerated bool
+}
+
+// SkippedFunction describes a function skipped because of missing inputs.
+type SkippedFunction struct {
+	// Name refers to the function, as in validation errors.
+	Name string
+	// Missing holds the required input types that were not available.
+	Missing []reflect.Type
+}
+
+// Skipped returns the functions skipped because some of their required inputs
+// were not available, in registration order. It answers the question of why
+// a run returned the zero value of its target.
+func (r Report) Skipped() []SkippedFunction {
+	var out []SkippedFunction
+	for _, fr := range r.Functions {
+		if len(fr.Missing) > 0 {
+			out = append(out, SkippedFunction{Name: fr.Name, Missing: fr.Missing})
+		}
+	}
+	return out
+}
+
+// WaitingFunction describes a function that never started because the run
+// ended first.
+type WaitingFunction struct {
+	// Name refers to the function, as in validation errors.
+	Name string
+	// WaitingOn holds the unwrapped types of the inputs that were not
+	// resolved yet, produced by functions that did not return in time.
+	WaitingOn []reflect.Type
+}
+
+// Waiting returns the functions that did not start before the run ended,
+// typically because it was cancelled or timed out, with the inputs they were
+// still waiting on, in registration order.
+func (r Report) Waiting() []WaitingFunction {
+	producers := map[reflect.Type]*FunctionReport{}
+	for i := range r.Functions {
+		for _, outT := range r.Functions[i].Outputs {
+			producers[outT] = &r.Functions[i]
+		}
+	}
+
+	var out []WaitingFunction
+	for _, fr := range r.Functions {
+		if fr.Status != StatusNotStarted {
+			continue
+		}
+		w := WaitingFunction{Name: fr.Name}
+		for _, inT := range fr.Inputs {
+			inT, _ = unwrapOptional(inT)
+			if p, ok := producers[inT]; ok && p.Status != StatusSucceeded && p.Status != StatusSkipped {
+				w.WaitingOn = append(w.WaitingOn, inT)
+			}
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// Produced returns the types of the values stored by the functions of the run,
+// in registration order. Provided inputs and the Optional outputs left unset
+// are not included.
+func (r Report) Produced() []reflect.Type {
+	var out []reflect.Type
+	for _, fr := range r.Functions {
+		if fr.Values == nil {
+			continue
+		}
+		for _, outT := range fr.Outputs {
+			if !slices.Contains(fr.Unset, outT) {
+				out = append(out, outT)
+			}
+		}
+	}
+	return out
+}
+
+// WithReport fills r with the report of the run once it has finished.
+func WithReport(r *Report) RunOption {
+	return func(c *runConfig) {
+		c.report = r
+	}
+}
+
+// runReport records the status of the functions during a run.
+type runReport struct {
+	mu         sync.Mutex
+	functions  map[*function]*FunctionReport
+	order      []*FunctionReport
+	redactions redactions
+	degraded   bool
+	clock      clock
+	started    time.Time
+	// provided holds the values the run started with, see Report.Provided
+	provided  map[reflect.Type]any
+	mutations []Mutation
+}
+
+func newRunReport(funcs []*function, redactions redactions, clock clock) *runReport {
+	r := &runReport{
+		functions:  make(map[*function]*FunctionReport, len(funcs)),
+		order:      make([]*FunctionReport, 0, len(funcs)),
+		redactions: redactions,
+		clock:      clock,
+		started:    clock.now(),
+	}
+	for _, fn := range funcs {
+		fr := &FunctionReport{Name: fn.name, Outputs: fn.valueOutputs()}
+		for _, in := range fn.ins {
+			if !in.injected() {
+				fr.Inputs = append(fr.Inputs, in.typ)
+			}
+		}
+		r.functions[fn] = fr
+		r.order = append(r.order, fr)
+	}
+	return r
+}
+
+// record sets the status of fn. A nil report records nothing.
+func (r *runReport) record(fn *function, status Status, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Status, fr.Err = status, err
+	}
+}
+
+// recordMissing records the missing inputs of a skipped fn. A nil report
+// records nothing.
+func (r *runReport) recordMissing(fn *function, missing []reflect.Type) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Missing = missing
+	}
+}
+
+// recordSkipReason records why fn was switched off for the run. A nil report
+// records nothing.
+func (r *runReport) recordSkipReason(fn *function, reason string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.SkipReason = reason
+	}
+}
+
+// recordDuration records the time fn took to return. A nil report records
+// nothing.
+func (r *runReport) recordDuration(fn *function, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Duration = d
+	}
+}
+
+// recordResilience records how the Resilient fn produced its outputs. A nil
+// report records nothing.
+func (r *runReport) recordResilience(fn *function, rec *ResilienceReport) {
+	if r == nil || rec.Attempts == 0 && !rec.CircuitOpen {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Resilience = rec
+	}
+}
+
+// recordTolerated records that the failure of fn was tolerated. A nil report
+// records nothing.
+func (r *runReport) recordTolerated(fn *function) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Tolerated = true
+	}
+}
+
+// degrade marks the run as degraded. A nil report records nothing.
+func (r *runReport) degrade() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.degraded = true
+}
+
+// recordValues records the redacted value outputs of fn. A nil report records
+// nothing.
+func (r *runReport) recordValues(fn *function, outValues []reflect.Value) {
+	if r == nil {
+		return
+	}
+	var (
+		values = make([]any, len(fn.outs))
+		unset  []reflect.Type
+	)
+	for i, out := range fn.outs {
+		v := outValues[out.pos]
+		values[i] = r.redactions.redact(fn, v)
+		if isOptional(v.Type()) && !v.FieldByName("IsSet").Bool() {
+			unset = append(unset, out.key)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Values, fr.Unset = values, unset
+	}
+}
+
+// record sets the status of fn in the run and in its report.
+func (rs *runState) record(fn *function, status Status, err error) {
+	rs.status[rs.schedule.pos[fn]] = status
+	rs.report.record(fn, status, err)
+}
+
+// isCancellation reports whether err is the error of the cancelled ctx.
+func isCancellation(ctx context.Context, err error) bool {
+	ctxErr := ctx.Err()
+	return ctxErr != nil && errors.Is(err, ctxErr)
+}
+
+// recordError records the error returned by fn. The error counts as a
+// cancellation when it is the error of the cancelled run context.
+func (rs *runState) recordError(ctx context.Context, fn *function, err error) {
+	if isCancellation(ctx, err) {
+		rs.record(fn, StatusCancelled, err)
+		return
+	}
+	rs.record(fn, StatusFailed, err)
+}
+
+func (r *runReport) report(err error) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := Report{Functions: make([]FunctionReport, len(r.order)), Err: err, Degraded: r.degraded, Duration: r.clock.since(r.started), Provided: r.provided, Mutations: r.mutations}
+	for i, fr := range r.order {
+		out.Functions[i] = *fr
+	}
+	return out
+}
+
+// FailureDomain splits the outputs lost in a failed run between those lost
+// because of the failure and those lost as collateral damage.
+type FailureDomain struct {
+	// Failed holds the names of the functions that returned an error.
+	Failed []string
+	// Blocked holds the outputs that could not be produced because they
+	// require, directly or transitively, an output of a failed function.
+	Blocked []reflect.Type
+	// Collateral holds the outputs that were lost only because the run was
+	// cancelled: nothing they require was produced by a failed function.
+	Collateral []reflect.Type
+}
+
+// FailureDomain analyses the report of a failed run to quantify its blast
+// radius. Outputs of functions that were skipped because of inputs missing
+// from the run are neither blocked nor collateral.
+//
+// Collateral outputs are good candidates for isolation: they would have been
+// produced had the failure not cancelled the run.
+func (r Report) FailureDomain() FailureDomain {
+	var (
+		out       FailureDomain
+		producers = map[reflect.Type]*FunctionReport{}
+		blocked   = map[*FunctionReport]bool{}
+	)
+	for i := range r.Functions {
+		fr := &r.Functions[i]
+		for _, outT := range fr.Outputs {
+			producers[outT] = fr
+		}
+		if fr.Status == StatusFailed {
+			out.Failed = append(out.Failed, fr.Name)
+			blocked[fr] = true
+		}
+	}
+
+	// requiresFailed reports whether fr requires an output of a failed
+	// function, directly or through other functions that did not succeed.
+	var requiresFailed func(fr *FunctionReport, visiting map[*FunctionReport]bool) bool
+	requiresFailed = func(fr *FunctionReport, visiting map[*FunctionReport]bool) bool {
+		if b, ok := blocked[fr]; ok {
+			return b
+		}
+		if visiting[fr] {
+			return false
+		}
+		visiting[fr] = true
+
+		var b bool
+		for _, inT := range fr.Inputs {
+			if isOptional(inT) {
+				continue
+			}
+			if p, ok := producers[inT]; ok && p.Status != StatusSucceeded && requiresFailed(p, visiting) {
+				b = true
+				break
+			}
+		}
+		blocked[fr] = b
+		return b
+	}
+
+	for i := range r.Functions {
+		fr := &r.Functions[i]
+		if fr.Status == StatusSucceeded || fr.Status == StatusSkipped {
+			continue
+		}
+		switch {
+		case requiresFailed(fr, map[*FunctionReport]bool{}):
+			out.Blocked = append(out.Blocked, fr.Outputs...)
+		case fr.Status == StatusCancelled || fr.Status == StatusNotStarted:
+			out.Collateral = append(out.Collateral, fr.Outputs...)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,118 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Report(t *testing.T) {
+	type (
+		in        string
+		missing   string
+		user      string
+		orders    string
+		summary   string
+		ads       string
+		adsRanked string
+		audit     string
+	)
+
+	t.Run("should report the status of every function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) user { return user(i) },
+			func(m missing) orders { return orders(m) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[user](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.NoError(t, report.Err)
+		if assert.Len(t, report.Functions, 2) {
+			assert.Equal(t, StatusSucceeded, report.Functions[0].Status)
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(in(""))}, report.Functions[0].Inputs)
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(user(""))}, report.Functions[0].Outputs)
+			assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+		}
+	})
+
+	t.Run("should split lost outputs between blocked and collateral", func(t *testing.T) {
+		t.Parallel()
+		ordersStarted := make(chan struct{})
+		ngn, err := Initialize(
+			func(i in) user { return user(i) },
+			func(u user) (orders, error) {
+				<-ordersStarted
+				return "", errors.New("<orders-error>")
+			},
+			func(o orders) summary { return summary(o) },
+			func(ctx context.Context, u user) (ads, error) {
+				close(ordersStarted)
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			func(a ads) adsRanked { return adsRanked(a) },
+			func(s Optional[summary], u user) audit { return audit(u) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[summary](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assertErr(t, err, "<orders-error>")
+		assertErr(t, report.Err, "<orders-error>")
+
+		domain := report.FailureDomain()
+		if assert.Len(t, domain.Failed, 1) {
+			assert.Contains(t, domain.Failed[0], "(warp_test.user) (warp_test.orders, error)")
+		}
+		assert.ElementsMatch(t, []reflect.Type{
+			reflect.TypeOf(orders("")),
+			reflect.TypeOf(summary("")),
+		}, domain.Blocked)
+		assert.ElementsMatch(t, []reflect.Type{
+			reflect.TypeOf(ads("")),
+			reflect.TypeOf(adsRanked("")),
+			reflect.TypeOf(audit("")),
+		}, domain.Collateral)
+	})
+
+	t.Run("should list the skipped functions with their missing inputs", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) Optional[user] { return Optional[user]{} },
+			func(u user, m missing) orders { return orders(u) },
+			func(o orders) summary { return summary(o) },
+			func(i in, s Optional[summary]) audit { return audit(i) },
+			func(u user) ads { return ads(u) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[summary](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, summary(""), out)
+
+		skipped := report.Skipped()
+		if assert.Len(t, skipped, 3) {
+			assert.Contains(t, skipped[0].Name, "(warp_test.user, warp_test.missing) warp_test.orders")
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(missing(""))}, skipped[0].Missing)
+			assert.Contains(t, skipped[1].Name, "(warp_test.orders) warp_test.summary")
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(orders(""))}, skipped[1].Missing)
+			assert.Contains(t, skipped[2].Name, "(warp_test.user) warp_test.ads")
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(user(""))}, skipped[2].Missing)
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a Resilient function whose circuit breaker is
+// open and which has neither a stale value nor a fallback to serve.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Source is where a Resilient function got the outputs it returned from.
+type Source int
+
+const (
+	// SourcePrimary means the outputs were returned by the primary function.
+	SourcePrimary Source = iota
+	// SourceStale means the outputs were the last outputs of the primary
+	// function, served from the stale cache.
+	SourceStale
+	// SourceFallback means the outputs were returned by the fallback
+	// function.
+	SourceFallback
+	// SourceNone means no outputs were returned: the function failed.
+	SourceNone
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourcePrimary:
+		return "primary"
+	case SourceStale:
+		return "stale"
+	case SourceFallback:
+		return "fallback"
+	case SourceNone:
+		return "none"
+	}
+	return "unknown"
+}
+
+// ResilienceReport describes how a Resilient function produced its outputs
+// during a run.
+type ResilienceReport struct {
+	// Attempts is the number of calls of the primary function.
+	Attempts int
+	// CircuitOpen is true if the primary function was not called because
+	// its circuit breaker was open.
+	CircuitOpen bool
+	// Source is where the outputs came from.
+	Source Source
+	// Errs holds the errors returned by the attempts of the primary
+	// function, in order.
+	Errs []error
+}
+
+// ResilienceOption configures a Resilient function.
+type ResilienceOption func(*resilience)
+
+// Retry calls the primary function up to attempts times in total, waiting
+// backoff before the second attempt and doubling the wait before every
+// further attempt. Retries stop when the run is cancelled.
+func Retry(attempts int, backoff time.Duration) ResilienceOption {
+	return func(r *resilience) {
+		r.attempts, r.backoff = max(attempts, 1), backoff
+	}
+}
+
+// CircuitBreaker stops calling the primary function for cooldown once its
+// calls have failed threshold times in a row, in any run, so that a failing
+// dependency is not hammered. The next call after the cooldown probes the
+// dependency again.
+func CircuitBreaker(threshold int, cooldown time.Duration) ResilienceOption {
+	return func(r *resilience) {
+		r.threshold, r.cooldown = threshold, cooldown
+	}
+}
+
+// StaleCache serves the last outputs of the primary function, if they are not
+// older than maxAge, when the primary function fails or its circuit is open.
+// It can not be used with a function returning a Cleanup.
+func StaleCache(maxAge time.Duration) ResilienceOption {
+	return func(r *resilience) {
+		r.staleAge = maxAge
+	}
+}
+
+// Fallback calls fn, which must have the type of the primary function, when
+// the primary function fails or its circuit is open and no stale outputs can
+// be served. The error of fn fails the function.
+func Fallback(fn any) ResilienceOption {
+	return func(r *resilience) {
+		r.fallback = reflect.ValueOf(fn)
+	}
+}
+
+// Resilient registers primary, a function returning an error, wrapped in the
+// resilience pattern configured by opts. Every call goes through the same
+// stages, each of them optional:
+//
+//  1. the circuit breaker, which skips the primary function while open,
+//  2. the retries of the primary function,
+//  3. the stale cache, serving the last outputs of the primary function,
+//  4. the fallback function.
+//
+// The function keeps the name of primary prefixed with "resilient", and run
+// reports describe how it produced its outputs in FunctionReport.Resilience.
+// The state of the circuit breaker and of the stale cache is shared by the
+// runs of the engine.
+func Resilient(primary any, opts ...ResilienceOption) *Provider {
+	fnV := reflect.ValueOf(primary)
+	p := &Provider{fn: primary}
+	if primary == nil || fnV.Kind() != reflect.Func {
+		p.err = errors.New("resilient input must be a function")
+		return p
+	}
+	p.name, p.location = "resilient "+referTo(fnV), locate(fnV)
+
+	r := &resilience{primary: fnV, attempts: 1}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+
+	fnT := fnV.Type()
+	outs := outputs(fnT)
+	r.errPos = getPosOfType[error](outs)
+	switch {
+	case r.errPos == -1:
+		p.err = errors.New("resilient function must return an error")
+		return p
+	case r.fallback.IsValid() && (r.fallback.Kind() != reflect.Func || r.fallback.IsNil() || r.fallback.Type() != fnT):
+		p.err = fmt.Errorf("fallback must be a function of type %s", fnT)
+		return p
+	case r.staleAge > 0 && getPosOfType[Cleanup](outs) != -1:
+		p.err = errors.New("resilient function returning a Cleanup can not use a stale cache")
+		return p
+	}
+
+	// The view function accepts the run context, in first position unless
+	// primary already accepts one
+	ins, ctxPos := inputs(fnT), getPosOfType[context.Context](inputs(fnT))
+	if ctxPos == -1 {
+		ins = append([]reflect.Type{reflect.TypeOf((*context.Context)(nil)).Elem()}, ins...)
+	}
+	viewT := reflect.FuncOf(ins, outs, false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		ctx := args[max(ctxPos, 0)].Interface().(context.Context)
+		if ctxPos == -1 {
+			args = args[1:]
+		}
+		rec := resilienceReportFrom(ctx)
+		return r.call(ctx, args, rec)
+	}).Interface()
+	p.resilient = true
+	return p
+}
+
+// resilience holds the configuration and the state of a Resilient function.
+type resilience struct {
+	primary   reflect.Value
+	errPos    int
+	attempts  int
+	backoff   time.Duration
+	threshold int
+	cooldown  time.Duration
+	staleAge  time.Duration
+	fallback  reflect.Value
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	last      []reflect.Value
+	lastAt    time.Time
+}
+
+// call produces the outputs of the function for args and describes how in
+// rec.
+func (r *resilience) call(ctx context.Context, args []reflect.Value, rec *ResilienceReport) []reflect.Value {
+	var err error
+	clock := clockFrom(ctx)
+	if r.open(clock.now()) {
+		rec.CircuitOpen, err = true, ErrCircuitOpen
+	} else {
+		backoff := r.backoff
+		for attempt := 1; attempt <= r.attempts; attempt++ {
+			if attempt > 1 {
+				if !clock.sleep(ctx, backoff) {
+					break
+				}
+				backoff *= 2
+			}
+
+			rec.Attempts++
+			out := r.primary.Call(args)
+			if err = getError(out, r.errPos); err == nil {
+				r.succeed(out, clock.now())
+				rec.Source = SourcePrimary
+				return out
+			}
+			rec.Errs = append(rec.Errs, err)
+		}
+		r.fail(clock.now())
+		err = fmt.Errorf("failed after %d attempts: %w", rec.Attempts, err)
+	}
+
+	if out, ok := r.stale(clock.now()); ok {
+		rec.Source = SourceStale
+		return out
+	}
+	if r.fallback.IsValid() {
+		out := r.fallback.Call(args)
+		if getError(out, r.errPos) == nil {
+			rec.Source = SourceFallback
+		} else {
+			rec.Source = SourceNone
+		}
+		return out
+	}
+
+	rec.Source = SourceNone
+	out := make([]reflect.Value, r.primary.Type().NumOut())
+	for i := range out {
+		out[i] = reflect.Zero(r.primary.Type().Out(i))
+	}
+	out[r.errPos] = reflect.ValueOf(&err).Elem()
+	return out
+}
+
+// open reports whether the circuit breaker is open at now.
+func (r *resilience) open(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.threshold > 0 && now.Before(r.openUntil)
+}
+
+// succeed closes the circuit breaker and caches out, produced at now.
+func (r *resilience) succeed(out []reflect.Value, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = 0
+	if r.staleAge > 0 {
+		r.last, r.lastAt = out, now
+	}
+}
+
+// fail counts a failed call at now and opens the circuit breaker once the
+// threshold is reached.
+func (r *resilience) fail(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	if r.threshold > 0 && r.failures >= r.threshold {
+		r.openUntil = now.Add(r.cooldown)
+	}
+}
+
+// stale returns the cached outputs if they are fresh enough at now.
+func (r *resilience) stale(now time.Time) ([]reflect.Value, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == nil || now.Sub(r.lastAt) > r.staleAge {
+		return nil, false
+	}
+	return r.last, true
+}
+
+type resilienceReportKey struct{}
+
+// withResilienceReport returns a copy of ctx carrying rec, filled by the
+// Resilient function called with the context.
+func withResilienceReport(ctx context.Context, rec *ResilienceReport) context.Context {
+	return context.WithValue(ctx, resilienceReportKey{}, rec)
+}
+
+// resilienceReportFrom returns the ResilienceReport carried by ctx, or a
+// discarded one if it carries none.
+func resilienceReportFrom(ctx context.Context) *ResilienceReport {
+	if rec, ok := ctx.Value(resilienceReportKey{}).(*ResilienceReport); ok {
+		return rec
+	}
+	return &ResilienceReport{}
+}
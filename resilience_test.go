@@ -0,0 +1,142 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Resilient(t *testing.T) {
+	type (
+		query string
+		quote string
+	)
+	// failing returns a primary function failing its first n calls
+	failing := func(n int32, calls *atomic.Int32) func(query) (quote, error) {
+		return func(q query) (quote, error) {
+			if calls.Add(1) <= n {
+				return "", errors.New("<unavailable>")
+			}
+			return quote("primary " + q), nil
+		}
+	}
+	fallback := func(q query) (quote, error) { return quote("fallback " + q), nil }
+
+	t.Run("should retry the primary function", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(Resilient(failing(2, &calls), Retry(3, time.Millisecond), Fallback(fallback)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[quote](context.Background(), ngn, query("<q>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, quote("primary <q>"), out)
+		assert.True(t, strings.HasPrefix(report.Functions[0].Name, "resilient github.com/dezlitz/warp_test.Test_Resilient"))
+		if assert.NotNil(t, report.Functions[0].Resilience) {
+			assert.Equal(t, 3, report.Functions[0].Resilience.Attempts)
+			assert.Equal(t, SourcePrimary, report.Functions[0].Resilience.Source)
+			assert.Len(t, report.Functions[0].Resilience.Errs, 2)
+		}
+	})
+
+	t.Run("should call the fallback once the retries are exhausted", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(Resilient(failing(5, &calls), Retry(2, 0), Fallback(fallback)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[quote](context.Background(), ngn, query("<q>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, quote("fallback <q>"), out)
+		assert.Equal(t, SourceFallback, report.Functions[0].Resilience.Source)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("should serve the stale outputs of the primary function", func(t *testing.T) {
+		t.Parallel()
+		var fail atomic.Bool
+		ngn, err := Initialize(Resilient(
+			func(q query) (quote, error) {
+				if fail.Load() {
+					return "", errors.New("<unavailable>")
+				}
+				return quote("primary " + q), nil
+			},
+			StaleCache(time.Hour),
+			Fallback(fallback),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[quote](context.Background(), ngn, query("<q1>"))
+		assert.NoError(t, err)
+
+		fail.Store(true)
+		var report Report
+		out, err := Run[quote](context.Background(), ngn, query("<q2>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, quote("primary <q1>"), out)
+		assert.Equal(t, SourceStale, report.Functions[0].Resilience.Source)
+	})
+
+	t.Run("should stop calling the primary function while the circuit is open", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(Resilient(failing(5, &calls), CircuitBreaker(2, time.Hour)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for range 2 {
+			_, err = Run[quote](context.Background(), ngn, query("<q>"))
+			assertErr(t, err, "failed after 1 attempts: <unavailable>")
+		}
+
+		var report Report
+		_, err = Run[quote](context.Background(), ngn, query("<q>"), WithReport(&report))
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.True(t, report.Functions[0].Resilience.CircuitOpen)
+		assert.Equal(t, SourceNone, report.Functions[0].Resilience.Source)
+	})
+
+	t.Run("should stop retrying once the run is cancelled", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(Resilient(failing(5, &calls), Retry(3, time.Hour)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = Run[quote](ctx, ngn, query("<q>"))
+		assertErr(t, err, "failed after 1 attempts: <unavailable>")
+	})
+
+	t.Run("should return a validation error for invalid primary and fallback functions", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Resilient(func(q query) quote { return quote(q) }))
+		assertErrContains(t, err, "resilient function must return an error")
+
+		_, err = Initialize(Resilient(fallback, Fallback(func(q query) quote { return quote(q) })))
+		assertErrContains(t, err, "fallback must be a function of type func(warp_test.query) (warp_test.quote, error)")
+
+		_, err = Initialize(Resilient("not a function"))
+		assertErrContains(t, err, "resilient input must be a function")
+	})
+}
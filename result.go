@@ -0,0 +1,32 @@
+package warp
+
+import "context"
+
+// RunResult is the outcome of a single run of the engine.
+type RunResult[T any] struct {
+	// Provided holds the inputs the run was provided with.
+	Provided []any
+	// Value is the output of type T, or the zero value if the run failed.
+	Value T
+	// Err is the error returned by the run, if any.
+	Err error
+	// Report describes what happened to every function of the run: its
+	// status, error, duration and the values it produced. See Report.Skipped
+	// and Report.Produced. It is only filled by RunDetailed.
+	Report Report
+}
+
+// RunDetailed runs the engine as Run does and returns the output of type T
+// together with the report of the run, to debug a run or to build a response
+// from several outputs of the run without running it again. A report passed
+// with WithReport is filled as well.
+func RunDetailed[T any](ctx context.Context, e *Engine, provided ...any) RunResult[T] {
+	inputs, cfg := splitRunOptions(provided)
+
+	var report Report
+	v, err := Run[T](ctx, e, append(provided[:len(provided):len(provided)], WithReport(&report))...)
+	if cfg.report != nil {
+		*cfg.report = report
+	}
+	return RunResult[T]{Provided: inputs, Value: v, Err: err, Report: report}
+}
@@ -0,0 +1,93 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunDetailed(t *testing.T) {
+	type (
+		in      string
+		missing string
+		user    string
+		orders  string
+		summary string
+	)
+
+	t.Run("should return the output with the report of the run", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) user {
+				time.Sleep(5 * time.Millisecond)
+				return user(i)
+			},
+			func(m missing) orders { return orders(m) },
+			func(u user, o Optional[orders]) summary { return summary(u) + "<summary>" },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var handle RunHandle
+		result := RunDetailed[summary](context.Background(), ngn, in("<in>"), WithHandle(&handle))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, summary("<in><summary>"), result.Value)
+		assert.Equal(t, []any{in("<in>")}, result.Provided)
+		assert.Equal(t, []reflect.Type{reflect.TypeOf(user("")), reflect.TypeOf(summary(""))}, result.Report.Produced())
+		if assert.Len(t, result.Report.Skipped(), 1) {
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(missing(""))}, result.Report.Skipped()[0].Missing)
+		}
+		if assert.Len(t, result.Report.Functions, 3) {
+			assert.GreaterOrEqual(t, result.Report.Functions[0].Duration, 5*time.Millisecond)
+			assert.Zero(t, result.Report.Functions[1].Duration)
+		}
+		assert.GreaterOrEqual(t, result.Report.Duration, 5*time.Millisecond)
+	})
+
+	t.Run("should report the error of every failed function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) (user, error) { return "", errors.New("<user-error>") },
+			func(i in) (orders, error) { return "", errors.New("<orders-error>") },
+			func(u user, o orders) summary { return summary(u) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := RunDetailed[summary](context.Background(), ngn, in("<in>"), WithRunMode(RunMode{Failure: FailAggregate}))
+		assertErrContains(t, result.Err, "<user-error>")
+		assertErrContains(t, result.Err, "<orders-error>")
+		assert.Equal(t, result.Err, result.Report.Err)
+		if assert.Len(t, result.Report.Functions, 3) {
+			assertErr(t, result.Report.Functions[0].Err, "<user-error>")
+			assertErr(t, result.Report.Functions[1].Err, "<orders-error>")
+			assert.Equal(t, StatusNotStarted, result.Report.Functions[2].Status)
+		}
+		assert.Empty(t, result.Report.Produced())
+	})
+	t.Run("should fill the report passed with WithReport", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) user { return user(i) },
+			func(u user) summary { return summary(u) + "<summary>" },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		result := RunDetailed[summary](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []any{in("<in>")}, result.Provided)
+		assert.Len(t, result.Report.Functions, 2)
+		assert.Equal(t, result.Report, report)
+	})
+}
@@ -0,0 +1,74 @@
+package warp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+)
+
+// WithRunID sets the ID of the run, such as the ID of the request or of the
+// trace the run serves, instead of a generated one. See RunIDFromContext.
+func WithRunID(id string) RunOption {
+	return func(c *runConfig) {
+		c.runID = id
+	}
+}
+
+// WithMetadata attaches the metadata key and value to the run. See
+// MetadataFromContext.
+func WithMetadata(key, value string) RunOption {
+	return func(c *runConfig) {
+		if c.metadata == nil {
+			c.metadata = map[string]string{}
+		}
+		c.metadata[key] = value
+	}
+}
+
+// RunIDFromContext returns the ID of the run carried by ctx, the context of a
+// function or of an event listener, so that the logs and traces emitted
+// during a run can be correlated. Every run has an ID, generated unless set
+// with WithRunID. ok is false if ctx does not belong to a run.
+func RunIDFromContext(ctx context.Context) (id string, ok bool) {
+	info, ok := ctx.Value(runInfoKey{}).(*runInfo)
+	if !ok {
+		return "", false
+	}
+	return info.id, true
+}
+
+// MetadataFromContext returns a copy of the metadata attached with
+// WithMetadata to the run carried by ctx, nil if there is none.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	info, ok := ctx.Value(runInfoKey{}).(*runInfo)
+	if !ok {
+		return nil
+	}
+	return maps.Clone(info.metadata)
+}
+
+type runInfoKey struct{}
+
+// runInfo is the ID and the metadata of a run.
+type runInfo struct {
+	id       string
+	metadata map[string]string
+}
+
+// withRunInfo returns a copy of ctx carrying the ID and the metadata of the
+// run configured by cfg.
+func withRunInfo(ctx context.Context, cfg *runConfig) context.Context {
+	id := cfg.runID
+	if id == "" {
+		id = newRunID()
+	}
+	return context.WithValue(ctx, runInfoKey{}, &runInfo{id: id, metadata: cfg.metadata})
+}
+
+// newRunID returns a random run ID of 16 hexadecimal characters.
+func newRunID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
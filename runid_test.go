@@ -0,0 +1,63 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunIDFromContext(t *testing.T) {
+	type (
+		in  string
+		out string
+	)
+
+	// id and metadata hold what the function saw of its last run, so the
+	// subtests do not run in parallel
+	var (
+		id       string
+		metadata map[string]string
+	)
+	ngn, err := Initialize(func(ctx context.Context, i in) out {
+		id, _ = RunIDFromContext(ctx)
+		metadata = MetadataFromContext(ctx)
+		return out(i)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should generate a distinct ID per run", func(t *testing.T) {
+		var report Report
+		_, err := Run[out](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		first := id
+		assert.Len(t, first, 16)
+		assert.Equal(t, first, report.RunID)
+		assert.Nil(t, metadata)
+
+		_, err = Run[out](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, id)
+	})
+
+	t.Run("should expose the ID and the metadata set by the caller", func(t *testing.T) {
+		_, err := Run[out](context.Background(), ngn, in("<in>"),
+			WithRunID("<request-id>"),
+			WithMetadata("tenant", "<tenant>"),
+			WithMetadata("region", "<region>"),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, "<request-id>", id)
+		assert.Equal(t, map[string]string{"tenant": "<tenant>", "region": "<region>"}, metadata)
+	})
+
+	t.Run("should not find a run in a context that does not belong to one", func(t *testing.T) {
+		_, ok := RunIDFromContext(context.Background())
+		assert.False(t, ok)
+		assert.Nil(t, MetadataFromContext(context.Background()))
+	})
+}
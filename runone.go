@@ -0,0 +1,34 @@
+package warp
+
+import (
+	"context"
+	"reflect"
+)
+
+// RunOne runs the engine as Run does under WithCollect and returns the single
+// available output assignable to T, such as the single output implementing
+// the interface T, so that call sites do not check the number of collected
+// values themselves.
+//
+// An error matching ErrNotExactlyOne is returned if the run produced no value
+// or several values assignable to T, and an error matching
+// ErrTargetNotProducible if no function produces one.
+func RunOne[T any](ctx context.Context, e *Engine, provided ...any) (T, error) {
+	var zero T
+	if e == nil || !e.initialized {
+		return zero, categorize(ErrNotInitialized, "error running engine that has not been initialized")
+	}
+
+	out, err := run[[]T](ctx, e, e.schedule, append(provided[:len(provided):len(provided)], WithCollect()))
+	if err != nil {
+		return zero, err
+	}
+	switch target := reflect.TypeOf((*T)(nil)).Elem(); len(out) {
+	case 1:
+		return out[0], nil
+	case 0:
+		return zero, categorize(ErrNotExactlyOne, "no value of type %s was produced", target)
+	default:
+		return zero, categorize(ErrNotExactlyOne, "%d values of type %s were produced", len(out), target)
+	}
+}
@@ -0,0 +1,84 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type (
+	card   string
+	wallet string
+)
+
+func (c card) String() string   { return string(c) }
+func (w wallet) String() string { return string(w) }
+
+func Test_RunOne(t *testing.T) {
+	type (
+		order  string
+		method string
+	)
+	ngn, err := Initialize(
+		func(o order, m method) (Optional[card], error) {
+			if m != "card" {
+				return Optional[card]{}, nil
+			}
+			return Optional[card]{Val: card("card " + o), IsSet: true}, nil
+		},
+		func(o order, m method) (Optional[wallet], error) {
+			if m == "card" {
+				return Optional[wallet]{}, nil
+			}
+			return Optional[wallet]{Val: wallet("wallet " + o), IsSet: true}, nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should return the single value assignable to the target", func(t *testing.T) {
+		t.Parallel()
+		out, err := RunOne[fmt.Stringer](context.Background(), ngn, order("<o>"), method("card"))
+		assert.NoError(t, err)
+		assert.Equal(t, card("card <o>"), out)
+
+		w, err := RunOne[wallet](context.Background(), ngn, order("<o>"), method("wallet"))
+		assert.NoError(t, err)
+		assert.Equal(t, wallet("wallet <o>"), w)
+	})
+
+	t.Run("should fail if no value was produced", func(t *testing.T) {
+		t.Parallel()
+		_, err := RunOne[wallet](context.Background(), ngn, order("<o>"), method("card"))
+		assertErr(t, err, "no value of type warp_test.wallet was produced")
+		assert.True(t, errors.Is(err, ErrNotExactlyOne))
+	})
+
+	t.Run("should fail if several values were produced", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(o order) card { return card(o) },
+			func(o order) wallet { return wallet(o) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RunOne[fmt.Stringer](context.Background(), ngn, order("<o>"))
+		assertErr(t, err, "2 values of type fmt.Stringer were produced")
+		assert.True(t, errors.Is(err, ErrNotExactlyOne))
+	})
+
+	t.Run("should fail if no function produces a value of the target type", func(t *testing.T) {
+		t.Parallel()
+		_, err := RunOne[error](context.Background(), ngn, order("<o>"), method("card"))
+		assertErr(t, err, "no function output type is assignable to error")
+		assert.True(t, errors.Is(err, ErrTargetNotProducible))
+	})
+}
@@ -0,0 +1,489 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// schedule is the precomputed dependency graph of a set of functions run
+// together.
+type schedule struct {
+	funcs []*function
+	// deps counts, per function, the inputs produced by other functions of
+	// the set.
+	deps []int32
+	// consumers lists, per signal, the position of the functions of the set
+	// consuming it.
+	consumers [][]int
+	// order holds the position of the functions in dependency order.
+	order []int
+	// pos maps the functions to their position.
+	pos map[*function]int
+	// producer holds, per signal, the position of the function of the set
+	// producing it, -1 if none.
+	producer []int
+	// lazy is true if a function of the set has a Lazy parameter, and
+	// deferred holds, per function, whether it only runs once forced by a
+	// Lazy parameter. See Lazy.
+	lazy     bool
+	deferred []bool
+	// paths holds, per function, the length of its critical path, see
+	// Estimated
+	paths []time.Duration
+	// states pools the state of the runs of the set
+	states statePool
+}
+
+func newSchedule(funcs []*function, numSignals int) *schedule {
+	s := &schedule{
+		funcs:     funcs,
+		deps:      make([]int32, len(funcs)),
+		consumers: make([][]int, numSignals),
+		pos:       make(map[*function]int, len(funcs)),
+		producer:  make([]int, numSignals),
+		deferred:  make([]bool, len(funcs)),
+	}
+	for i, fn := range funcs {
+		s.pos[fn] = i
+	}
+
+	for i := range s.producer {
+		s.producer[i] = -1
+	}
+	for i, fn := range funcs {
+		for _, out := range fn.outs {
+			s.producer[out.signal] = i
+		}
+	}
+
+	lazyConsumed := make([]bool, numSignals)
+	for i, fn := range funcs {
+		for _, in := range fn.ins {
+			if in.signal == -1 || s.producer[in.signal] == -1 {
+				continue
+			}
+			if in.lazy {
+				s.lazy, lazyConsumed[in.signal] = true, true
+				continue
+			}
+			s.deps[i]++
+			s.consumers[in.signal] = append(s.consumers[in.signal], i)
+		}
+	}
+
+	pending := append([]int32(nil), s.deps...)
+	for i := range funcs {
+		if pending[i] == 0 {
+			s.order = append(s.order, i)
+		}
+	}
+	for next := 0; next < len(s.order); next++ {
+		for _, out := range funcs[s.order[next]].outs {
+			for _, i := range s.consumers[out.signal] {
+				if pending[i]--; pending[i] == 0 {
+					s.order = append(s.order, i)
+				}
+			}
+		}
+	}
+
+	// A function is deferred if its outputs are consumed, but only lazily or
+	// by deferred functions. Consumers come last in order.
+	for k := len(s.order) - 1; k >= 0 && s.lazy; k-- {
+		i := s.order[k]
+		var consumed, eagerly bool
+		for _, out := range funcs[i].outs {
+			consumed = consumed || lazyConsumed[out.signal] || len(s.consumers[out.signal]) > 0
+			for _, c := range s.consumers[out.signal] {
+				eagerly = eagerly || !s.deferred[c]
+			}
+		}
+		s.deferred[i] = consumed && !eagerly
+	}
+
+	s.paths = s.criticalPaths()
+	return s
+}
+
+// produced returns an error if no function of s produces values of a type
+// returned by the run, such as when the producer of the target of RunTagged is
+// not tagged.
+func (s *schedule) produced(index *valueIndex, outTs []reflect.Type) error {
+	for _, outT := range outTs {
+		slot, ok := index.slots[outT]
+		if !ok || slot >= len(s.producer) || s.producer[slot] == -1 {
+			return categorize(ErrTargetNotProducible, "target %s can not be produced: no function of the run produces it", outT)
+		}
+	}
+	return nil
+}
+
+// reachability tells, per function of a schedule, whether the function can
+// run given the values available. available holds, per value slot, whether
+// the value is stored or produced by a reachable function, see reachable.
+type reachability struct {
+	available []bool
+	reachable []bool
+	// missing holds, per unreachable function, its required inputs that are
+	// not available
+	missing [][]reflect.Type
+}
+
+func newReachability(s *schedule, index *valueIndex) reachability {
+	return reachability{
+		available: make([]bool, len(index.types)),
+		reachable: make([]bool, len(s.funcs)),
+		missing:   make([][]reflect.Type, len(s.funcs)),
+	}
+}
+
+// reachable reports, per function, whether the function can run given the
+// values available, which r.available must hold on entry: every required
+// input is available or produced by a reachable function, or has a default
+// value. A function whose outputs are all available, such as an adapter whose
+// target was provided, is not reachable, unless it is a sink producing none.
+// The outputs of the reachable functions are then available.
+//
+// The missing inputs of an unreachable function are none if its outputs are
+// all available or if it is switched off: off holds, per function, the reason
+// it is switched off for the run, see switchedOff, and may be nil.
+func (s *schedule) reachable(r *reachability, off []string) {
+	for _, i := range s.order {
+		fn := s.funcs[i]
+		r.missing[i] = nil
+		var reachable bool
+		switch {
+		case off != nil && off[i] != "":
+		case fn.cached != nil && fn.cached():
+			reachable = true
+		default:
+			reachable = fn.sink || len(fn.outs) > 0
+			for _, o := range fn.outs {
+				reachable = reachable && !r.available[o.signal]
+			}
+			for _, in := range fn.ins {
+				if !in.injected() && !in.optional && !in.zero && !in.lazy && !in.def.IsValid() && !r.available[in.slot] {
+					reachable = false
+					r.missing[i] = append(r.missing[i], in.key)
+				}
+			}
+		}
+
+		r.reachable[i] = reachable
+		if reachable {
+			for _, o := range fn.outs {
+				r.available[o.signal] = true
+			}
+		}
+	}
+}
+
+// reachableFrom returns the reachability of the functions of s given the
+// types in stored, see reachable.
+func (s *schedule) reachableFrom(index *valueIndex, stored map[reflect.Type]bool) *reachability {
+	r := newReachability(s, index)
+	for t := range stored {
+		if slot, ok := index.slots[t]; ok {
+			r.available[slot] = true
+		}
+	}
+	s.reachable(&r, nil)
+	return &r
+}
+
+// produces returns an error if the target type is neither stored nor
+// produced by a reachable function.
+func (s *schedule) produces(r *reachability, stored bool, target reflect.Type) error {
+	if stored {
+		return nil
+	}
+	for i, fn := range s.funcs {
+		if !fn.produces(target) {
+			continue
+		}
+		if r.reachable[i] {
+			return nil
+		}
+		if missing := r.missing[i]; len(missing) > 0 {
+			return categorize(ErrTargetNotProducible, "target %s can not be produced: function %s can not run: %s", target, fn.name, describeMissing(missing))
+		}
+		return categorize(ErrTargetNotProducible, "target %s can not be produced: function %s does not run", target, fn.name)
+	}
+	return categorize(ErrTargetNotProducible, "target %s can not be produced: no function of the run produces it", target)
+}
+
+// start launches the functions of the run that do not depend on any other
+// function. The remaining functions are launched by resolve once all their
+// inputs are resolved, so no goroutine ever blocks waiting for an input.
+//
+// Functions that can not be reached from the provided inputs are skipped up
+// front, without a goroutine. Under the Strict input policy, an unreachable
+// function fails the run before any function is launched.
+func (rs *runState) start(ctx context.Context, eg *errgroup.Group, s *schedule) error {
+	rs.ctx, rs.eg, rs.schedule = ctx, eg, s
+	rs.pending, rs.started, rs.resolved = rs.pooled.pending, rs.pooled.started, rs.pooled.resolved
+	rs.status, rs.errs = rs.pooled.status, rs.pooled.errs
+	for i := range s.funcs {
+		rs.pending[i].Store(s.deps[i])
+	}
+	rs.lazyState(s)
+
+	r := &rs.pooled.reachability
+	for slot := range r.available {
+		_, r.available[slot] = rs.storage.loadSlot(slot)
+	}
+	off := rs.switchedOff(ctx)
+	s.reachable(r, off)
+	reachable, missing := r.reachable, r.missing
+	for _, target := range rs.targets {
+		_, stored := rs.storage.Load(target)
+		if err := s.produces(r, stored, target); err != nil {
+			return err
+		}
+	}
+	if rs.mode.Inputs == Strict {
+		for i, fn := range s.funcs {
+			if !reachable[i] && len(missing[i]) > 0 {
+				return rs.skip(fn, missing[i])
+			}
+		}
+	}
+
+	for i, fn := range s.funcs {
+		if !reachable[i] {
+			rs.started[i].Store(true)
+			rs.record(fn, StatusSkipped, nil)
+			rs.report.recordMissing(fn, missing[i])
+			if off != nil {
+				rs.report.recordSkipReason(fn, off[i])
+			}
+			rs.listeners.functionSkipped(rs.ctx, FunctionEvent{Name: fn.name, Status: StatusSkipped})
+		}
+	}
+	for i, fn := range s.funcs {
+		if !reachable[i] {
+			rs.resolve(fn)
+			rs.finish(i)
+		}
+	}
+	if rs.progress != nil {
+		var total int
+		for i := range s.funcs {
+			if reachable[i] && !s.deferred[i] {
+				total++
+			}
+		}
+		rs.progress.start(total)
+	}
+
+	for i, fn := range s.funcs {
+		// Cached singletons do not need their inputs
+		if reachable[i] && (s.deps[i] == 0 || (fn.cached != nil && fn.cached())) {
+			rs.ready(i)
+		}
+	}
+	// The producers of the outputs of the run are never deferred
+	for i, fn := range s.funcs {
+		if s.deferred[i] && slices.ContainsFunc(fn.valueOutputs(), rs.returns) {
+			rs.force(i)
+		}
+	}
+	rs.dispatch()
+	return nil
+}
+
+// launch queues the function at position i, at most once per run, to be
+// run by a worker of the run, see work.
+func (rs *runState) launch(i int) {
+	if rs.started[i].Swap(true) {
+		return
+	}
+	rs.pooled.launched[i] = rs.clock.now()
+	rs.pooled.queue.push(i)
+}
+
+// dispatch starts a worker for each queued function no worker is coming for.
+func (rs *runState) dispatch() {
+	for range rs.pooled.queue.claim() {
+		rs.eg.Go(rs.pooled.work)
+	}
+}
+
+// work runs the queued functions one after the other until the queue is
+// empty. A worker takes the first queued function and leaves the others to
+// new workers, so a function never waits for a worker, and a chain of
+// functions runs on a single goroutine.
+//
+// A function queuing its consumers before returning, then blocking, must
+// dispatch them first, see dispatch. Once a function fails the run the
+// worker stops, leaving the queued functions to other workers.
+func (rs *runState) work() error {
+	q := &rs.pooled.queue
+	for arriving := true; ; arriving = false {
+		i, ok := q.pop(arriving)
+		if !ok {
+			return nil
+		}
+		rs.dispatch()
+		if err := rs.execute(i); err != nil {
+			rs.dispatch()
+			return err
+		}
+	}
+}
+
+// readyQueue holds the functions of a run launched but not taken by a worker
+// yet, in launch order. Each function is queued at most once per run.
+type readyQueue struct {
+	mu    sync.Mutex
+	items []int
+	head  int
+	// coming counts the workers started that did not take a function yet
+	coming int
+}
+
+func (q *readyQueue) push(i int) {
+	q.mu.Lock()
+	q.items = append(q.items, i)
+	q.mu.Unlock()
+}
+
+// pop takes the first queued function, if any. A new worker is arriving on
+// its first pop.
+func (q *readyQueue) pop(arriving bool) (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if arriving {
+		q.coming--
+	}
+	if q.head == len(q.items) {
+		return 0, false
+	}
+	i := q.items[q.head]
+	q.head++
+	return i, true
+}
+
+// claim returns the number of queued functions no worker is coming for, and
+// counts the workers to start for them as coming.
+func (q *readyQueue) claim() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := max(len(q.items)-q.head-q.coming, 0)
+	q.coming += n
+	return n
+}
+
+func (q *readyQueue) clear() {
+	q.items, q.head, q.coming = q.items[:0], 0, 0
+}
+
+// execute runs the function at position i. A function launched after the
+// run was cancelled, or halted under FailDrain, is not called.
+//
+// Under cooperative execution functions yield the processor before running,
+// see WithCooperativeExecution. Under the Sequential execution policy
+// functions run one at a time. Limited functions wait for a previous call to
+// return, and CPU and IO bound functions for a slot of their pool, see
+// Limited and CPUBound. When the functions executing at once are limited,
+// the ready functions start by priority and critical path, see Prioritized
+// and Estimated. A function still waiting once the run is cancelled is not
+// called.
+//
+// Under the FailAggregate failure policy the errors of the functions are kept
+// in errs instead of cancelling the run. Once the run is short-circuited
+// their errors are ignored, see WithShortCircuit.
+func (rs *runState) execute(i int) error {
+	fn, ready := rs.schedule.funcs[i], rs.pooled.launched[i]
+	defer rs.finish(i)
+	prec := rs.schedule.precedence(i)
+	exit, err := rs.cooperative.enter(rs.ctx, rs.slots, prec)
+	defer exit()
+	// A function waiting for a slot gives up once the run is cancelled
+	gates, held := [...]*gate{rs.sequential, fn.limit, rs.pools.gate(fn.workload)}, 0
+	for err == nil && held < len(gates) {
+		if err = gates[held].acquire(rs.ctx, prec); err == nil {
+			held++
+		}
+	}
+	defer func() {
+		for k := held - 1; k >= 0; k-- {
+			gates[k].release()
+		}
+	}()
+
+	started := rs.clock.now()
+	delay := started.Sub(ready)
+	rs.listeners.functionStart(rs.ctx, FunctionEvent{Name: fn.name, Delay: delay})
+	cached := fn.cached != nil && fn.cached()
+	if err == nil {
+		err = rs.ctx.Err()
+	}
+	if err == nil && rs.halted != nil {
+		err = rs.halted.Err()
+	}
+	if err != nil {
+		rs.record(fn, StatusCancelled, err)
+	} else {
+		ctx, exit := rs.handle.enter(rs.ctx, fn)
+		if rs.shed != nil && rs.shed.policy.FunctionTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = rs.clock.withTimeout(ctx, rs.shed.policy.FunctionTimeout)
+			defer cancel()
+		}
+		err = fn.run(ctx, rs)
+		if err != nil && rs.ctx.Err() == nil && errors.Is(context.Cause(ctx), ErrFunctionCancelled) {
+			err = rs.cancelled(fn, err)
+		}
+		exit()
+	}
+	if err == nil && rs.status[i] == StatusSucceeded {
+		rs.produced(fn)
+	} else if err != nil && rs.shortCircuited() {
+		// The run no longer needs fn
+		err = nil
+	}
+	duration := rs.clock.since(started)
+	if status := rs.status[i]; !cached && (status == StatusSucceeded || status == StatusFailed) {
+		fn.latency.observe(duration)
+		fn.stats.observe(duration, status == StatusFailed)
+	}
+	rs.report.recordDuration(fn, duration)
+	rs.listeners.functionEnd(rs.ctx, FunctionEvent{
+		Name:     fn.name,
+		Status:   rs.status[i],
+		Err:      err,
+		Duration: duration,
+		Delay:    delay,
+	})
+	rs.progress.complete()
+
+	if rs.mode.Failure == FailAggregate {
+		rs.errs[i] = err
+		return nil
+	}
+	return err
+}
+
+// resolve marks the outputs of fn as resolved, whether their values were
+// stored or not, and launches the consumers whose inputs are now all resolved.
+// Outputs are resolved at most once. The outputs of a failed function are
+// never resolved: its consumers are not started.
+func (rs *runState) resolve(fn *function) {
+	if rs.resolved[rs.schedule.pos[fn]].Swap(true) {
+		return
+	}
+	for _, out := range fn.outs {
+		for _, i := range rs.schedule.consumers[out.signal] {
+			if rs.pending[i].Add(-1) == 0 {
+				rs.ready(i)
+			}
+		}
+	}
+}
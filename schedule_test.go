@@ -0,0 +1,105 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Schedule(t *testing.T) {
+	type (
+		in      string
+		missing string
+		a       string
+		b       string
+		c       string
+	)
+
+	t.Run("should not start functions before their inputs are resolved", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(ctx context.Context, i in) (a, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			func(v a) b { return b(v) },
+			func(v b) c { return c(v) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var report Report
+		_, err = Run[c](ctx, ngn, in("<in>"), WithReport(&report))
+		assert.True(t, errors.Is(err, context.Canceled))
+		if assert.Len(t, report.Functions, 3) {
+			assert.Equal(t, StatusCancelled, report.Functions[0].Status)
+			assert.Equal(t, StatusNotStarted, report.Functions[1].Status)
+			assert.Equal(t, StatusNotStarted, report.Functions[2].Status)
+		}
+	})
+
+	t.Run("should skip every function downstream of a skipped function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(m missing) a { return a(m) },
+			func(v a) b { return b(v) },
+			func(v b, i in) c { return c(v) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[c](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, c(""), out)
+		for _, fr := range report.Functions {
+			assert.Equal(t, StatusSkipped, fr.Status, fr.Name)
+		}
+	})
+
+	t.Run("should run a function once all of its inputs are resolved", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) a { return a(i + "<a>") },
+			func(i in) b { return b(i + "<b>") },
+			func(x a, y b, z Optional[missing]) c { return c(string(x) + string(y)) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[c](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+		assert.Equal(t, c("<in><a><in><b>"), out)
+	})
+
+	t.Run("should skip functions unreachable from the provided inputs before running any function", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) (a, error) { return "", errors.New("<error>") },
+			func(m missing) b { return b(m) },
+			func(v b) c { return c(v) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		_, err = Run[a](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assertErr(t, err, "<error>")
+		if assert.Len(t, report.Functions, 3) {
+			assert.Equal(t, StatusFailed, report.Functions[0].Status)
+			assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+			assert.Equal(t, StatusSkipped, report.Functions[2].Status)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package warp
+
+import "context"
+
+// Serve continuously runs the engine for every input set received from in and
+// sends the result of each run to out, in the order the input sets were
+// received. A failed run does not stop Serve, its error is reported in the
+// RunResult.
+//
+// Serve returns nil once in is closed and every result has been sent, or
+// ctx.Err() if ctx is done first. Serve does not close out. Several Serve
+// calls may share the same channels to process input sets concurrently.
+func Serve[T any](ctx context.Context, e *Engine, in <-chan []any, out chan<- RunResult[T]) error {
+	for {
+		var (
+			provided []any
+			ok       bool
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case provided, ok = <-in:
+			if !ok {
+				return nil
+			}
+		}
+
+		v, err := Run[T](ctx, e, provided...)
+		result := RunResult[T]{Provided: provided, Value: v, Err: err}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- result:
+		}
+	}
+}
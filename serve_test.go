@@ -0,0 +1,68 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Serve(t *testing.T) {
+	type (
+		in  int
+		out int
+	)
+
+	t.Run("should run the engine for every input set until the input channel is closed", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) (out, error) {
+				if i < 0 {
+					return 0, errors.New("<negative-error>")
+				}
+				return out(i * 2), nil
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inputs := make(chan []any, 3)
+		results := make(chan RunResult[out], 3)
+		inputs <- []any{in(1)}
+		inputs <- []any{in(-1)}
+		inputs <- []any{in(2)}
+		close(inputs)
+
+		assert.NoError(t, Serve(context.Background(), ngn, inputs, results))
+		close(results)
+
+		var got []RunResult[out]
+		for r := range results {
+			got = append(got, r)
+		}
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, RunResult[out]{Provided: []any{in(1)}, Value: 2}, got[0])
+			assertErr(t, got[1].Err, "<negative-error>")
+			assert.Equal(t, RunResult[out]{Provided: []any{in(2)}, Value: 4}, got[2])
+		}
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) out { return out(i) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = Serve(ctx, ngn, make(chan []any), make(chan RunResult[out]))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,162 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ShadowReport describes how the shadow of a Shadow function compared with
+// the primary function during a run.
+type ShadowReport struct {
+	// Diverged is true if the shadow failed while the primary function
+	// succeeded, or if the comparator found their outputs different.
+	Diverged bool
+	// Err is the error returned by the shadow, if any.
+	Err error
+	// Primary and Shadow hold the compared outputs of the primary function
+	// and of the shadow, after redaction. They are nil unless both
+	// succeeded.
+	Primary, Shadow any
+}
+
+// Shadow registers primary, with shadow running alongside it: every call
+// calls both at once, with the same inputs, and returns the outputs of primary
+// only, so that a replacement implementation of a function can be tried out
+// on live traffic before it is switched over.
+//
+// Once both have returned, equal compares their outputs of type T, which must
+// be one of their outputs, and the report of the run gives the outcome as the
+// Shadow of the function. A nil equal compares the outputs with
+// reflect.DeepEqual. The errors of the shadow never fail the run.
+//
+// The outputs of primary are available to the functions requiring them as
+// soon as primary returns, but the function is not done until its shadow has
+// returned too, whether primary succeeds or fails, and its report is only
+// recorded if it succeeds. The shadow is
+// passed the context of the function if it accepts one. Shadow and primary
+// must have the same type and can not return a Cleanup, as the Cleanup of the
+// shadow would not be called.
+func Shadow[T any](primary, shadow any, equal func(primary, shadow T) bool) *Provider {
+	p := asProvider(primary)
+	primaryV, shadowV := reflect.ValueOf(p.fn), reflect.ValueOf(shadow)
+	if p.fn == nil || shadow == nil || primaryV.Kind() != reflect.Func || shadowV.Kind() != reflect.Func {
+		p.err = errors.New("shadow and primary must be functions")
+		return p
+	}
+	p.name, p.location = "shadow "+p.ref(), p.locate()
+
+	fnT := primaryV.Type()
+	if shadowV.Type() != fnT {
+		p.err = fmt.Errorf("shadow must have the type of its primary, %s is not %s", shadowV.Type(), fnT)
+		return p
+	}
+	outs := outputs(fnT)
+	errPos, cmpPos := getPosOfType[error](outs), getPosOfType[T](outs)
+	switch {
+	case cmpPos == -1:
+		p.err = fmt.Errorf("compared type %s is not an output of the shadow", reflect.TypeOf((*T)(nil)).Elem())
+		return p
+	case getPosOfType[Cleanup](outs) != -1:
+		p.err = errors.New("shadow can not return a Cleanup")
+		return p
+	}
+	compare := func(a, b reflect.Value) bool {
+		if equal == nil {
+			return reflect.DeepEqual(a.Interface(), b.Interface())
+		}
+		return equal(a.Interface().(T), b.Interface().(T))
+	}
+
+	// The view function accepts the run context, in first position unless
+	// primary already accepts one
+	ins, ctxPos := inputs(fnT), getPosOfType[context.Context](inputs(fnT))
+	if ctxPos == -1 {
+		ins = append([]reflect.Type{reflect.TypeOf((*context.Context)(nil)).Elem()}, ins...)
+	}
+	viewT := reflect.FuncOf(ins, outs, false)
+	p.fn = reflect.MakeFunc(viewT, func(args []reflect.Value) []reflect.Value {
+		ctx := args[max(ctxPos, 0)].Interface().(context.Context)
+		if ctxPos == -1 {
+			args = args[1:]
+		}
+		sc := shadowCallFrom(ctx)
+		sc.pending.Add(1)
+		shadowOuts := make(chan []reflect.Value, 1)
+		go func() {
+			shadowOuts <- shadowV.Call(args)
+		}()
+
+		out := primaryV.Call(args)
+		go func() {
+			defer sc.pending.Done()
+			sOut := <-shadowOuts
+			err := getError(sOut, errPos)
+			if err != nil || getError(out, errPos) != nil {
+				failed := getError(out, errPos) == nil
+				sc.compared(ShadowReport{Diverged: failed, Err: err}, reflect.Value{}, reflect.Value{})
+				return
+			}
+			diverged := !compare(out[cmpPos], sOut[cmpPos])
+			sc.compared(ShadowReport{Diverged: diverged}, out[cmpPos], sOut[cmpPos])
+		}()
+		return out
+	}).Interface()
+	p.shadowed = true
+	return p
+}
+
+// shadowCall is the comparison of a Shadow function with its shadow during a
+// run, the last one if the function is called several times.
+type shadowCall struct {
+	mu              sync.Mutex
+	called          bool
+	report          ShadowReport
+	primary, shadow reflect.Value
+	// pending counts the comparisons not over yet
+	pending sync.WaitGroup
+}
+
+// compared records the outcome of a comparison.
+func (sc *shadowCall) compared(rec ShadowReport, primary, shadow reflect.Value) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.called, sc.report, sc.primary, sc.shadow = true, rec, primary, shadow
+}
+
+type shadowCallKey struct{}
+
+// withShadowCall returns a copy of ctx carrying sc, filled by the Shadow
+// function called with the context.
+func withShadowCall(ctx context.Context, sc *shadowCall) context.Context {
+	return context.WithValue(ctx, shadowCallKey{}, sc)
+}
+
+// shadowCallFrom returns the shadowCall carried by ctx, or a discarded one if
+// it carries none.
+func shadowCallFrom(ctx context.Context) *shadowCall {
+	if sc, ok := ctx.Value(shadowCallKey{}).(*shadowCall); ok {
+		return sc
+	}
+	return &shadowCall{}
+}
+
+// recordShadow waits for the comparisons of the Shadow fn with its shadow and
+// records the last one, if fn was called. A nil report records nothing.
+func (r *runReport) recordShadow(fn *function, sc *shadowCall) {
+	sc.pending.Wait()
+	if r == nil || !sc.called {
+		return
+	}
+	rec := sc.report
+	if sc.primary.IsValid() {
+		rec.Primary, rec.Shadow = r.redactions.redact(fn, sc.primary), r.redactions.redact(fn, sc.shadow)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fr, ok := r.functions[fn]; ok {
+		fr.Shadow = &rec
+	}
+}
@@ -0,0 +1,162 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Shadow(t *testing.T) {
+	type (
+		order   string
+		price   float64
+		invoice string
+	)
+
+	var (
+		legacy = func(o order) (price, error) { return price(len(o)), nil }
+		render = func(p price) invoice { return invoice("total " + strings.Repeat("$", int(p))) }
+		within = func(a, b price) bool { return a-b < 0.01 && b-a < 0.01 }
+	)
+
+	t.Run("returns the outputs of the primary and reports an agreeing shadow", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Shadow(legacy, func(o order) (price, error) { return price(len(o)) + 0.001, nil }, within),
+			render,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[invoice](context.Background(), ngn, order("abc"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, invoice("total $$$"), out)
+		assert.True(t, strings.HasPrefix(report.Functions[0].Name, "shadow github.com/dezlitz/warp_test.Test_Shadow"))
+		assert.Equal(t, &ShadowReport{Primary: price(3), Shadow: price(3.001)}, report.Functions[0].Shadow)
+		assert.Nil(t, report.Functions[1].Shadow)
+	})
+
+	t.Run("reports a diverging shadow", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			Shadow[price](legacy, func(o order) (price, error) { return 4, nil }, nil),
+			render,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[invoice](context.Background(), ngn, order("abc"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, invoice("total $$$"), out)
+		assert.Equal(t, &ShadowReport{Diverged: true, Primary: price(3), Shadow: price(4)}, report.Functions[0].Shadow)
+	})
+
+	t.Run("reports a failing shadow without failing the run", func(t *testing.T) {
+		t.Parallel()
+		shadowErr := errors.New("shadow failed")
+		ngn, err := Initialize(
+			Shadow(legacy, func(o order) (price, error) { return 0, shadowErr }, within),
+			render,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[invoice](context.Background(), ngn, order("abc"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, invoice("total $$$"), out)
+		assert.Equal(t, &ShadowReport{Diverged: true, Err: shadowErr}, report.Functions[0].Shadow)
+	})
+
+	t.Run("passes its outputs downstream before the shadow returns", func(t *testing.T) {
+		t.Parallel()
+		var (
+			rendered = make(chan struct{})
+			returned = make(chan struct{})
+		)
+		ngn, err := Initialize(
+			Shadow(legacy, func(o order) (price, error) {
+				<-rendered
+				close(returned)
+				return 3, nil
+			}, within),
+			func(p price) invoice {
+				close(rendered)
+				return render(p)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report Report
+		out, err := Run[invoice](context.Background(), ngn, order("abc"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, invoice("total $$$"), out)
+		assert.False(t, report.Functions[0].Shadow.Diverged)
+		select {
+		case <-returned:
+		default:
+			t.Fatal("run returned before the shadow")
+		}
+	})
+
+	t.Run("waits for the shadow when the primary fails", func(t *testing.T) {
+		t.Parallel()
+		primaryErr := errors.New("primary failed")
+		returned := make(chan struct{})
+		ngn, err := Initialize(
+			Shadow(func(o order) (price, error) { return 0, primaryErr }, func(o order) (price, error) {
+				time.Sleep(10 * time.Millisecond)
+				close(returned)
+				return 3, nil
+			}, within),
+			render,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[invoice](context.Background(), ngn, order("abc"))
+		assert.ErrorIs(t, err, primaryErr)
+		select {
+		case <-returned:
+		default:
+			t.Fatal("run returned before the shadow")
+		}
+	})
+
+	t.Run("fails to initialize", func(t *testing.T) {
+		t.Parallel()
+		tests := map[string]struct {
+			provider *Provider
+			err      string
+		}{
+			"shadow not a function": {Shadow(legacy, "legacy", within), "shadow and primary must be functions"},
+			"types differ":          {Shadow(legacy, func(o order) price { return 0 }, within), "shadow must have the type of its primary"},
+			"compared type missing": {Shadow(legacy, legacy, func(a, b invoice) bool { return a == b }), "compared type warp_test.invoice is not an output of the shadow"},
+			"cleanup output": {
+				Shadow[price](func(o order) (price, Cleanup) { return 0, nil }, func(o order) (price, Cleanup) { return 0, nil }, nil),
+				"shadow can not return a Cleanup",
+			},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				_, err := Initialize(tt.provider)
+				assertErrContains(t, err, tt.err)
+			})
+		}
+	})
+}
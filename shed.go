@@ -0,0 +1,92 @@
+package warp
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultShedTag is the tag of the functions skipped by degraded runs when the
+// ShedPolicy does not name one.
+const DefaultShedTag = "sheddable"
+
+// ShedPolicy describes when the engine is overloaded and how runs degrade
+// while it is. Register it with WithLoadShedding.
+type ShedPolicy struct {
+	// MaxInFlight is the number of in-flight runs above which the engine is
+	// overloaded. Zero disables the check.
+	MaxInFlight int
+	// LatencySLO is the moving average of the run durations above which the
+	// engine is overloaded. Zero disables the check.
+	LatencySLO time.Duration
+	// Tag marks the functions skipped by degraded runs, DefaultShedTag if
+	// empty.
+	Tag string
+	// FunctionTimeout bounds the duration of every function of a degraded
+	// run. Zero leaves the functions unbounded.
+	FunctionTimeout time.Duration
+}
+
+// WithLoadShedding makes the engine degrade the runs started while it is
+// overloaded, as described by p: a degraded run skips the functions tagged
+// p.Tag, as if their inputs were missing, and cancels the functions running
+// longer than p.FunctionTimeout. The report of a degraded run is marked as
+// such.
+func WithLoadShedding(p ShedPolicy) Option {
+	return func(c *config) {
+		if p.Tag == "" {
+			p.Tag = DefaultShedTag
+		}
+		c.shed = &shedder{policy: p}
+	}
+}
+
+// shedder tracks the load of an engine.
+type shedder struct {
+	policy ShedPolicy
+	// latency is the exponentially weighted moving average of the run
+	// durations, in nanoseconds
+	latency atomic.Int64
+}
+
+// overloaded reports whether runs started now must be degraded. A nil shedder
+// is never overloaded.
+func (s *shedder) overloaded(inflight int) bool {
+	if s == nil {
+		return false
+	}
+	if s.policy.MaxInFlight > 0 && inflight > s.policy.MaxInFlight {
+		return true
+	}
+	return s.policy.LatencySLO > 0 && time.Duration(s.latency.Load()) > s.policy.LatencySLO
+}
+
+// observe adds the duration of a run to the moving average. A nil shedder
+// observes nothing.
+func (s *shedder) observe(d time.Duration) {
+	if s == nil {
+		return
+	}
+	for {
+		old := s.latency.Load()
+		next := int64(d)
+		if old != 0 {
+			next = old + (int64(d)-old)/5
+		}
+		if s.latency.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// shed reports whether fn is skipped by a degraded run.
+func (s *shedder) shed(fn *function) bool {
+	return slices.Contains(fn.tags, s.policy.Tag)
+}
+
+// inflightRuns returns the number of in-flight runs.
+func (e *Engine) inflightRuns() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.inflight)
+}
@@ -0,0 +1,90 @@
+package warp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithLoadShedding(t *testing.T) {
+	type (
+		in           string
+		block        struct{ started, release chan struct{} }
+		page         string
+		suggestions  string
+		enrichedPage string
+	)
+
+	newEngine := func(t *testing.T, p ShedPolicy) *Engine {
+		ngn, err := Initialize(
+			func(i in, b Optional[block]) page {
+				if b.IsSet {
+					close(b.Val.started)
+					<-b.Val.release
+				}
+				return page(i)
+			},
+			Tag(func(p page) suggestions { return "<suggestions>" }, DefaultShedTag),
+			func(p page, s Optional[suggestions]) enrichedPage { return enrichedPage(string(p) + string(s.Val)) },
+			WithLoadShedding(p),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	t.Run("should skip sheddable functions while too many runs are in flight", func(t *testing.T) {
+		t.Parallel()
+		ngn := newEngine(t, ShedPolicy{MaxInFlight: 1})
+
+		var report Report
+		out, err := Run[enrichedPage](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, enrichedPage("<in><suggestions>"), out)
+		assert.False(t, report.Degraded)
+
+		b := block{started: make(chan struct{}), release: make(chan struct{})}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = Run[enrichedPage](context.Background(), ngn, in("<blocked>"), b)
+		}()
+		<-b.started
+
+		out, err = Run[enrichedPage](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, enrichedPage("<in>"), out)
+		assert.True(t, report.Degraded)
+		if assert.Len(t, report.Functions, 3) {
+			assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+			assert.Equal(t, "run degraded", report.Functions[1].SkipReason)
+		}
+
+		close(b.release)
+		<-done
+	})
+
+	t.Run("should degrade runs while the latency is above the SLO", func(t *testing.T) {
+		t.Parallel()
+		ngn := newEngine(t, ShedPolicy{LatencySLO: time.Millisecond})
+
+		b := block{started: make(chan struct{}), release: make(chan struct{})}
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			close(b.release)
+		}()
+		_, err := Run[enrichedPage](context.Background(), ngn, in("<slow>"), b)
+		assert.NoError(t, err)
+
+		var report Report
+		out, err := Run[enrichedPage](context.Background(), ngn, in("<in>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, enrichedPage("<in>"), out)
+		assert.True(t, report.Degraded)
+	})
+}
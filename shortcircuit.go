@@ -0,0 +1,63 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+)
+
+// errTargetProduced is the cause of the context of the functions of a run
+// cancelled by WithShortCircuit.
+var errTargetProduced = errors.New("target produced")
+
+// WithShortCircuit cancels the run as soon as its target is produced, or every
+// output it collects under WithCollect, instead of running every function that
+// can run, so that a "first answer wins" lookup returns without waiting for the
+// branches it no longer needs.
+//
+// The functions still running get their context cancelled and the functions
+// not started yet are not called; they are reported as cancelled and their
+// errors are ignored. The run returns once the running functions have
+// returned, so a function that ignores its context still delays it. Cleanups
+// and teardowns are called as usual.
+func WithShortCircuit() RunOption {
+	return func(c *runConfig) {
+		c.shortCircuit = true
+	}
+}
+
+// withShortCircuit returns a copy of ctx cancelled by rs once the target of
+// the run is produced, if the run is made WithShortCircuit.
+func (rs *runState) withShortCircuit(ctx context.Context, cfg *runConfig) (context.Context, context.CancelCauseFunc) {
+	if !cfg.shortCircuit {
+		return ctx, func(error) {}
+	}
+	ctx, rs.shortCircuit = context.WithCancelCause(ctx)
+	rs.unproduced.Store(int32(len(rs.outputs)))
+	return ctx, rs.shortCircuit
+}
+
+// produced cancels the run if it is made WithShortCircuit and fn, which
+// succeeded, produces the last of its outputs not produced yet.
+func (rs *runState) produced(fn *function) {
+	if rs.shortCircuit == nil {
+		return
+	}
+	for _, outT := range fn.valueOutputs() {
+		if rs.returns(outT) && rs.unproduced.Add(-1) == 0 {
+			rs.shortCircuit(errTargetProduced)
+		}
+	}
+}
+
+// returns reports whether values of type t are returned by the run.
+func (rs *runState) returns(t reflect.Type) bool {
+	return slices.Contains(rs.outputs, t)
+}
+
+// shortCircuited reports whether the run was cancelled because its target was
+// produced.
+func (rs *runState) shortCircuited() bool {
+	return rs.shortCircuit != nil && errors.Is(context.Cause(rs.ctx), errTargetProduced)
+}
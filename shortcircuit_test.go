@@ -0,0 +1,91 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithShortCircuit(t *testing.T) {
+	type (
+		query   string
+		fast    string
+		slow    string
+		answer  string
+		audited string
+	)
+
+	// newEngine returns an engine whose answer is produced from the fast
+	// lookup once the slow lookup has started, while the slow lookup waits
+	// for its context to be cancelled
+	newEngine := func(t *testing.T, slowErr chan<- error) *Engine {
+		started := make(chan struct{})
+		ngn, err := Initialize(
+			func(q query) fast { <-started; return fast(q) },
+			func(ctx context.Context, q query) (slow, error) {
+				close(started)
+				<-ctx.Done()
+				slowErr <- ctx.Err()
+				return "", ctx.Err()
+			},
+			func(f fast) answer { return answer(f) },
+			func(s slow) audited { return audited(s) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	t.Run("should cancel the branches still running once the target is produced", func(t *testing.T) {
+		t.Parallel()
+		slowErr := make(chan error, 1)
+		ngn := newEngine(t, slowErr)
+
+		var report Report
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := Run[answer](ctx, ngn, query("<q>"), WithShortCircuit(), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, answer("<q>"), out)
+		assert.ErrorIs(t, <-slowErr, context.Canceled)
+		assert.NoError(t, ctx.Err())
+		for _, fr := range report.Functions {
+			if fr.Outputs[0] == reflect.TypeOf(slow("")) {
+				assert.Equal(t, StatusCancelled, fr.Status)
+			}
+		}
+	})
+
+	t.Run("should wait for every branch without WithShortCircuit", func(t *testing.T) {
+		t.Parallel()
+		slowErr := make(chan error, 1)
+		ngn := newEngine(t, slowErr)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := Run[answer](ctx, ngn, query("<q>"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, <-slowErr, context.DeadlineExceeded)
+	})
+
+	t.Run("should fail the run if a function fails before the target is produced", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(q query) (fast, error) { return "", errors.New("<failed>") },
+			func(f fast) answer { return answer(f) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[answer](context.Background(), ngn, query("<q>"), WithShortCircuit())
+		assertErr(t, err, "<failed>")
+	})
+}
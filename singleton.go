@@ -0,0 +1,114 @@
+package warp
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Singleton marks fn as singleton-scoped. A singleton function is called at
+// most once per engine: the first run that can supply its inputs calls it and
+// every later run reuses the outputs it returned, without waiting for inputs.
+//
+// The engine owns the resources produced by singleton functions. Any Cleanup
+// returned by the function and any output implementing io.Closer is released
+// by Engine.Close instead of at the end of the run.
+func Singleton(fn any) *Provider {
+	p := asProvider(fn)
+	p.singleton = true
+	return p
+}
+
+// singleton caches the outputs of a singleton-scoped function. The outputs
+// are published atomically once produced, so that runs load them without
+// locking.
+type singleton struct {
+	outputs atomic.Pointer[[]reflect.Value]
+	mu      sync.Mutex
+	// producing is closed once the call in progress returns, nil if no call
+	// is in progress
+	producing chan struct{}
+}
+
+func (s *singleton) load() ([]reflect.Value, bool) {
+	if outputs := s.outputs.Load(); outputs != nil {
+		return *outputs, true
+	}
+	return nil, false
+}
+
+// produceSingleton returns the cached outputs of s, calling fn to produce them
+// if no earlier run has done so. Concurrent runs wait for the call in
+// progress, until ctx is done, so that fn is never called twice, and try
+// again if it fails. Outputs are only cached, and their resources only owned
+// by the engine, if fn succeeds.
+func (e *Engine) produceSingleton(
+	ctx context.Context,
+	s *singleton,
+	call func() ([]reflect.Value, error),
+	outputs []reflect.Type,
+	cleanupPos int,
+) ([]reflect.Value, error) {
+	for {
+		if outValues, ok := s.load(); ok {
+			return outValues, nil
+		}
+		s.mu.Lock()
+		producing := s.producing
+		if producing == nil {
+			break
+		}
+		s.mu.Unlock()
+		select {
+		case <-producing:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	// Produced while the lock was not held
+	if outValues, ok := s.load(); ok {
+		s.mu.Unlock()
+		return outValues, nil
+	}
+	done := make(chan struct{})
+	s.producing = done
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.producing = nil
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	outValues, err := call()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, outT := range outputs {
+		switch {
+		case i == cleanupPos:
+			e.own(outValues[i].Interface().(Cleanup))
+		case isValueType(outT) && outT.Implements(reflect.TypeOf((*io.Closer)(nil)).Elem()):
+			if isNil(outValues[i]) {
+				continue
+			}
+			closer := outValues[i].Interface().(io.Closer)
+			e.own(func(context.Context) error { return closer.Close() })
+		}
+	}
+
+	s.outputs.Store(&outValues)
+	return outValues, nil
+}
+
+// isNil reports whether v holds a nil value of a nillable kind.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type closerType struct{ closed *atomic.Int32 }
+
+func (c *closerType) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func Test_EngineSingleton(t *testing.T) {
+	type (
+		config  string
+		client  struct{ cfg config }
+		request string
+		reply   string
+	)
+
+	t.Run("should call a singleton function once and reuse its outputs across runs", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			Singleton(func(cfg config) client {
+				calls.Add(1)
+				return client{cfg}
+			}),
+			func(c client, r request) reply { return reply(string(c.cfg) + string(r)) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[reply](context.Background(), ngn, config("<cfg>"), request("<req1>"))
+		assert.NoError(t, err)
+		assert.Equal(t, reply("<cfg><req1>"), out)
+
+		// the singleton no longer needs its inputs once it has been called
+		out, err = Run[reply](context.Background(), ngn, request("<req2>"))
+		assert.NoError(t, err)
+		assert.Equal(t, reply("<cfg><req2>"), out)
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("should not cache the outputs of a failed singleton function", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			Singleton(func(cfg config) (client, error) {
+				if calls.Add(1) == 1 {
+					return client{}, errors.New("<dial-error>")
+				}
+				return client{cfg}, nil
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[client](context.Background(), ngn, config("<cfg>"))
+		assertErr(t, err, "<dial-error>")
+
+		out, err := Run[client](context.Background(), ngn, config("<cfg>"))
+		assert.NoError(t, err)
+		assert.Equal(t, client{"<cfg>"}, out)
+	})
+
+	t.Run("should not block the other runs while a singleton function is called", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		started, release := make(chan struct{}), make(chan struct{})
+		ngn, err := Initialize(
+			Singleton(func(cfg config) client {
+				calls.Add(1)
+				close(started)
+				<-release
+				return client{cfg}
+			}),
+			func(c client, r request) reply { return reply(string(c.cfg) + string(r)) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first := make(chan error)
+		go func() {
+			_, err := Run[reply](context.Background(), ngn, config("<cfg>"), request("<req1>"))
+			first <- err
+		}()
+		<-started
+
+		// The run waiting for the call in progress gives up at its deadline
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = Run[reply](ctx, ngn, config("<cfg>"), request("<req2>"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		assert.NoError(t, <-first)
+		out, err := Run[reply](context.Background(), ngn, request("<req3>"))
+		assert.NoError(t, err)
+		assert.Equal(t, reply("<cfg><req3>"), out)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("should release singleton resources on close", func(t *testing.T) {
+		t.Parallel()
+		var (
+			closed   atomic.Int32
+			cleaned  atomic.Int32
+			released []string
+		)
+		ngn, err := Initialize(
+			Singleton(func(cfg config) (*closerType, Cleanup) {
+				return &closerType{&closed}, func(context.Context) error {
+					cleaned.Add(1)
+					released = append(released, "cleanup")
+					return nil
+				}
+			}),
+			func(*closerType, request) (reply, Cleanup) {
+				return "", func(context.Context) error {
+					released = append(released, "run")
+					return nil
+				}
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[reply](context.Background(), ngn, config("<cfg>"), request("<req>"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"run"}, released)
+		assert.Equal(t, int32(0), closed.Load())
+
+		assert.NoError(t, ngn.Close())
+		assert.NoError(t, ngn.Close())
+		assert.Equal(t, int32(1), closed.Load())
+		assert.Equal(t, int32(1), cleaned.Load())
+		assert.Equal(t, []string{"run", "cleanup"}, released)
+	})
+
+	t.Run("should return an error when running a closed engine", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(cfg config) client { return client{cfg} },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, ngn.Close())
+
+		_, err = Run[client](context.Background(), ngn, config("<cfg>"))
+		assertErr(t, err, "error running engine that has been closed")
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
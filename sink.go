@@ -0,0 +1,25 @@
+package warp
+
+import "reflect"
+
+// Sink marks fn as a terminal function consuming values without producing
+// any, such as writing an audit log or emitting metrics. A sink returns
+// nothing or only an error, and runs in every run that can supply its
+// inputs. Run waits for the sinks to return and fails with their errors like
+// with those of any other function. Compiled runners prune sinks, as they do
+// not contribute to the target.
+func Sink(fn any) *Provider {
+	p := asProvider(fn)
+	p.sink = true
+	return p
+}
+
+// validateSinkOutputs checks that a sink function returns at most an error.
+func validateSinkOutputs(fnT reflect.Type) error {
+	for _, outT := range outputs(fnT) {
+		if !isType[error](outT) {
+			return invalid(RuleSinkOutputs, "sink must not return values other than an error")
+		}
+	}
+	return nil
+}
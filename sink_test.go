@@ -0,0 +1,82 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Sink(t *testing.T) {
+	type (
+		order   string
+		receipt string
+	)
+
+	t.Run("should run the sink once its inputs are produced", func(t *testing.T) {
+		t.Parallel()
+		var audited atomic.Value
+		ngn, err := Initialize(
+			func(o order) receipt { return receipt("receipt of " + o) },
+			Sink(func(r receipt) { audited.Store(r) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[receipt](context.Background(), ngn, order("<order>"))
+		assert.NoError(t, err)
+		assert.Equal(t, receipt("receipt of <order>"), out)
+		assert.Equal(t, receipt("receipt of <order>"), audited.Load())
+	})
+
+	t.Run("should fail the run with the error of the sink", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(o order) receipt { return receipt(o) },
+			Sink(func(ctx context.Context, r receipt) error { return errors.New("audit log unavailable") }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[receipt](context.Background(), ngn, order("<order>"))
+		assertErr(t, err, "audit log unavailable")
+	})
+
+	t.Run("should not run the sink if its inputs are missing", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		ngn, err := Initialize(
+			func(o order) receipt { return receipt(o) },
+			Sink(func(r receipt) { calls.Add(1) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[receipt](context.Background(), ngn)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("should return a validation error if the sink returns a value", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			Sink(func(o order) (receipt, error) { return receipt(o), nil }),
+		)
+		assertErrContains(t, err, "sink must not return values other than an error")
+	})
+
+	t.Run("should still require plain functions to return a value", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(o order) error { return nil },
+		)
+		assertErrContains(t, err, "must have at least 1 return value type (excluding error)")
+	})
+}
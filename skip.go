@@ -0,0 +1,10 @@
+package warp
+
+import "errors"
+
+// ErrSkip is returned by a function, possibly wrapped, to mean that it ran but
+// can not produce its outputs. The run continues as if the function had been
+// skipped: its outputs are not available, so the functions requiring them are
+// skipped and the Optional inputs they feed are left unset. The function is
+// reported as skipped, with the error it returned.
+var ErrSkip = errors.New("skip")
@@ -0,0 +1,59 @@
+package warp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_ErrSkip(t *testing.T) {
+	type (
+		userID  string
+		profile string
+		avatar  string
+		page    string
+	)
+
+	ngn, err := Initialize(
+		func(id userID) (profile, error) {
+			if id == "<unknown>" {
+				return "", fmt.Errorf("no profile for %s: %w", id, ErrSkip)
+			}
+			return profile("profile of " + id), nil
+		},
+		func(p profile) avatar { return avatar("avatar of " + p) },
+		func(id userID, p Optional[profile]) page {
+			if !p.IsSet {
+				return page("anonymous " + id)
+			}
+			return page(p.Val)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should run normally if the function does not skip", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[page](context.Background(), ngn, userID("<id>"))
+		assert.NoError(t, err)
+		assert.Equal(t, page("profile of <id>"), out)
+	})
+
+	t.Run("should continue the run without the outputs of the skipping function", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		out, err := Run[page](context.Background(), ngn, userID("<unknown>"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, page("anonymous <unknown>"), out)
+
+		assert.Equal(t, StatusSkipped, report.Functions[0].Status)
+		assert.ErrorIs(t, report.Functions[0].Err, ErrSkip)
+		assert.Equal(t, StatusSkipped, report.Functions[1].Status)
+		assert.Equal(t, StatusSucceeded, report.Functions[2].Status)
+	})
+}
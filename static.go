@@ -0,0 +1,84 @@
+package warp
+
+import (
+	"reflect"
+	"sync"
+)
+
+// StaticCall calls a function without reflection. It receives the function
+// arguments in parameter order and returns its results in result order.
+type StaticCall func(args []any) []any
+
+// StaticFactory returns a StaticCall for fn, or nil if it does not know the
+// signature of fn.
+type StaticFactory func(fn any) StaticCall
+
+var static struct {
+	mu        sync.RWMutex
+	factories []StaticFactory
+}
+
+// RegisterStatic registers a factory of static calls. When Initialize finds a
+// factory returning a StaticCall for a function, the engine calls the function
+// through it instead of through reflection.
+//
+// RegisterStatic is called from init functions generated by the warpgen
+// command (github.com/dezlitz/warp/cmd/warpgen) and is rarely useful on its
+// own. Engines initialized before a factory is registered keep using
+// reflection.
+func RegisterStatic(factory StaticFactory) {
+	static.mu.Lock()
+	defer static.mu.Unlock()
+	static.factories = append(static.factories, factory)
+}
+
+// staticCall returns the registered StaticCall of fn, if any.
+func staticCall(fn any) StaticCall {
+	static.mu.RLock()
+	defer static.mu.RUnlock()
+	for _, factory := range static.factories {
+		if call := factory(fn); call != nil {
+			return call
+		}
+	}
+	return nil
+}
+
+// caller returns the function used by the engine to call fnV: its StaticCall if
+// one is registered, reflect.Value.Call otherwise.
+func caller(fnV reflect.Value) func([]reflect.Value) []reflect.Value {
+	call := staticCall(fnV.Interface())
+	if call == nil {
+		return fnV.Call
+	}
+
+	outputs := outputs(fnV.Type())
+	return func(ins []reflect.Value) []reflect.Value {
+		args := make([]any, len(ins))
+		for i, in := range ins {
+			args[i] = in.Interface()
+		}
+
+		results := call(args)
+		outValues := make([]reflect.Value, len(outputs))
+		for i, outT := range outputs {
+			outValues[i] = valueOf(outT, results[i])
+		}
+		return outValues
+	}
+}
+
+// valueOf returns v as a reflect.Value of type t, as reflect.Value.Call would,
+// so that nil interfaces and interface typed results keep their declared type.
+func valueOf(t reflect.Type, v any) reflect.Value {
+	if v == nil {
+		return reflect.Zero(t)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type() != t {
+		typed := reflect.New(t).Elem()
+		typed.Set(rv)
+		return typed
+	}
+	return rv
+}
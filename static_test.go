@@ -0,0 +1,72 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type (
+	staticIn  string
+	staticOut string
+	staticErr struct{ error }
+)
+
+var (
+	registerStaticOnce sync.Once
+	staticCalls        atomic.Int32
+)
+
+func Test_RegisterStatic(t *testing.T) {
+	// Factories can not be unregistered, register once across test repetitions
+	registerStaticOnce.Do(func() {
+		RegisterStatic(func(fn any) StaticCall {
+			switch fn := fn.(type) {
+			case func(context.Context, staticIn) (staticOut, error):
+				return func(args []any) []any {
+					staticCalls.Add(1)
+					a0, _ := args[0].(context.Context)
+					a1, _ := args[1].(staticIn)
+					r0, r1 := fn(a0, a1)
+					return []any{r0, r1}
+				}
+			}
+			return nil
+		})
+	})
+
+	t.Run("should call functions through their registered static call", func(t *testing.T) {
+		ngn, err := Initialize(
+			func(_ context.Context, in staticIn) (staticOut, error) { return staticOut(in + "<out>"), nil },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		before := staticCalls.Load()
+		out, err := Run[staticOut](context.Background(), ngn, staticIn("<in>"))
+		assert.NoError(t, err)
+		assert.Equal(t, staticOut("<in><out>"), out)
+		assert.Equal(t, before+1, staticCalls.Load())
+	})
+
+	t.Run("should return the error of a static call", func(t *testing.T) {
+		ngn, err := Initialize(
+			func(_ context.Context, in staticIn) (staticOut, error) {
+				return "", staticErr{errors.New("<static-error>")}
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[staticOut](context.Background(), ngn, staticIn("<in>"))
+		assertErr(t, err, "<static-error>")
+	})
+}
@@ -0,0 +1,90 @@
+package warp
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// statsWindow is the number of the latest calls of a function its latency
+// percentiles are computed over.
+const statsWindow = 1024
+
+// FunctionStats aggregates the calls of a function across the runs of an
+// engine.
+type FunctionStats struct {
+	// Invocations is the number of calls of the function that returned,
+	// successfully or not.
+	Invocations int64 `json:"invocations"`
+	// Errors is the number of calls of the function that failed.
+	Errors int64 `json:"errors"`
+	// P50 and P99 are the median and the 99th percentile durations of the
+	// latest calls of the function.
+	P50 time.Duration `json:"p50"`
+	P99 time.Duration `json:"p99"`
+}
+
+// Stats returns the statistics of the functions that have been called, keyed
+// by function name as in reports, so capacity planning does not require an
+// external metrics sink. Skipped and cancelled functions and cached singletons
+// are not counted. The percentiles are computed over the latest 1024 calls of
+// every function.
+func (e *Engine) Stats() map[string]FunctionStats {
+	out := map[string]FunctionStats{}
+	if e == nil {
+		return out
+	}
+	for _, fn := range e.funcs {
+		if s, ok := fn.stats.snapshot(); ok {
+			out[fn.name] = s
+		}
+	}
+	return out
+}
+
+// functionStats accumulates the statistics of a function.
+type functionStats struct {
+	mu          sync.Mutex
+	invocations int64
+	errors      int64
+	// durations holds the latest durations, a ring buffer written at next
+	durations []time.Duration
+	next      int
+}
+
+func (s *functionStats) observe(d time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invocations++
+	if failed {
+		s.errors++
+	}
+	if len(s.durations) < statsWindow {
+		s.durations = append(s.durations, d)
+		return
+	}
+	s.durations[s.next] = d
+	s.next = (s.next + 1) % statsWindow
+}
+
+func (s *functionStats) snapshot() (FunctionStats, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.invocations == 0 {
+		return FunctionStats{}, false
+	}
+	sorted := slices.Clone(s.durations)
+	slices.Sort(sorted)
+	return FunctionStats{
+		Invocations: s.invocations,
+		Errors:      s.errors,
+		P50:         percentile(sorted, 0.50),
+		P99:         percentile(sorted, 0.99),
+	}, true
+}
+
+// percentile returns the nearest-rank percentile q of the sorted durations.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	rank := int(q*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
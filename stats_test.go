@@ -0,0 +1,66 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Stats(t *testing.T) {
+	type (
+		in  int
+		a   int
+		out int
+	)
+
+	t.Run("should aggregate the calls of the functions across runs", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(i in) (a, error) {
+				time.Sleep(time.Duration(i) * time.Millisecond)
+				if i == 0 {
+					return 0, errors.New("<failed>")
+				}
+				return a(i), nil
+			},
+			func(a a) out { return out(a) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, i := range []in{0, 1, 2, 3, 4} {
+			_, _ = Run[out](context.Background(), ngn, i)
+		}
+
+		stats := ngn.Stats()
+		if assert.Len(t, stats, 2) {
+			for name, s := range stats {
+				if strings.Contains(name, "Test_Stats.func1.1") {
+					assert.Equal(t, int64(5), s.Invocations)
+					assert.Equal(t, int64(1), s.Errors)
+					assert.GreaterOrEqual(t, s.P50, 2*time.Millisecond)
+					assert.GreaterOrEqual(t, s.P99, 4*time.Millisecond)
+				} else {
+					assert.Equal(t, int64(4), s.Invocations)
+					assert.Zero(t, s.Errors)
+				}
+			}
+		}
+	})
+
+	t.Run("should not report the functions that were never called", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(i in) a { return a(i) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, ngn.Stats())
+	})
+}
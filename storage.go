@@ -0,0 +1,116 @@
+package warp
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// valueIndex assigns a dense slot to every type produced or consumed by the
+// functions of an engine, so that the values of a run are stored in a slice
+// rather than in a map keyed by type. The slot of a produced type is its
+// signal. See compilePlan.
+type valueIndex struct {
+	slots map[reflect.Type]int
+	// types holds the type of every slot
+	types []reflect.Type
+}
+
+// valueStore holds the values of a run, by unwrapped type. The values of the
+// indexed types are kept in their slot, the values of the other types, only
+// ever provided, in a map.
+//
+// A slot is written at most once per run, before the functions consuming it
+// are launched: its value is published by the atomic set flag, so that slots
+// are read and written without locks.
+type valueStore struct {
+	index  *valueIndex
+	values []valueSlot
+	mu     sync.Mutex
+	other  map[reflect.Type]reflect.Value
+}
+
+type valueSlot struct {
+	v   reflect.Value
+	set atomic.Bool
+}
+
+func newValueStore(index *valueIndex) *valueStore {
+	return &valueStore{index: index, values: make([]valueSlot, len(index.types))}
+}
+
+// clear removes the values of the run, so that the store can be reused by
+// the next one.
+func (s *valueStore) clear() {
+	for slot := range s.values {
+		s.values[slot].set.Store(false)
+		s.values[slot].v = reflect.Value{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.other)
+}
+
+// load returns the value of the parameter in, by its slot if it has one.
+func (s *valueStore) load(in inputPlan) (reflect.Value, bool) {
+	if in.slot >= 0 {
+		return s.loadSlot(in.slot)
+	}
+	return s.Load(in.key)
+}
+
+// Load returns the value of type key.
+func (s *valueStore) Load(key reflect.Type) (reflect.Value, bool) {
+	if slot, ok := s.index.slots[key]; ok {
+		return s.loadSlot(slot)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.other[key]
+	return v, ok
+}
+
+func (s *valueStore) loadSlot(slot int) (reflect.Value, bool) {
+	if !s.values[slot].set.Load() {
+		return reflect.Value{}, false
+	}
+	return s.values[slot].v, true
+}
+
+// Store stores v as the value of type key.
+func (s *valueStore) Store(key reflect.Type, v reflect.Value) {
+	if slot, ok := s.index.slots[key]; ok {
+		s.storeSlot(slot, v)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.other == nil {
+		s.other = map[reflect.Type]reflect.Value{}
+	}
+	s.other[key] = v
+}
+
+func (s *valueStore) storeSlot(slot int, v reflect.Value) {
+	s.values[slot].v = v
+	s.values[slot].set.Store(true)
+}
+
+// Range calls f for every stored value, the indexed types first in slot
+// order, until f returns false.
+func (s *valueStore) Range(f func(key reflect.Type, v reflect.Value) bool) {
+	for slot := range s.values {
+		if v, ok := s.loadSlot(slot); ok && !f(s.index.types[slot], v) {
+			return
+		}
+	}
+	s.mu.Lock()
+	other := maps.Clone(s.other)
+	s.mu.Unlock()
+	for key, v := range other {
+		if !f(key, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunStorage(t *testing.T) {
+	type (
+		in      int
+		out     int
+		unknown string
+	)
+
+	ngn, err := Initialize(
+		func(i in) out { return out(i + 1) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("keeps the provided values no function consumes", func(t *testing.T) {
+		t.Parallel()
+		var report Report
+		v, err := Run[out](context.Background(), ngn, in(1), unknown("kept"), WithReport(&report))
+		assert.NoError(t, err)
+		assert.Equal(t, out(2), v)
+		assert.Equal(t, map[reflect.Type]any{reflect.TypeFor[in](): in(1), reflect.TypeFor[unknown](): unknown("kept")}, report.Provided)
+	})
+
+	t.Run("stores the values of every run apart", func(t *testing.T) {
+		t.Parallel()
+		for i := range 3 {
+			v, err := Run[out](context.Background(), ngn, in(i))
+			assert.NoError(t, err)
+			assert.Equal(t, out(i+1), v)
+		}
+		v, err := Run[out](context.Background(), ngn)
+		assert.NoError(t, err)
+		assert.Zero(t, v)
+	})
+
+	t.Run("stores the unset Optional values provided", func(t *testing.T) {
+		t.Parallel()
+		v, err := Run[out](context.Background(), ngn, Optional[in]{})
+		assert.NoError(t, err)
+		assert.Zero(t, v)
+
+		v, err = Run[out](context.Background(), ngn, Optional[in]{Val: 2, IsSet: true})
+		assert.NoError(t, err)
+		assert.Equal(t, out(3), v)
+	})
+}
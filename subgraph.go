@@ -0,0 +1,52 @@
+package warp
+
+import (
+	"reflect"
+)
+
+// Subgraph returns a new engine made of the functions producing the target
+// types and of every function they transitively depend on, with the options
+// of e. It is validated like any engine, so a big application graph can be
+// cut down into a lightweight engine for a test or a command line tool.
+// Optional targets are unwrapped.
+//
+// The new engine shares nothing with e at runtime: it caches its own
+// singletons and is closed on its own. Its interface bindings are made again
+// among the functions it keeps, see WithInterfaceBinding.
+//
+// An error is returned if e has not been initialized or if no function of e
+// produces one of the targets.
+func (e *Engine) Subgraph(targets ...reflect.Type) (*Engine, error) {
+	if e == nil || !e.initialized {
+		return nil, categorize(ErrNotInitialized, "error extracting subgraph of engine that has not been initialized")
+	}
+
+	roots := make([]*function, 0, len(targets))
+	for _, target := range targets {
+		if target == nil {
+			return nil, categorize(ErrTargetNotProducible, "target <nil> does not match any function output types")
+		}
+		target, _ = unwrapOptional(target)
+		producer, ok := e.producers[target]
+		if !ok {
+			return nil, categorize(ErrTargetNotProducible, "output type %s does not match any function output types", target)
+		}
+		roots = append(roots, producer)
+	}
+
+	args := []any{withConfig(e.cfg)}
+	for _, fn := range e.withUpstream(roots) {
+		// Interface bindings have no source, they are bound again
+		if src := e.sources[e.schedule.pos[fn]]; src != nil {
+			args = append(args, src)
+		}
+	}
+	return Initialize(args...)
+}
+
+// withConfig applies the settings of cfg.
+func withConfig(cfg *config) Option {
+	return func(c *config) {
+		*c = *cfg
+	}
+}
@@ -0,0 +1,61 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_Subgraph(t *testing.T) {
+	type (
+		config   string
+		database string
+		cache    string
+		users    string
+		reports  string
+	)
+
+	var calls atomic.Int32
+	ngn, err := Initialize(
+		func(c config) database { return database("database " + c) },
+		func(c config) cache { calls.Add(1); return cache("cache " + c) },
+		Singleton(func(d database) users { return users("users of " + d) }),
+		func(d database, c cache) reports { return reports("reports of " + d) },
+		Default(config("<config>")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should only keep the functions the targets depend on", func(t *testing.T) {
+		t.Parallel()
+		sub, err := ngn.Subgraph(reflect.TypeOf(Optional[users]{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := Run[users](context.Background(), sub)
+		assert.NoError(t, err)
+		assert.Equal(t, users("users of database <config>"), out)
+		assert.Len(t, sub.Functions(), 2)
+		assert.Zero(t, calls.Load())
+
+		_, err = Run[reports](context.Background(), sub)
+		assert.ErrorIs(t, err, ErrTargetNotProducible)
+	})
+
+	t.Run("should return an error for a target no function produces", func(t *testing.T) {
+		t.Parallel()
+		_, err := ngn.Subgraph(reflect.TypeOf(""))
+		assert.ErrorIs(t, err, ErrTargetNotProducible)
+		assertErr(t, err, "output type string does not match any function output types")
+
+		_, err = (&Engine{}).Subgraph(reflect.TypeOf(users("")))
+		assert.ErrorIs(t, err, ErrNotInitialized)
+	})
+}
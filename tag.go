@@ -0,0 +1,80 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Tag labels fn with one or more tags. Tags select the functions run by
+// RunTagged, so a single engine can host several workflows.
+func Tag(fn any, tags ...string) *Provider {
+	p := asProvider(fn)
+	p.tags = append(p.tags, tags...)
+	return p
+}
+
+// RunTagged works like Run but only executes the functions tagged with tag,
+// plus the functions they transitively depend on for their inputs. Every other
+// function is ignored for this run.
+//
+// An error is returned if no function carries the tag, and an error wrapping
+// ErrTargetNotProducible if none of the functions run produces T.
+func RunTagged[T any](ctx context.Context, e *Engine, tag string, provided ...any) (T, error) {
+	var out T
+	if e == nil || !e.initialized {
+		return out, categorize(ErrNotInitialized, "error running engine that has not been initialized")
+	}
+
+	funcs := e.tagged(tag)
+	if len(funcs) == 0 {
+		return out, fmt.Errorf("no function is tagged %q", tag)
+	}
+
+	return run[T](ctx, e, newSchedule(funcs, e.numSignals), provided)
+}
+
+// tagged returns the functions tagged with tag together with their upstream
+// functions, in registration order.
+func (e *Engine) tagged(tag string) []*function {
+	var roots []*function
+	for _, fn := range e.funcs {
+		if slices.Contains(fn.tags, tag) {
+			roots = append(roots, fn)
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+
+	return e.withUpstream(roots)
+}
+
+// withUpstream returns the given functions together with every function they
+// transitively depend on, in registration order.
+func (e *Engine) withUpstream(roots []*function) []*function {
+	selected := map[*function]bool{}
+	var visit func(fn *function)
+	visit = func(fn *function) {
+		if selected[fn] {
+			return
+		}
+		selected[fn] = true
+		for _, in := range fn.ins {
+			if producer, ok := e.producers[in.key]; ok {
+				visit(producer)
+			}
+		}
+	}
+	for _, fn := range roots {
+		visit(fn)
+	}
+
+	out := make([]*function, 0, len(selected))
+	for _, fn := range e.funcs {
+		if selected[fn] {
+			out = append(out, fn)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package warp_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_RunTagged(t *testing.T) {
+	type (
+		source   string
+		document string
+		index    string
+		response string
+	)
+
+	t.Run("should only run tagged functions and their upstream functions", func(t *testing.T) {
+		t.Parallel()
+		var served, indexed atomic.Int32
+		ngn, err := Initialize(
+			func(s source) document { return document(s + "<doc>") },
+			Tag(func(d document) index {
+				indexed.Add(1)
+				return index(d + "<index>")
+			}, "reindex"),
+			Tag(func(d document) response {
+				served.Add(1)
+				return response(d + "<response>")
+			}, "serve"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := RunTagged[index](context.Background(), ngn, "reindex", source("<src>"))
+		assert.NoError(t, err)
+		assert.Equal(t, index("<src><doc><index>"), out)
+		assert.Equal(t, int32(1), indexed.Load())
+		assert.Equal(t, int32(0), served.Load())
+
+		resp, err := RunTagged[response](context.Background(), ngn, "serve", source("<src>"))
+		assert.NoError(t, err)
+		assert.Equal(t, response("<src><doc><response>"), resp)
+		assert.Equal(t, int32(1), indexed.Load())
+		assert.Equal(t, int32(1), served.Load())
+	})
+
+	t.Run("should return an error if the target is not produced by the tagged functions", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(s source) document { return document(s) },
+			Tag(func(d document) index { return index(d) }, "reindex"),
+			Tag(func(d document) response { return response(d) }, "serve"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RunTagged[response](context.Background(), ngn, "reindex", source("<src>"))
+		assert.ErrorIs(t, err, ErrTargetNotProducible)
+		assertErrContains(t, err, "target warp_test.response can not be produced: no function of the run produces it")
+	})
+
+	t.Run("should return an error if no function carries the tag", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			func(s source) document { return document(s) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RunTagged[document](context.Background(), ngn, "backfill", source("<src>"))
+		assertErr(t, err, `no function is tagged "backfill"`)
+	})
+}
@@ -0,0 +1,88 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Teardown is a callback registered with OnRunEnd. It is called once the run
+// has finished with the error of the run, nil if it succeeded, so that it can
+// commit or roll back what its function did.
+type Teardown func(ctx context.Context, runErr error) error
+
+// WithTeardown lets the functions of the run register Teardown callbacks with
+// OnRunEnd. The callbacks are called once all functions have returned, in the
+// reverse order they were registered, whether the run succeeded or failed, so
+// that the side effects of a run can be made transactional: a function
+// registers the rollback of its changes, which undoes them after the changes
+// of the functions depending on it are undone.
+//
+// Teardowns are called before the Cleanup functions, with a context that is
+// not cancelled with the run. All of them are called even if some fail; their
+// errors are joined with the run error.
+func WithTeardown() RunOption {
+	return func(c *runConfig) {
+		c.teardown = true
+	}
+}
+
+// OnRunEnd registers fn to be called once the run carried by ctx, the context
+// of a function, has finished. It reports whether fn was registered, false if
+// ctx does not belong to a run made with WithTeardown. A nested run only
+// registers its own teardowns.
+func OnRunEnd(ctx context.Context, fn Teardown) bool {
+	t, _ := ctx.Value(teardownsKey{}).(*teardowns)
+	if t == nil || fn == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fns = append(t.fns, fn)
+	return true
+}
+
+type teardownsKey struct{}
+
+// teardowns collects the Teardown callbacks registered during a single run in
+// the order they were registered.
+type teardowns struct {
+	mu  sync.Mutex
+	fns []Teardown
+}
+
+// withTeardowns returns a copy of ctx carrying the teardowns of a run made
+// with WithTeardown, nil otherwise. A copy is only made if the run collects
+// teardowns or if ctx carries the teardowns of an enclosing run, which must
+// not collect those of the nested run.
+func withTeardowns(ctx context.Context, cfg *runConfig) (context.Context, *teardowns) {
+	var t *teardowns
+	if cfg.teardown {
+		t = &teardowns{}
+	} else if ctx.Value(teardownsKey{}) == nil {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, teardownsKey{}, t), t
+}
+
+// run calls the collected teardowns in reverse registration order with the
+// run error, and ctx stripped of its cancellation. A nil teardowns does
+// nothing.
+func (t *teardowns) run(ctx context.Context, runErr error) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	fns := t.fns
+	t.fns = nil
+	t.mu.Unlock()
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](ctx, runErr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,109 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WithTeardown(t *testing.T) {
+	type (
+		order   string
+		payment string
+		receipt string
+	)
+
+	// journal records the calls of the teardowns and of the cleanups
+	type journal struct {
+		mu      sync.Mutex
+		entries []string
+	}
+	record := func(j *journal, entry string) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.entries = append(j.entries, entry)
+	}
+	teardown := func(j *journal, name string) Teardown {
+		return func(_ context.Context, runErr error) error {
+			if runErr != nil {
+				record(j, "rollback "+name)
+			} else {
+				record(j, "commit "+name)
+			}
+			return nil
+		}
+	}
+	newEngine := func(t *testing.T, j *journal, fail error) *Engine {
+		ngn, err := Initialize(
+			func(ctx context.Context, o order) payment {
+				OnRunEnd(ctx, teardown(j, "payment"))
+				return payment(o)
+			},
+			func(ctx context.Context, p payment) (receipt, Cleanup, error) {
+				OnRunEnd(ctx, teardown(j, "receipt"))
+				return receipt(p), func(context.Context) error { record(j, "cleanup"); return nil }, fail
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ngn
+	}
+
+	t.Run("should call the teardowns in reverse order after a successful run", func(t *testing.T) {
+		t.Parallel()
+		var j journal
+		ngn := newEngine(t, &j, nil)
+
+		out, err := Run[receipt](context.Background(), ngn, order("<order>"), WithTeardown())
+		assert.NoError(t, err)
+		assert.Equal(t, receipt("<order>"), out)
+		assert.Equal(t, []string{"commit receipt", "commit payment", "cleanup"}, j.entries)
+	})
+
+	t.Run("should call the teardowns with the error of a failed run", func(t *testing.T) {
+		t.Parallel()
+		var j journal
+		ngn := newEngine(t, &j, errors.New("<declined>"))
+
+		_, err := Run[receipt](context.Background(), ngn, order("<order>"), WithTeardown())
+		assertErr(t, err, "<declined>")
+		assert.Equal(t, []string{"rollback receipt", "rollback payment"}, j.entries)
+	})
+
+	t.Run("should join the errors of the teardowns with the run error", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(func(ctx context.Context, o order) payment {
+			OnRunEnd(ctx, func(context.Context, error) error { return errors.New("<first>") })
+			OnRunEnd(ctx, func(context.Context, error) error { return errors.New("<second>") })
+			return payment(o)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[payment](context.Background(), ngn, order("<order>"), WithTeardown())
+		assertErr(t, err, "<second>\n<first>")
+	})
+
+	t.Run("should not register teardowns without WithTeardown", func(t *testing.T) {
+		t.Parallel()
+		var registered bool
+		ngn, err := Initialize(func(ctx context.Context, o order) payment {
+			registered = OnRunEnd(ctx, func(context.Context, error) error { return nil })
+			return payment(o)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[payment](context.Background(), ngn, order("<order>"))
+		assert.NoError(t, err)
+		assert.False(t, registered)
+	})
+}
@@ -0,0 +1,68 @@
+package warp
+
+// F0 registers f, a function of no parameters returning a single value.
+//
+// The typed registration helpers wrap the functions passed to Initialize so
+// that the shape of their signatures is checked by the compiler: FN registers
+// a function of N parameters returning a single value, FNE a function of N
+// parameters returning a value and an error. A function returning nothing, or
+// more values than one and an error, then fails to compile in user code
+// instead of failing Initialize:
+//
+//	warp.Initialize(
+//		warp.F1(NewConfig),
+//		warp.F2E(NewServer),
+//	)
+//
+// The rules the compiler can not check, such as a parameter type repeated or
+// also returned, are still validated by Initialize. The helpers return a
+// Provider, which the other helpers such as Singleton accept in place of the
+// function.
+func F0[R any](f func() R) *Provider {
+	return &Provider{fn: f}
+}
+
+// F0E registers f, a function of no parameters returning a value and an error.
+func F0E[R any](f func() (R, error)) *Provider {
+	return &Provider{fn: f}
+}
+
+// F1 registers f, a function of 1 parameter returning a single value.
+func F1[A, R any](f func(A) R) *Provider {
+	return &Provider{fn: f}
+}
+
+// F1E registers f, a function of 1 parameter returning a value and an error.
+func F1E[A, R any](f func(A) (R, error)) *Provider {
+	return &Provider{fn: f}
+}
+
+// F2 registers f, a function of 2 parameters returning a single value.
+func F2[A, B, R any](f func(A, B) R) *Provider {
+	return &Provider{fn: f}
+}
+
+// F2E registers f, a function of 2 parameters returning a value and an error.
+func F2E[A, B, R any](f func(A, B) (R, error)) *Provider {
+	return &Provider{fn: f}
+}
+
+// F3 registers f, a function of 3 parameters returning a single value.
+func F3[A, B, C, R any](f func(A, B, C) R) *Provider {
+	return &Provider{fn: f}
+}
+
+// F3E registers f, a function of 3 parameters returning a value and an error.
+func F3E[A, B, C, R any](f func(A, B, C) (R, error)) *Provider {
+	return &Provider{fn: f}
+}
+
+// F4 registers f, a function of 4 parameters returning a single value.
+func F4[A, B, C, D, R any](f func(A, B, C, D) R) *Provider {
+	return &Provider{fn: f}
+}
+
+// F4E registers f, a function of 4 parameters returning a value and an error.
+func F4E[A, B, C, D, R any](f func(A, B, C, D) (R, error)) *Provider {
+	return &Provider{fn: f}
+}
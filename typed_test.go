@@ -0,0 +1,48 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_TypedRegistration(t *testing.T) {
+	type (
+		a string
+		b string
+		c string
+		d string
+	)
+
+	ngn, err := Initialize(
+		F0(func() a { return "a" }),
+		F1E(func(in a) (b, error) { return b(in + "b"), nil }),
+		Singleton(F2(func(in1 a, in2 b) c { return c(string(in1) + string(in2) + "c") })),
+		F3E(func(in1 a, in2 b, in3 c) (d, error) {
+			if in1 == "<bad>" {
+				return "", errors.New("bad input")
+			}
+			return d(string(in3) + "d"), nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should run the registered functions", func(t *testing.T) {
+		t.Parallel()
+		out, err := Run[d](context.Background(), ngn)
+		assert.NoError(t, err)
+		assert.Equal(t, d("aabcd"), out)
+	})
+
+	t.Run("should still validate the rules the compiler can not check", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(F2(func(in1 a, in2 a) b { return b(in1 + in2) }))
+		assertErrContains(t, err, "function takes the same parameter type warp_test.a more than once")
+	})
+}
@@ -2,31 +2,151 @@ package warp
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 )
 
+// Rule is a validation rule of Initialize.
+type Rule int
+
+const (
+	// RuleNoFunctions is broken by an engine initialized without functions.
+	RuleNoFunctions Rule = iota + 1
+	// RuleNotFunction is broken by an input that is not a function.
+	RuleNotFunction
+	// RuleNoOutputs is broken by a function returning nothing.
+	RuleNoOutputs
+	// RuleNoValueOutputs is broken by a function returning only an error or
+	// a Cleanup.
+	RuleNoValueOutputs
+	// RuleSinkOutputs is broken by a Sink returning values.
+	RuleSinkOutputs
+	// RuleMultipleErrors is broken by a function returning several errors.
+	RuleMultipleErrors
+	// RuleMultipleCleanups is broken by a function returning several
+	// Cleanups.
+	RuleMultipleCleanups
+	// RuleErrorInput is broken by a function accepting an error.
+	RuleErrorInput
+	// RuleCleanupInput is broken by a function accepting a Cleanup.
+	RuleCleanupInput
+	// RuleContextOutput is broken by a function returning a
+	// context.Context.
+	RuleContextOutput
+	// RuleInvokerOutput is broken by a function returning an Invoker.
+	RuleInvokerOutput
+	// RuleInputIsOutput is broken by a function returning the type of one
+	// of its parameters.
+	RuleInputIsOutput
+	// RuleVariadic is broken by a variadic function.
+	RuleVariadic
+	// RuleRepeatedInput is broken by a function accepting the same type
+	// twice.
+	RuleRepeatedInput
+	// RuleDuplicateOutput is broken by functions returning the same type.
+	RuleDuplicateOutput
+	// RuleCycle is broken by functions depending on their own outputs.
+	RuleCycle
+	// RuleInvalidProvider is broken by a Provider that could not be built
+	// from the function it wraps.
+	RuleInvalidProvider
+	// RuleInvalidAdapter is broken by an Adapter that could not be built.
+	RuleInvalidAdapter
+	// RuleAmbiguousAdapter is broken by an Adapter producing a type that is
+	// also produced by a function or by another Adapter.
+	RuleAmbiguousAdapter
+	// RuleChainedAdapter is broken by an Adapter adapting a type that is
+	// itself adapted.
+	RuleChainedAdapter
+	// RuleAmbiguousBinding is broken by an interface input implemented by
+	// several outputs under WithInterfaceBinding.
+	RuleAmbiguousBinding
+)
+
+var ruleNames = [...]string{
+	RuleNoFunctions:      "no functions",
+	RuleNotFunction:      "not a function",
+	RuleNoOutputs:        "no outputs",
+	RuleNoValueOutputs:   "no value outputs",
+	RuleSinkOutputs:      "sink outputs",
+	RuleMultipleErrors:   "multiple errors",
+	RuleMultipleCleanups: "multiple cleanups",
+	RuleErrorInput:       "error input",
+	RuleCleanupInput:     "cleanup input",
+	RuleContextOutput:    "context output",
+	RuleInvokerOutput:    "invoker output",
+	RuleInputIsOutput:    "input is output",
+	RuleVariadic:         "variadic",
+	RuleRepeatedInput:    "repeated input",
+	RuleDuplicateOutput:  "duplicate output",
+	RuleCycle:            "cycle",
+	RuleInvalidProvider:  "invalid provider",
+	RuleInvalidAdapter:   "invalid adapter",
+	RuleAmbiguousAdapter: "ambiguous adapter",
+	RuleChainedAdapter:   "chained adapter",
+	RuleAmbiguousBinding: "ambiguous binding",
+}
+
+func (r Rule) String() string {
+	if r <= 0 || int(r) >= len(ruleNames) {
+		return "unknown"
+	}
+	return ruleNames[r]
+}
+
+// ValidationError is a failure of the validation of Initialize. The error
+// returned by Initialize joins a ValidationError per failure, which errors.As
+// retrieves.
+type ValidationError struct {
+	// Func refers to the function breaking the rule, empty if the rule is
+	// broken by several functions together.
+	Func string
+	// Rule is the broken rule.
+	Rule Rule
+	// Detail describes the failure.
+	Detail string
+
+	err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Func == "" {
+		return "input validation error: " + e.Detail
+	}
+	return fmt.Sprintf("input %s caused validation error: %s", e.Func, e.Detail)
+}
+
+// Unwrap returns the error the failure was built from, if any.
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// invalid returns the ValidationError breaking rule, not yet attributed to a
+// function.
+func invalid(rule Rule, format string, args ...any) *ValidationError {
+	return &ValidationError{Rule: rule, Detail: fmt.Sprintf(format, args...)}
+}
+
 // early engine init per function validation steps
 
 func validateAtLeastOneFunction(fns ...any) error {
 	if len(fns) == 0 {
-		return errors.New("engine must be initialized with at least one function")
+		return invalid(RuleNoFunctions, "engine must be initialized with at least one function")
 	}
 	return nil
 }
 
 func validateTypeFunction(fnT reflect.Type) error {
 	if fnT.Kind() != reflect.Func {
-		return errors.New("all inputs must be functions")
+		return invalid(RuleNotFunction, "all inputs must be functions")
 	}
 	return nil
 }
 
 func validateFunctionHasOutputs(fnT reflect.Type) error {
 	if fnT.NumOut() == 0 {
-		return errors.New("must not have no return type(s)")
+		return invalid(RuleNoOutputs, "must not have no return type(s)")
 	}
 	return nil
 }
@@ -39,7 +159,21 @@ func validateFunctionHasReturnsAtMostOneError(fnT reflect.Type) error {
 		}
 	}
 	if count > 1 {
-		return errors.New("must have no more than 1 error return type")
+		return invalid(RuleMultipleErrors, "must have no more than 1 error return type")
+	}
+
+	return nil
+}
+
+func validateFunctionHasReturnsAtMostOneCleanup(fnT reflect.Type) error {
+	var count int
+	for _, outT := range outputs(fnT) {
+		if isType[Cleanup](outT) {
+			count++
+		}
+	}
+	if count > 1 {
+		return invalid(RuleMultipleCleanups, "must have no more than 1 Cleanup return type")
 	}
 
 	return nil
@@ -48,12 +182,12 @@ func validateFunctionHasReturnsAtMostOneError(fnT reflect.Type) error {
 func validateFunctionHasAtLeastOneNonErrorValueOutput(fnT reflect.Type) error {
 	var count int
 	for _, o := range outputs(fnT) {
-		if !isType[error](o) {
+		if isValueType(o) {
 			count++
 		}
 	}
 	if count == 0 {
-		return errors.New("must have at least 1 return value type (excluding error)")
+		return invalid(RuleNoValueOutputs, "must have at least 1 return value type (excluding error)")
 	}
 
 	return nil
@@ -62,7 +196,16 @@ func validateFunctionHasAtLeastOneNonErrorValueOutput(fnT reflect.Type) error {
 func validateFunctionInputsNotError(fnT reflect.Type) error {
 	for _, i := range inputs(fnT) {
 		if isType[error](i) {
-			return errors.New("must not have input param(s) of type error")
+			return invalid(RuleErrorInput, "must not have input param(s) of type error")
+		}
+	}
+	return nil
+}
+
+func validateFunctionInputsNotCleanup(fnT reflect.Type) error {
+	for _, i := range inputs(fnT) {
+		if isType[Cleanup](i) {
+			return invalid(RuleCleanupInput, "must not have input param(s) of type Cleanup")
 		}
 	}
 	return nil
@@ -71,7 +214,7 @@ func validateFunctionInputsNotError(fnT reflect.Type) error {
 func validateFunctionOutputsNotContext(fnT reflect.Type) error {
 	for _, outT := range outputs(fnT) {
 		if isType[context.Context](outT) {
-			return errors.New("must not have any context.Context return value type(s)")
+			return invalid(RuleContextOutput, "must not have any context.Context return value type(s)")
 		}
 	}
 	return nil
@@ -79,7 +222,7 @@ func validateFunctionOutputsNotContext(fnT reflect.Type) error {
 
 func validateDistinctInputOutputTypes(fnT reflect.Type) error {
 	for _, outT := range outputs(fnT) {
-		if isType[error](outT) {
+		if !isValueType(outT) {
 			continue
 		}
 		outTU, _ := unwrapOptional(outT)
@@ -87,7 +230,7 @@ func validateDistinctInputOutputTypes(fnT reflect.Type) error {
 		for _, inT := range inputs(fnT) {
 			inTU, _ := unwrapOptional(inT)
 			if outTU == inTU {
-				return fmt.Errorf("input type %s is also an output type", inTU)
+				return invalid(RuleInputIsOutput, "input type %s is also an output type", inTU)
 			}
 		}
 	}
@@ -97,7 +240,7 @@ func validateDistinctInputOutputTypes(fnT reflect.Type) error {
 
 func validateFunctionNotVariadic(fnT reflect.Type) error {
 	if fnT.Kind() == reflect.Func && fnT.IsVariadic() {
-		return errors.New("must not be a variadic function")
+		return invalid(RuleVariadic, "must not be a variadic function")
 	}
 	return nil
 }
@@ -107,7 +250,7 @@ func validateSameInputTypes(fnT reflect.Type) error {
 	for _, inT := range inputs(fnT) {
 		inT, _ = unwrapOptional(inT)
 		if in[inT] {
-			return fmt.Errorf("function takes the same parameter type %s more than once", inT)
+			return invalid(RuleRepeatedInput, "function takes the same parameter type %s more than once", inT)
 		}
 		in[inT] = true
 	}
@@ -117,26 +260,35 @@ func validateSameInputTypes(fnT reflect.Type) error {
 
 // late engine init cross-function validation steps
 
-func validateOutputTypesUnique(fns ...any) error {
-	outTypes := make(map[reflect.Type][]reflect.Value, len(fns))
+func validateOutputTypesUnique(fns ...any) []error {
+	var (
+		order    []reflect.Type
+		outTypes = make(map[reflect.Type][]reflect.Value, len(fns))
+	)
 	for _, fn := range fns {
 		fnV := reflect.ValueOf(fn)
 		for _, outT := range outputs(fnV.Type()) {
-			if isType[error](outT) {
+			if !isValueType(outT) {
 				continue
 			}
+			// T and Optional[T] are stored under the same key
+			outT, _ = unwrapOptional(outT)
+			if _, ok := outTypes[outT]; !ok {
+				order = append(order, outT)
+			}
 			outTypes[outT] = append(outTypes[outT], fnV)
 		}
 	}
 
-	for outT, providerTs := range outTypes {
-		if len(providerTs) > 1 {
+	var errs []error
+	for _, outT := range order {
+		if providerTs := outTypes[outT]; len(providerTs) > 1 {
 			badProviderRefs := strings.Join(sliceConvert(referTo, providerTs), " AND ")
-			return fmt.Errorf("output value type %s already provided to the engine by %s", outT, badProviderRefs)
+			errs = append(errs, invalid(RuleDuplicateOutput, "output value type %s already provided to the engine by %s", outT, badProviderRefs))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func validateNoCyclicDependancies(fnVs []reflect.Value) error {
@@ -153,14 +305,14 @@ func checkCyclicDependancies(fnV reflect.Value, pathFuncs []reflect.Value, fnVs
 	fnT := reflect.TypeOf(fnV.Interface())
 	for _, pathFn := range pathFuncs {
 		if pathFn.Type() == fnT {
-			return fmt.Errorf("cyclic dependency detected: %s", cyclicDependencyPath(pathFuncs))
+			return invalid(RuleCycle, "cyclic dependency detected: %s", cyclicDependencyPath(pathFuncs))
 		}
 	}
 
 	pathFuncs = append(pathFuncs, fnV)
 
 	for _, outT := range outputs(fnT) {
-		if isType[error](outT) {
+		if !isValueType(outT) {
 			continue
 		}
 		outTU, _ := unwrapOptional(outT)
@@ -169,6 +321,7 @@ func checkCyclicDependancies(fnV reflect.Value, pathFuncs []reflect.Value, fnVs
 			fnT := reflect.TypeOf(fnV.Interface())
 			for _, inT := range inputs(fnT) {
 				inTU, _ := unwrapOptional(inT)
+				inTU, _ = unwrapLazy(inTU)
 				if inTU == outTU {
 					err := checkCyclicDependancies(fnV, pathFuncs, fnVs)
 					if err != nil {
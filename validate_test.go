@@ -0,0 +1,92 @@
+package warp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_ValidationError(t *testing.T) {
+	t.Run("should describe the broken rule", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(func(context.Context, ...inType) (outType1, error) { return "", nil })
+
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected a ValidationError, got %v", err)
+		}
+		assert.Equal(t, RuleVariadic, verr.Rule)
+		assert.Equal(t, "variadic", verr.Rule.String())
+		assert.Contains(t, verr.Func, "Test_ValidationError")
+		assert.Equal(t, "must not be a variadic function", verr.Detail)
+		assertErrContains(t, err, "caused validation error: must not be a variadic function")
+	})
+
+	t.Run("should not attribute the rules broken by several functions", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			func(inType) outType1 { return "" },
+			func(inType) Optional[outType1] { return Optional[outType1]{} },
+		)
+
+		assert.Equal(t, []Rule{RuleDuplicateOutput}, rules(err))
+		var verr *ValidationError
+		errors.As(err, &verr)
+		assert.Empty(t, verr.Func)
+	})
+
+	t.Run("should join a ValidationError per failure", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			"<not-a-function>",
+			func(inType) {},
+			func(inType, error) outType1 { return "" },
+			Paginate[outType2, outType3]("<not-a-function>"),
+			Convert(func(outType1) (outType4, error) { return "", nil }),
+			func(outType5) outType4 { return "" },
+			func(outType4) outType5 { return "" },
+		)
+
+		assert.Equal(t, []Rule{
+			RuleInvalidProvider,
+			RuleNotFunction,
+			RuleNoOutputs,
+			RuleErrorInput,
+			RuleAmbiguousAdapter,
+			RuleCycle,
+		}, rules(err))
+	})
+
+	t.Run("should wrap the error of an invalid provider", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(Resilient(func(inType) outType1 { return "" }))
+
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected a ValidationError, got %v", err)
+		}
+		assert.Equal(t, RuleInvalidProvider, verr.Rule)
+		assert.Error(t, errors.Unwrap(verr))
+		assertErr(t, err, "input validation error: resilient function must return an error")
+	})
+}
+
+// rules returns the rules broken by the validation errors joined in err.
+func rules(err error) []Rule {
+	var out []Rule
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			out = append(out, rules(err)...)
+		}
+		return out
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		out = append(out, verr.Rule)
+	}
+	return out
+}
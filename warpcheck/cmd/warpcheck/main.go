@@ -0,0 +1,13 @@
+// Command warpcheck reports the functions passed to warp.Initialize that the
+// engine would reject, see package warpcheck.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/dezlitz/warp/warpcheck"
+)
+
+func main() {
+	singlechecker.Main(warpcheck.Analyzer)
+}
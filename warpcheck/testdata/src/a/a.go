@@ -0,0 +1,110 @@
+package a
+
+import (
+	"context"
+	"time"
+
+	"github.com/dezlitz/warp"
+)
+
+type (
+	A string
+	B string
+	C string
+	D string
+)
+
+type params struct {
+	warp.In
+
+	A A
+	B warp.Optional[B]
+}
+
+type results struct {
+	warp.Out
+
+	C C
+	D D `warp:"-"`
+}
+
+func valid() {
+	warp.Initialize(
+		func(a A) B { return "" },
+		warp.Singleton(func(ctx context.Context, p params) (results, error) { return results{}, nil }),
+		warp.Sink(func(c C, d warp.Lazy[D]) error { return nil }),
+		warp.Paginate[A, B](func(a A) (A, B) { return "", "" }),
+		warp.WithInterfaceBinding(),
+	)
+}
+
+func signatures() {
+	warp.Initialize(
+		func(a A) {},                   // want `function \(func\(a A\) literal\) must not have no return type\(s\)`
+		func(a A) error { return nil }, // want `must have at least 1 return value type \(excluding error\)`
+		func(a A) (B, error, error) { return "", nil, nil },                  // want `must have no more than 1 error return type`
+		func(a A, err error) C { return "" },                                 // want `must not have input param\(s\) of type error`
+		func(a A) context.Context { return nil },                             // want `must not have any context.Context return value type\(s\)`
+		func(a A, o warp.Optional[A]) D { return "" },                        // want `function takes the same parameter type A more than once`
+		func(a A) A { return "" },                                            // want `input type A is also an output type`
+		func(a ...A) B { return "" },                                         // want `must not be a variadic function`
+		warp.Sink(func(a A) B { return "" }),                                 // want `sink must not return values other than an error`
+		warp.Tag(func(ctx context.Context, a A, b A) B { return "" }, "tag"), // want `function takes the same parameter type A more than once`
+	)
+}
+
+func schedulingWrappers() {
+	warp.Initialize(
+		warp.Limited(func(a A) {}, 1),                                                   // want `function \(func\(a A\) literal\) must not have no return type\(s\)`
+		warp.CPUBound(func(a A) A { return "" }),                                        // want `input type A is also an output type`
+		warp.IOBound(func(b ...B) C { return "" }),                                      // want `must not be a variadic function`
+		warp.Flagged(func(c C) context.Context { return nil }, "flag"),                  // want `must not have any context.Context return value type\(s\)`
+		warp.Prioritized(func(d D, err error) A { return "" }, 1),                       // want `must not have input param\(s\) of type error`
+		warp.Estimated(func(a A, o warp.Optional[A]) B { return "" }, time.Millisecond), // want `function takes the same parameter type A more than once`
+	)
+}
+
+func duplicates() {
+	warp.Initialize(
+		func(a A) B { return "" },
+		warp.F1(func(c C) warp.Optional[B] { return warp.Optional[B]{} }), // want `output value type B of \(func\(c C\) warp.Optional\[B\] literal\) already provided to the engine by \(func\(a A\) B literal\)`
+	)
+}
+
+type maybeB warp.Optional[B]
+
+// notOptionalB only looks like an Optional
+type notOptionalB struct {
+	Val   B
+	IsSet bool
+}
+
+func derivedOptionals() {
+	warp.Initialize(
+		func(a A) B { return "" },
+		func(c C) maybeB { return maybeB{} }, // want `output value type B of \(func\(c C\) maybeB literal\) already provided to the engine by \(func\(a A\) B literal\)`
+	)
+	warp.Initialize(
+		func(a A) B { return "" },
+		func(c C) notOptionalB { return notOptionalB{} },
+	)
+}
+
+func outputStructs() {
+	warp.Initialize(
+		func(a A) results { return results{} },
+		func(b B) C { return "" }, // want `output value type C of \(func\(b B\) C literal\) already provided to the engine by \(func\(a A\) results literal\)`
+	)
+}
+
+func cycles() {
+	warp.Initialize(
+		func(a A) B { return "" }, // want `cyclic dependency detected: \(func\(a A\) B literal\) -> \(func\(b B\) C literal\) -> \(func\(c warp.Lazy\[C\]\) A literal\) -> \(func\(a A\) B literal\)`
+		func(b B) C { return "" },
+		func(c warp.Lazy[C]) A { return "" },
+	)
+}
+
+func slices(fns []any) {
+	warp.Initialize(append(fns, func(a A) {})...)
+}
@@ -0,0 +1,34 @@
+// Package warp is a stub of the warp package declaring the API the analyzer
+// recognizes.
+package warp
+
+import "time"
+
+type (
+	Provider        struct{}
+	Option          func()
+	Cleanup         func() error
+	Optional[T any] struct {
+		Val   T
+		IsSet bool `warp:"optional"`
+	}
+	Lazy[T any] struct{}
+	In          struct{}
+	Out         struct{}
+	Engine      struct{}
+)
+
+func Initialize(fns ...any) (*Engine, error)         { return nil, nil }
+func Singleton(fn any) *Provider                     { return nil }
+func Sink(fn any) *Provider                          { return nil }
+func Tag(fn any, tags ...string) *Provider           { return nil }
+func Limited(fn any, n int) *Provider                { return nil }
+func CPUBound(fn any) *Provider                      { return nil }
+func IOBound(fn any) *Provider                       { return nil }
+func Flagged(fn any, flag string) *Provider          { return nil }
+func Prioritized(fn any, priority int) *Provider     { return nil }
+func Estimated(fn any, cost time.Duration) *Provider { return nil }
+func Paginate[P, C any](fn any) *Provider            { return nil }
+func F1[A, R any](f func(A) R) *Provider             { return nil }
+func F1E[A, R any](f func(A) (R, error)) *Provider   { return nil }
+func WithInterfaceBinding() Option                   { return nil }
@@ -0,0 +1,385 @@
+// Package warpcheck defines an Analyzer reporting the functions passed to
+// warp.Initialize that the engine would reject, before the program runs.
+//
+// The analyzer checks the signature of every function passed to
+// warp.Initialize, directly or wrapped by helpers such as warp.Singleton,
+// against the rules validated by Initialize, then checks the functions of a
+// call together: no output type may be produced twice and the functions must
+// not depend on each other in a cycle. Parameter and result structs are
+// expanded as the engine does. Functions changed by their helper, such as
+// those registered with warp.Paginate or warp.Branched, and functions passed
+// as a slice are not checked.
+//
+// Run it standalone with
+//
+//	go run github.com/dezlitz/warp/warpcheck/cmd/warpcheck ./...
+//
+// or add Analyzer to a multichecker.
+package warpcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const warpPath = "github.com/dezlitz/warp"
+
+// Analyzer reports invalid function signatures, duplicate output types and
+// cyclic dependencies across the functions passed to warp.Initialize.
+var Analyzer = &analysis.Analyzer{
+	Name:     "warpcheck",
+	Doc:      "report functions passed to warp.Initialize that the engine would reject",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if warpFunc(pass, call.Fun) == "Initialize" {
+			checkInitialize(pass, call)
+		}
+	})
+	return nil, nil
+}
+
+// registered is a function passed to warp.Initialize.
+type registered struct {
+	expr ast.Expr
+	name string
+	sig  *types.Signature
+	sink bool
+	// ins and outs are the value parameter and result types of the
+	// function, parameter and result structs expanded.
+	ins, outs []types.Type
+}
+
+func checkInitialize(pass *analysis.Pass, call *ast.CallExpr) {
+	var fns []*registered
+	for i, arg := range call.Args {
+		if call.Ellipsis.IsValid() && i == len(call.Args)-1 {
+			break
+		}
+		fn, ok := resolve(pass, arg)
+		if !ok {
+			continue
+		}
+		fn.expr = arg
+		fn.ins, fn.outs = expand(fn.sig)
+		if checkSignature(pass, fn) {
+			fns = append(fns, fn)
+		}
+	}
+
+	checkOutputsUnique(pass, fns)
+	checkCycles(pass, fns)
+}
+
+// resolve returns the function registered by expr, unwrapping the helpers of
+// the warp package that keep its signature.
+func resolve(pass *analysis.Pass, expr ast.Expr) (*registered, bool) {
+	expr = ast.Unparen(expr)
+	// The types of the warp package, such as Option, are not functions to
+	// run even when their underlying type is one
+	if t := pass.TypesInfo.TypeOf(expr); t != nil && !isWarpType(t, "") {
+		if sig, ok := t.Underlying().(*types.Signature); ok {
+			return &registered{name: types.ExprString(expr), sig: sig}, true
+		}
+	}
+
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil, false
+	}
+	switch name := warpFunc(pass, call.Fun); {
+	case name == "Sink":
+		fn, ok := resolve(pass, call.Args[0])
+		if ok {
+			fn.sink = true
+		}
+		return fn, ok
+	case name == "Singleton", name == "Tag", name == "BestEffort", name == "Redacted", name == "Resilient",
+		name == "Limited", name == "CPUBound", name == "IOBound", name == "Flagged",
+		name == "Prioritized", name == "Estimated",
+		len(name) >= 2 && name[0] == 'F' && strings.Trim(name[1:], "0123456789E") == "":
+		return resolve(pass, call.Args[0])
+	}
+	return nil, false
+}
+
+// warpFunc returns the name of the function of the warp package expr refers
+// to, or "" if it refers to none.
+func warpFunc(pass *analysis.Pass, expr ast.Expr) string {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	case *ast.IndexExpr:
+		return warpFunc(pass, e.X)
+	case *ast.IndexListExpr:
+		return warpFunc(pass, e.X)
+	default:
+		return ""
+	}
+
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != warpPath {
+		return ""
+	}
+	return fn.Name()
+}
+
+// checkSignature reports the rules of the engine sig breaks and whether it
+// follows them all.
+func checkSignature(pass *analysis.Pass, fn *registered) bool {
+	var (
+		problems        []string
+		errs, cleanups  int
+		values          int
+		contextReturned bool
+	)
+	for i := 0; i < fn.sig.Results().Len(); i++ {
+		switch t := fn.sig.Results().At(i).Type(); {
+		case isError(t):
+			errs++
+		case isWarpType(t, "Cleanup"):
+			cleanups++
+		default:
+			values++
+			contextReturned = contextReturned || isContext(t)
+		}
+	}
+
+	switch {
+	case fn.sink && values > 0:
+		problems = append(problems, "sink must not return values other than an error")
+	case !fn.sink && fn.sig.Results().Len() == 0:
+		problems = append(problems, "must not have no return type(s)")
+	case !fn.sink && len(fn.outs) == 0:
+		problems = append(problems, "must have at least 1 return value type (excluding error)")
+	}
+	if errs > 1 {
+		problems = append(problems, "must have no more than 1 error return type")
+	}
+	if cleanups > 1 {
+		problems = append(problems, "must have no more than 1 Cleanup return type")
+	}
+	if contextReturned {
+		problems = append(problems, "must not have any context.Context return value type(s)")
+	}
+	if fn.sig.Variadic() {
+		problems = append(problems, "must not be a variadic function")
+	}
+
+	var seen typeutil.Map
+	for _, in := range fn.ins {
+		switch {
+		case isError(in):
+			problems = append(problems, "must not have input param(s) of type error")
+		case isWarpType(in, "Cleanup"):
+			problems = append(problems, "must not have input param(s) of type Cleanup")
+		}
+		key := inputKey(in)
+		if seen.At(key) != nil {
+			problems = append(problems, fmt.Sprintf("function takes the same parameter type %s more than once", typeString(pass, key)))
+		}
+		seen.Set(key, true)
+		for _, out := range fn.outs {
+			if types.Identical(unwrap(out, "Optional"), key) {
+				problems = append(problems, fmt.Sprintf("input type %s is also an output type", typeString(pass, key)))
+			}
+		}
+	}
+
+	for _, problem := range problems {
+		pass.Reportf(fn.expr.Pos(), "function %s %s", fn.name, problem)
+	}
+	return len(problems) == 0
+}
+
+// checkOutputsUnique reports the functions producing a type already produced
+// by another function.
+func checkOutputsUnique(pass *analysis.Pass, fns []*registered) {
+	var producers typeutil.Map
+	for _, fn := range fns {
+		for _, out := range fn.outs {
+			key := unwrap(out, "Optional")
+			if first, ok := producers.At(key).(*registered); ok {
+				pass.Reportf(fn.expr.Pos(), "output value type %s of %s already provided to the engine by %s", typeString(pass, key), fn.name, first.name)
+				continue
+			}
+			producers.Set(key, fn)
+		}
+	}
+}
+
+// checkCycles reports the functions depending on their own outputs through
+// other functions.
+func checkCycles(pass *analysis.Pass, fns []*registered) {
+	var producers typeutil.Map
+	for _, fn := range fns {
+		for _, out := range fn.outs {
+			producers.Set(unwrap(out, "Optional"), fn)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state = map[*registered]int{}
+		path  []*registered
+		visit func(fn *registered)
+	)
+	visit = func(fn *registered) {
+		switch state[fn] {
+		case visited:
+			return
+		case visiting:
+			// path leads from the consumers to their producers, the cycle
+			// is reported in the order the values flow
+			names := []string{fn.name}
+			for i := len(path) - 1; i >= 0 && path[i] != fn; i-- {
+				names = append(names, path[i].name)
+			}
+			names = append(names, fn.name)
+			pass.Reportf(fn.expr.Pos(), "cyclic dependency detected: %s", strings.Join(names, " -> "))
+			return
+		}
+
+		state[fn] = visiting
+		path = append(path, fn)
+		for _, in := range fn.ins {
+			if producer, ok := producers.At(inputKey(in)).(*registered); ok {
+				visit(producer)
+			}
+		}
+		path = path[:len(path)-1]
+		state[fn] = visited
+	}
+	for _, fn := range fns {
+		visit(fn)
+	}
+}
+
+// expand returns the parameter and value result types of sig, the fields of
+// its parameter and result structs in place of the structs.
+func expand(sig *types.Signature) (ins, outs []types.Type) {
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		if !embeds(t, "In") {
+			ins = append(ins, t)
+			continue
+		}
+		st := t.Underlying().(*types.Struct)
+		for j := 0; j < st.NumFields(); j++ {
+			if f := st.Field(j); f.Exported() && !f.Embedded() {
+				ins = append(ins, f.Type())
+			}
+		}
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		t := sig.Results().At(i).Type()
+		if isError(t) || isWarpType(t, "Cleanup") {
+			continue
+		}
+		if !embeds(t, "Out") {
+			outs = append(outs, t)
+			continue
+		}
+		st := t.Underlying().(*types.Struct)
+		for j := 0; j < st.NumFields(); j++ {
+			f := st.Field(j)
+			if f.Exported() && !f.Embedded() && reflect.StructTag(st.Tag(j)).Get("warp") != "-" {
+				outs = append(outs, f.Type())
+			}
+		}
+	}
+	return ins, outs
+}
+
+// embeds reports whether t is a struct embedding the named type of the warp
+// package.
+func embeds(t types.Type, name string) bool {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if f := st.Field(i); f.Embedded() && isWarpType(f.Type(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// inputKey returns the type of the value received by a parameter of type t.
+func inputKey(t types.Type) types.Type {
+	return unwrap(unwrap(t, "Optional"), "Lazy")
+}
+
+// unwrap returns the type argument of t if t is an instance of the named
+// generic type of the warp package, t otherwise.
+func unwrap(t types.Type, name string) types.Type {
+	if named, ok := types.Unalias(t).(*types.Named); ok && isWarpType(named, name) && named.TypeArgs().Len() == 1 {
+		return named.TypeArgs().At(0)
+	}
+	if name == "Optional" {
+		if val, ok := derivedOptional(t); ok {
+			return val
+		}
+	}
+	return t
+}
+
+// derivedOptional returns the type of the value wrapped by t if t is derived
+// from an Optional, such as type MaybeUser warp.Optional[User]: its fields are
+// the fields of Optional, instantiated from the warp package.
+func derivedOptional(t types.Type) (types.Type, bool) {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || st.NumFields() != 2 {
+		return nil, false
+	}
+	val, isSet := st.Field(0), st.Field(1)
+	if val.Pkg() == nil || val.Pkg().Path() != warpPath || val.Name() != "Val" || isSet.Name() != "IsSet" {
+		return nil, false
+	}
+	return val.Type(), true
+}
+
+// isWarpType reports whether t is the named type of the warp package, or an
+// instance of it. Any named type of the package matches an empty name.
+func isWarpType(t types.Type, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == warpPath && (name == "" || obj.Name() == name)
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isContext(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func typeString(pass *analysis.Pass, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pass.Pkg))
+}
@@ -0,0 +1,13 @@
+package warpcheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/dezlitz/warp/warpcheck"
+)
+
+func Test_Analyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), warpcheck.Analyzer, "a")
+}
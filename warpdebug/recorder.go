@@ -0,0 +1,132 @@
+package warpdebug
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dezlitz/warp"
+)
+
+// Recorder is a warp.EventListener keeping the latest runs of the engines it
+// is registered with, for Handler to show. Register it with warp.WithListener.
+type Recorder struct {
+	warp.NopListener
+	size int
+
+	mu   sync.Mutex
+	runs []*Run
+	// byID indexes the recorded runs by ID
+	byID map[string]*Run
+}
+
+// Run is a run recorded by a Recorder.
+type Run struct {
+	// ID is the ID of the run, see warp.RunIDFromContext.
+	ID string `json:"id"`
+	// Target is the type produced by the run.
+	Target string `json:"target"`
+	// Started is when the run started.
+	Started time.Time `json:"started"`
+	// Done is true once the run has returned.
+	Done bool `json:"done"`
+	// Duration is the duration of the run, set once done.
+	Duration time.Duration `json:"duration"`
+	// Err is the error returned by the run, if any.
+	Err string `json:"error,omitempty"`
+	// Functions holds the functions of the run that have returned or have
+	// been skipped, in the order they did.
+	Functions []Function `json:"functions"`
+}
+
+// Function is a function of a recorded run.
+type Function struct {
+	// Name refers to the function, as in reports.
+	Name string `json:"name"`
+	// Status is the outcome of the function, such as "succeeded".
+	Status string `json:"status"`
+	// Duration is the duration of the function.
+	Duration time.Duration `json:"duration"`
+	// Err is the error returned by the function, if any.
+	Err string `json:"error,omitempty"`
+}
+
+// NewRecorder returns a Recorder keeping the latest n runs, 20 if n is zero or
+// negative.
+func NewRecorder(n int) *Recorder {
+	if n <= 0 {
+		n = 20
+	}
+	return &Recorder{size: n, byID: map[string]*Run{}}
+}
+
+// Runs returns a copy of the recorded runs, the latest first.
+func (r *Recorder) Runs() []Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Run, len(r.runs))
+	for i, run := range r.runs {
+		out[len(r.runs)-1-i] = *run
+		out[len(r.runs)-1-i].Functions = append([]Function(nil), run.Functions...)
+	}
+	return out
+}
+
+func (r *Recorder) OnRunStart(ctx context.Context, ev warp.RunEvent) {
+	id, ok := warp.RunIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := &Run{ID: id, Target: ev.Target.String(), Started: time.Now()}
+	if len(r.runs) == r.size {
+		// IDs set with warp.WithRunID may be reused by a later run
+		if oldest := r.runs[0]; r.byID[oldest.ID] == oldest {
+			delete(r.byID, oldest.ID)
+		}
+		r.runs = r.runs[1:]
+	}
+	r.runs = append(r.runs, run)
+	r.byID[id] = run
+}
+
+func (r *Recorder) OnRunEnd(ctx context.Context, ev warp.RunEvent) {
+	r.update(ctx, func(run *Run) {
+		run.Done, run.Duration = true, ev.Duration
+		if ev.Err != nil {
+			run.Err = ev.Err.Error()
+		}
+	})
+}
+
+func (r *Recorder) OnFunctionEnd(ctx context.Context, ev warp.FunctionEvent) {
+	r.recordFunction(ctx, ev)
+}
+
+func (r *Recorder) OnFunctionSkipped(ctx context.Context, ev warp.FunctionEvent) {
+	r.recordFunction(ctx, ev)
+}
+
+func (r *Recorder) recordFunction(ctx context.Context, ev warp.FunctionEvent) {
+	fn := Function{Name: ev.Name, Status: ev.Status.String(), Duration: ev.Duration}
+	if ev.Err != nil {
+		fn.Err = ev.Err.Error()
+	}
+	r.update(ctx, func(run *Run) {
+		run.Functions = append(run.Functions, fn)
+	})
+}
+
+// update applies f to the recorded run of ctx, if it is still recorded.
+func (r *Recorder) update(ctx context.Context, f func(run *Run)) {
+	id, ok := warp.RunIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run, ok := r.byID[id]; ok {
+		f(run)
+	}
+}
@@ -0,0 +1,204 @@
+// Package warpdebug serves a live view of a warp engine over HTTP, in the
+// spirit of net/http/pprof: its functions, the dependency graph between them
+// and the latest runs with the status of every function.
+//
+//	recorder := warpdebug.NewRecorder(20)
+//	engine, err := warp.Initialize(fns, warp.WithListener(recorder))
+//	http.Handle("/debug/warp/", warpdebug.Handler(engine, recorder))
+package warpdebug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/dezlitz/warp"
+)
+
+// Handler returns an http.Handler serving the debug view of e and of the runs
+// recorded by r, which may be nil. The handler serves, relative to the path it
+// is mounted on:
+//
+//   - an HTML page with the functions, the graph and the latest runs,
+//   - graph.json, the graph encoded by warp.Engine.MarshalGraphJSON,
+//   - runs.json, the latest runs, the latest first.
+func Handler(e *warp.Engine, r *Recorder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graph.json"):
+			graph, err := e.MarshalGraphJSON()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(graph)
+		case strings.HasSuffix(req.URL.Path, "/runs.json"):
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(runs(r))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			page := struct {
+				Functions []warp.FunctionInfo
+				Graph     graph
+				Runs      []Run
+			}{e.Functions(), layout(e.Functions()), runs(r)}
+			if err := pageTemplate.Execute(w, page); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		}
+	})
+}
+
+func runs(r *Recorder) []Run {
+	if r == nil {
+		return []Run{}
+	}
+	return r.Runs()
+}
+
+// Sizes of the graph view, in pixels.
+const (
+	nodeWidth  = 260
+	nodeHeight = 28
+	columnGap  = 60
+	rowGap     = 16
+)
+
+// graph is the layout of the graph view: the functions are placed in columns
+// by depth, the functions depending on nothing else in the first one.
+type graph struct {
+	Width, Height int
+	Nodes         []node
+	Edges         []edge
+}
+
+type node struct {
+	X, Y  int
+	Label string
+	Title string
+}
+
+type edge struct {
+	X1, Y1, X2, Y2 int
+}
+
+func layout(fns []warp.FunctionInfo) graph {
+	producers := map[reflect.Type]int{}
+	for i, fn := range fns {
+		for _, out := range fn.Outputs {
+			producers[out.Type] = i
+		}
+	}
+
+	// depth is the length of the longest chain of producers of a function.
+	// Graphs are acyclic, so the recursion ends.
+	depths := make([]int, len(fns))
+	for i := range depths {
+		depths[i] = -1
+	}
+	var depth func(i int) int
+	depth = func(i int) int {
+		if depths[i] == -1 {
+			depths[i] = 0
+			for _, in := range fns[i].Inputs {
+				if p, ok := producers[in.Type]; ok {
+					depths[i] = max(depths[i], depth(p)+1)
+				}
+			}
+		}
+		return depths[i]
+	}
+
+	var g graph
+	rows := map[int]int{}
+	for i, fn := range fns {
+		d := depth(i)
+		x, y := columnGap/2+d*(nodeWidth+columnGap), rowGap+rows[d]*(nodeHeight+rowGap)
+		rows[d]++
+		g.Nodes = append(g.Nodes, node{X: x, Y: y, Label: shortName(fn.Name), Title: fn.Name})
+		g.Width, g.Height = max(g.Width, x+nodeWidth+columnGap/2), max(g.Height, y+nodeHeight+rowGap)
+	}
+	for i, fn := range fns {
+		for _, in := range fn.Inputs {
+			if p, ok := producers[in.Type]; ok {
+				from, to := g.Nodes[p], g.Nodes[i]
+				g.Edges = append(g.Edges, edge{
+					X1: from.X + nodeWidth, Y1: from.Y + nodeHeight/2,
+					X2: to.X, Y2: to.Y + nodeHeight/2,
+				})
+			}
+		}
+	}
+	return g
+}
+
+// shortName returns the name of a function without its package path and
+// signature.
+func shortName(name string) string {
+	if i := strings.IndexByte(name, '('); i > 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndexByte(name, '/'); i != -1 {
+		name = name[i+1:]
+	}
+	if len(name) > 40 {
+		name = name[:37] + "..."
+	}
+	return name
+}
+
+var pageTemplate = template.Must(template.New("warpdebug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>warp</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+.succeeded { color: #080; } .failed { color: #c00; } .skipped, .cancelled { color: #888; }
+</style>
+</head>
+<body>
+<h1>warp</h1>
+<p><a href="graph.json">graph.json</a> <a href="runs.json">runs.json</a></p>
+
+<h2>Graph</h2>
+<svg width="{{.Graph.Width}}" height="{{.Graph.Height}}" font-size="12">
+{{- range .Graph.Edges}}
+<line x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}" stroke="#999"/>
+{{- end}}
+{{- range .Graph.Nodes}}
+<g><title>{{.Title}}</title><rect x="{{.X}}" y="{{.Y}}" width="260" height="28" rx="4" fill="#eef" stroke="#669"/><text x="{{.X}}" y="{{.Y}}" dx="8" dy="18">{{.Label}}</text></g>
+{{- end}}
+</svg>
+
+<h2>Functions</h2>
+<table>
+<tr><th>Function</th><th>Location</th><th>Inputs</th><th>Outputs</th><th>Tags</th></tr>
+{{- range .Functions}}
+<tr><td>{{.Name}}</td><td>{{.Location}}</td>
+<td>{{range .Inputs}}{{.Type}}{{if .Optional}} (optional){{end}}{{if .Lazy}} (lazy){{end}}<br>{{end}}</td>
+<td>{{range .Outputs}}{{.Type}}{{if .Optional}} (optional){{end}}<br>{{end}}</td>
+<td>{{range .Tags}}{{.}} {{end}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Runs</h2>
+{{- range .Runs}}
+<h3>{{.ID}} {{.Target}}</h3>
+<p>started {{.Started.Format "2006-01-02 15:04:05.000"}}{{if .Done}}, took {{.Duration}}{{else}}, in progress{{end}}{{if .Err}}, failed: {{.Err}}{{end}}</p>
+<table>
+<tr><th>Function</th><th>Status</th><th>Duration</th><th>Error</th></tr>
+{{- range .Functions}}
+<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Duration}}</td><td>{{.Err}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No run recorded.</p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,119 @@
+package warpdebug_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dezlitz/warp"
+	"github.com/dezlitz/warp/warpdebug"
+)
+
+type (
+	query  string
+	rows   string
+	report string
+)
+
+func Test_Handler(t *testing.T) {
+	recorder := warpdebug.NewRecorder(2)
+	ngn, err := warp.Initialize(
+		func(q query) (rows, error) {
+			if q == "" {
+				return "", errors.New("<empty query>")
+			}
+			return rows("rows of " + q), nil
+		},
+		func(r rows) report { return report("report of " + r) },
+		warp.WithListener(recorder),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = warp.Run[report](context.Background(), ngn, query(""), warp.WithRunID("<first>"))
+	assert.Error(t, err)
+	_, err = warp.Run[report](context.Background(), ngn, query("<q>"), warp.WithRunID("<second>"))
+	assert.NoError(t, err)
+	_, err = warp.Run[report](context.Background(), ngn, query("<q>"), warp.WithRunID("<third>"))
+	assert.NoError(t, err)
+	handler := warpdebug.Handler(ngn, recorder)
+
+	t.Run("should keep the latest runs", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/warp/runs.json", nil))
+
+		var runs []warpdebug.Run
+		if err := json.Unmarshal(rec.Body.Bytes(), &runs); err != nil {
+			t.Fatal(err)
+		}
+		if assert.Len(t, runs, 2) {
+			assert.Equal(t, "<third>", runs[0].ID)
+			assert.Equal(t, "<second>", runs[1].ID)
+			assert.True(t, runs[0].Done)
+			assert.Equal(t, "warpdebug_test.report", runs[0].Target)
+			if assert.Len(t, runs[0].Functions, 2) {
+				assert.Equal(t, "succeeded", runs[0].Functions[0].Status)
+			}
+		}
+	})
+
+	t.Run("should record the failures of the runs and of their functions", func(t *testing.T) {
+		t.Parallel()
+		recorder := warpdebug.NewRecorder(0)
+		ngn, err := warp.Initialize(
+			func(q query) (rows, error) { return "", errors.New("<failed>") },
+			func(r rows) report { return report(r) },
+			warp.WithListener(recorder),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = warp.Run[report](context.Background(), ngn, query("<q>"))
+
+		runs := recorder.Runs()
+		if assert.Len(t, runs, 1) {
+			assert.Contains(t, runs[0].Err, "<failed>")
+			if assert.NotEmpty(t, runs[0].Functions) {
+				assert.Equal(t, "failed", runs[0].Functions[0].Status)
+				assert.Equal(t, "<failed>", runs[0].Functions[0].Err)
+			}
+		}
+	})
+
+	t.Run("should serve the graph", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/warp/graph.json", nil))
+
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), `"edges"`)
+	})
+
+	t.Run("should serve the page", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/warp/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, "<svg")
+		assert.Contains(t, body, "&lt;third&gt;")
+		assert.Contains(t, body, "warpdebug_test.Test_Handler.func1")
+		assert.NotContains(t, body, "&lt;first&gt;")
+	})
+
+	t.Run("should serve the page without a recorder", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		warpdebug.Handler(ngn, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "No run recorded.")
+	})
+}
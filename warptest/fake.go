@@ -0,0 +1,119 @@
+package warptest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dezlitz/warp"
+)
+
+// FakeFactory builds the fake of an interface type, see FakeOf.
+type FakeFactory struct {
+	typ reflect.Type
+	fn  func() warp.Typed
+}
+
+// FakeOf returns the FakeFactory building the fakes of type I with factory.
+func FakeOf[I any](factory func() I) FakeFactory {
+	return FakeFactory{
+		typ: reflect.TypeOf((*I)(nil)).Elem(),
+		fn: func() warp.Typed {
+			return warp.As[I](factory())
+		},
+	}
+}
+
+// Fakes is a fixture making RunWithFixtures fake the interface and function
+// typed inputs that neither a function of the engine nor a fixture provides,
+// so that part of a graph can run in isolation. Create it with AutoFake.
+type Fakes struct {
+	factories map[reflect.Type]FakeFactory
+}
+
+// AutoFake returns a fixture faking the missing interface and function typed
+// inputs of the engine. Function typed inputs are faked by a function
+// returning zero values. Interface typed inputs are faked by the factory
+// registered for their type, as Go can not implement an interface at run
+// time: the test fails if an interface input has no factory.
+//
+// Only the inputs required by a function are faked, Optional inputs are left
+// unset.
+func AutoFake(factories ...FakeFactory) *Fakes {
+	f := &Fakes{factories: map[reflect.Type]FakeFactory{}}
+	for _, factory := range factories {
+		f.factories[factory.typ] = factory
+	}
+	return f
+}
+
+// splitFakes separates the Fakes from the other fixtures.
+func splitFakes(fixtures []any) ([]any, *Fakes) {
+	var (
+		out   = make([]any, 0, len(fixtures))
+		fakes *Fakes
+	)
+	for _, fixture := range fixtures {
+		if f, ok := fixture.(*Fakes); ok {
+			fakes = f
+			continue
+		}
+		out = append(out, fixture)
+	}
+	return out, fakes
+}
+
+// build returns the fakes of the inputs of e provided neither by a function
+// nor by provided.
+func (f *Fakes) build(t testing.TB, e *warp.Engine, provided []any) []any {
+	t.Helper()
+
+	available := map[reflect.Type]bool{}
+	for _, p := range provided {
+		if typed, ok := p.(warp.Typed); ok {
+			available[typed.Type()] = true
+			continue
+		}
+		available[reflect.TypeOf(p)] = true
+	}
+	for _, fn := range e.Functions() {
+		for _, out := range fn.Outputs {
+			available[out.Type] = true
+		}
+	}
+
+	var out []any
+	for _, fn := range e.Functions() {
+		for _, in := range fn.Inputs {
+			if in.Optional || available[in.Type] {
+				continue
+			}
+			kind := in.Type.Kind()
+			if kind != reflect.Interface && kind != reflect.Func {
+				continue
+			}
+
+			switch factory, ok := f.factories[in.Type]; {
+			case ok:
+				out = append(out, factory.fn())
+			case kind == reflect.Func:
+				out = append(out, zeroFunc(in.Type).Interface())
+			default:
+				t.Fatalf("no fake for %s required by %s: register one with warptest.FakeOf", in.Type, fn.Name)
+				return nil
+			}
+			available[in.Type] = true
+		}
+	}
+	return out
+}
+
+// zeroFunc returns a function of type fnT returning zero values.
+func zeroFunc(fnT reflect.Type) reflect.Value {
+	return reflect.MakeFunc(fnT, func([]reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, fnT.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(fnT.Out(i))
+		}
+		return out
+	})
+}
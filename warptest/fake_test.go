@@ -0,0 +1,71 @@
+package warptest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dezlitz/warp"
+	"github.com/dezlitz/warp/warptest"
+)
+
+type (
+	mailer interface {
+		Send(to string) error
+	}
+	clock      func() int64
+	recipient  string
+	sentAt     int64
+	newsletter string
+)
+
+type nopMailer struct{ sent *[]string }
+
+func (m nopMailer) Send(to string) error {
+	*m.sent = append(*m.sent, to)
+	return nil
+}
+
+func Test_AutoFake(t *testing.T) {
+	ngn, err := warp.Initialize(
+		func(m mailer, c clock, r recipient) (sentAt, error) {
+			return sentAt(c()), m.Send(string(r))
+		},
+		func(s sentAt, l warp.Optional[clock]) newsletter { return newsletter(strings.Repeat("*", int(s))) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should fake the missing interface and function inputs", func(t *testing.T) {
+		var sent []string
+		out := warptest.RunWithFixtures[newsletter](t, ngn,
+			recipient("<to>"),
+			warptest.AutoFake(warptest.FakeOf[mailer](func() mailer { return nopMailer{sent: &sent} })),
+		)
+		assert.Equal(t, newsletter(""), out)
+		assert.Equal(t, []string{"<to>"}, sent)
+	})
+
+	t.Run("should not fake the provided inputs", func(t *testing.T) {
+		var sent []string
+		out := warptest.RunWithFixtures[newsletter](t, ngn,
+			recipient("<to>"),
+			warp.As[mailer](nopMailer{sent: &sent}),
+			func() clock { return func() int64 { return 3 } },
+			warptest.AutoFake(),
+		)
+		assert.Equal(t, newsletter("***"), out)
+		assert.Equal(t, []string{"<to>"}, sent)
+	})
+
+	t.Run("should fail the test if an interface input has no factory", func(t *testing.T) {
+		ft := &fakeTB{TB: t}
+		func() {
+			defer func() { _ = recover() }()
+			warptest.RunWithFixtures[newsletter](ft, ngn, recipient("<to>"), warptest.AutoFake())
+		}()
+		assert.Contains(t, ft.fatal, "no fake for warptest_test.mailer required by")
+	})
+}
@@ -0,0 +1,138 @@
+// Package warptest provides helpers for testing code built on warp engines.
+package warptest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/dezlitz/warp"
+)
+
+// RunWithFixtures runs the engine for T with the values built by fixtures as
+// the provided inputs, and fails the test if the run returns an error.
+//
+// A fixture is either a value, provided as is, or a fixture function: any
+// function among the fixtures is called rather than provided. A
+// fixture function may accept a testing.TB, a context.Context and the values
+// of earlier fixtures, and returns the values to provide, optionally with an
+// error failing the test and a warp.Cleanup. The Cleanup, and every returned
+// value implementing io.Closer, are released with t.Cleanup once the test and
+// its subtests have finished, in reverse order.
+//
+// warp.RunOptions, and the Fakes returned by AutoFake, may be passed among the
+// fixtures.
+func RunWithFixtures[T any](t testing.TB, e *warp.Engine, fixtures ...any) T {
+	t.Helper()
+
+	fixtures, fakes := splitFakes(fixtures)
+	provided := buildFixtures(t, fixtures)
+	if fakes != nil {
+		provided = append(provided, fakes.build(t, e, provided)...)
+	}
+
+	out, err := warp.Run[T](context.Background(), e, provided...)
+	if err != nil {
+		t.Fatalf("error running engine: %v", err)
+	}
+	return out
+}
+
+var (
+	tbType      = reflect.TypeOf((*testing.TB)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	cleanupType = reflect.TypeOf(warp.Cleanup(nil))
+	closerType  = reflect.TypeOf((*io.Closer)(nil)).Elem()
+)
+
+// buildFixtures returns the values built by the fixtures, in order.
+func buildFixtures(t testing.TB, fixtures []any) []any {
+	t.Helper()
+
+	var (
+		out    = make([]any, 0, len(fixtures))
+		values = map[reflect.Type]reflect.Value{}
+	)
+	for i, fixture := range fixtures {
+		fixtureV := reflect.ValueOf(fixture)
+		if _, ok := fixture.(warp.RunOption); ok || fixtureV.Kind() != reflect.Func {
+			out = append(out, fixture)
+			values[reflect.TypeOf(fixture)] = fixtureV
+			continue
+		}
+
+		outValues, err := callFixture(t, fixtureV, values)
+		if err != nil {
+			t.Fatalf("fixture %d: %v", i, err)
+		}
+		for _, v := range outValues {
+			out = append(out, v.Interface())
+			values[v.Type()] = v
+		}
+	}
+	return out
+}
+
+// callFixture calls a fixture function and returns its value outputs. Its
+// Cleanup and io.Closer outputs are registered with t.Cleanup.
+func callFixture(t testing.TB, fnV reflect.Value, values map[reflect.Type]reflect.Value) ([]reflect.Value, error) {
+	t.Helper()
+
+	fnT := fnV.Type()
+	ins := make([]reflect.Value, fnT.NumIn())
+	for i := range ins {
+		inT := fnT.In(i)
+		switch {
+		case inT == tbType || inT == reflect.TypeOf(t):
+			ins[i] = reflect.ValueOf(t)
+		case inT == contextType:
+			ins[i] = reflect.ValueOf(context.Background())
+		default:
+			v, ok := values[inT]
+			if !ok {
+				return nil, fmt.Errorf("no earlier fixture provides %s", inT)
+			}
+			ins[i] = v
+		}
+	}
+
+	var out []reflect.Value
+	for _, v := range fnV.Call(ins) {
+		switch v.Type() {
+		case errorType:
+			if !v.IsNil() {
+				return nil, v.Interface().(error)
+			}
+		case cleanupType:
+			if cleanup := v.Interface().(warp.Cleanup); cleanup != nil {
+				t.Cleanup(func() {
+					if err := cleanup(context.Background()); err != nil {
+						t.Errorf("fixture cleanup: %v", err)
+					}
+				})
+			}
+		default:
+			if v.Type().Implements(closerType) && !isNil(v) {
+				closer := v.Interface().(io.Closer)
+				t.Cleanup(func() {
+					if err := closer.Close(); err != nil {
+						t.Errorf("fixture close: %v", err)
+					}
+				})
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package warptest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dezlitz/warp"
+	"github.com/dezlitz/warp/warptest"
+)
+
+type (
+	dsn      string
+	conn     struct{ closed *[]string }
+	userID   string
+	userName string
+)
+
+func (c *conn) Close() error {
+	*c.closed = append(*c.closed, "conn")
+	return nil
+}
+
+func Test_RunWithFixtures(t *testing.T) {
+	ngn, err := warp.Initialize(
+		func(c *conn, id userID) userName { return userName(strings.ToUpper(string(id))) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should provide fixture values and release them with the test", func(t *testing.T) {
+		var released []string
+		t.Run("table", func(t *testing.T) {
+			for _, id := range []userID{"ada", "bob"} {
+				t.Run(string(id), func(t *testing.T) {
+					out := warptest.RunWithFixtures[userName](t, ngn,
+						dsn("<dsn>"),
+						func(t testing.TB, d dsn) (*conn, warp.Cleanup, error) {
+							assert.Equal(t, dsn("<dsn>"), d)
+							return &conn{closed: &released}, func(context.Context) error {
+								released = append(released, "cleanup")
+								return nil
+							}, nil
+						},
+						id,
+					)
+					assert.Equal(t, userName(strings.ToUpper(string(id))), out)
+				})
+			}
+		})
+		assert.Equal(t, []string{"cleanup", "conn", "cleanup", "conn"}, released)
+	})
+
+	t.Run("should fail the test if a fixture returns an error", func(t *testing.T) {
+		ft := &fakeTB{TB: t}
+		func() {
+			defer func() { _ = recover() }()
+			warptest.RunWithFixtures[userName](ft, ngn,
+				func() (*conn, error) { return nil, errors.New("<fixture-error>") },
+			)
+		}()
+		assert.Equal(t, "fixture 0: <fixture-error>", ft.fatal)
+	})
+}
+
+// fakeTB records the first fatal message instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	fatal string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.fatal = fmt.Sprintf(format, args...)
+	panic("fatal")
+}
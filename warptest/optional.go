@@ -0,0 +1,33 @@
+package warptest
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// EquateOptionals returns a cmp.Option comparing warp.Optional values as
+// pointers to their values, nil when unset, instead of with their Equal
+// method, so that the other options passed to cmp.Diff or cmp.Equal, such as
+// cmpopts.IgnoreFields, apply to the values and differences are reported
+// within them.
+func EquateOptionals() cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		return isOptional(p.Last().Type())
+	}, cmp.Transformer("warp.Optional", func(o any) any {
+		v := reflect.ValueOf(o)
+		val := v.FieldByName("Val")
+		if !v.FieldByName("IsSet").Bool() {
+			return reflect.Zero(reflect.PointerTo(val.Type())).Interface()
+		}
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		return ptr.Interface()
+	}))
+}
+
+// isOptional reports whether t is an instance of warp.Optional.
+func isOptional(t reflect.Type) bool {
+	return t != nil && t.PkgPath() == "github.com/dezlitz/warp" && strings.HasPrefix(t.Name(), "Optional[")
+}
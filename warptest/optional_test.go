@@ -0,0 +1,48 @@
+package warptest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dezlitz/warp"
+	"github.com/dezlitz/warp/warptest"
+)
+
+func Test_EquateOptionals(t *testing.T) {
+	type quote struct {
+		Price     int
+		FetchedAt time.Time
+	}
+	now := time.Now()
+
+	t.Run("should compare the optionals with their Equal method by default", func(t *testing.T) {
+		t.Parallel()
+		assert.True(t, cmp.Equal(warp.Optional[quote]{Val: quote{Price: 1}}, warp.Optional[quote]{}))
+		assert.False(t, cmp.Equal(warp.Optional[quote]{IsSet: true}, warp.Optional[quote]{}))
+	})
+
+	t.Run("should apply the options to the values", func(t *testing.T) {
+		t.Parallel()
+		a := warp.Optional[quote]{Val: quote{Price: 1, FetchedAt: now}, IsSet: true}
+		b := warp.Optional[quote]{Val: quote{Price: 1, FetchedAt: now.Add(time.Hour)}, IsSet: true}
+		assert.False(t, cmp.Equal(a, b, warptest.EquateOptionals()))
+		assert.True(t, cmp.Equal(a, b, warptest.EquateOptionals(), cmpopts.IgnoreFields(quote{}, "FetchedAt")))
+		assert.False(t, cmp.Equal(a, warp.Optional[quote]{}, warptest.EquateOptionals(), cmpopts.IgnoreFields(quote{}, "FetchedAt")))
+		assert.True(t, cmp.Equal(warp.Optional[quote]{Val: quote{Price: 1}}, warp.Optional[quote]{}, warptest.EquateOptionals()))
+		assert.Contains(t, cmp.Diff(a, warp.Optional[quote]{Val: quote{Price: 2, FetchedAt: now}, IsSet: true}, warptest.EquateOptionals()), "Price:")
+	})
+
+	t.Run("should apply to the optionals nested in other values", func(t *testing.T) {
+		t.Parallel()
+		type order struct {
+			Quote warp.Optional[quote]
+		}
+		a := order{Quote: warp.Optional[quote]{Val: quote{Price: 1, FetchedAt: now}, IsSet: true}}
+		b := order{Quote: warp.Optional[quote]{Val: quote{Price: 1}, IsSet: true}}
+		assert.True(t, cmp.Equal(a, b, warptest.EquateOptionals(), cmpopts.IgnoreFields(quote{}, "FetchedAt")))
+	})
+}
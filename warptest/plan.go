@@ -0,0 +1,55 @@
+package warptest
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dezlitz/warp"
+)
+
+var update = flag.Bool("warptest.update", false, "rewrite the golden plans of RequirePlan")
+
+// RequirePlan compares the execution plan of the engine for target, the names
+// of the functions a run producing target requires in dependency order as
+// returned by warp.Engine.ExplainPath, with the snapshot saved in the golden
+// file, and fails the test if they differ, so that an unexpected change of
+// the wiring of the engine is caught.
+//
+// Run the tests with the -warptest.update flag to write the current plans to
+// their golden files, creating them if needed, then review the changes.
+func RequirePlan(t testing.TB, e *warp.Engine, target reflect.Type, golden string) {
+	t.Helper()
+
+	path, err := e.ExplainPath(target)
+	if err != nil {
+		t.Fatalf("error explaining the plan of %v: %v", target, err)
+	}
+	plan := "# plan of " + target.String() + "\n" + strings.Join(path, "\n") + "\n"
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("error writing golden plan: %v", err)
+		}
+		if err := os.WriteFile(golden, []byte(plan), 0o644); err != nil {
+			t.Fatalf("error writing golden plan: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden plan %s does not exist, run the tests with -warptest.update to create it", golden)
+	}
+	if err != nil {
+		t.Fatalf("error reading golden plan: %v", err)
+	}
+	if string(want) != plan {
+		t.Fatalf("plan of %v does not match golden plan %s, run the tests with -warptest.update to accept it\ngot:\n%s\nwant:\n%s", target, golden, plan, want)
+	}
+}
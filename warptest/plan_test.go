@@ -0,0 +1,72 @@
+package warptest_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dezlitz/warp"
+	"github.com/dezlitz/warp/warptest"
+)
+
+func Test_RequirePlan(t *testing.T) {
+	type (
+		dsn    string
+		pool   string
+		report string
+	)
+	ngn, err := warp.Initialize(
+		func(d dsn) pool { return pool(d) },
+		func(p pool) report { return report(p) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := ngn.ExplainPath(reflect.TypeOf(report("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := "# plan of warptest_test.report\n" + path[0] + "\n" + path[1] + "\n"
+
+	t.Run("should pass when the plan matches the golden plan", func(t *testing.T) {
+		t.Parallel()
+		golden := filepath.Join(t.TempDir(), "report.plan")
+		if err := os.WriteFile(golden, []byte(plan), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		ft := &fakeTB{TB: t}
+		warptest.RequirePlan(ft, ngn, reflect.TypeOf(report("")), golden)
+		assert.Empty(t, ft.fatal)
+	})
+
+	t.Run("should fail when the plan changed", func(t *testing.T) {
+		t.Parallel()
+		golden := filepath.Join(t.TempDir(), "report.plan")
+		if err := os.WriteFile(golden, []byte("# plan of warptest_test.report\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		ft := &fakeTB{TB: t}
+		func() {
+			defer func() { _ = recover() }()
+			warptest.RequirePlan(ft, ngn, reflect.TypeOf(report("")), golden)
+		}()
+		assert.Contains(t, ft.fatal, "plan of warptest_test.report does not match golden plan "+golden)
+	})
+
+	t.Run("should fail when the golden plan does not exist", func(t *testing.T) {
+		t.Parallel()
+		golden := filepath.Join(t.TempDir(), "missing.plan")
+
+		ft := &fakeTB{TB: t}
+		func() {
+			defer func() { _ = recover() }()
+			warptest.RequirePlan(ft, ngn, reflect.TypeOf(report("")), golden)
+		}()
+		assert.Equal(t, "golden plan "+golden+" does not exist, run the tests with -warptest.update to create it", ft.fatal)
+	})
+}
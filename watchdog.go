@@ -0,0 +1,116 @@
+package warp
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// StallListener is implemented by the EventListeners notified of the
+// functions stalled waiting for their inputs, see WithWatchdog.
+type StallListener interface {
+	// OnFunctionStalled is called once per run for a function that has
+	// waited for its inputs longer than the watchdog threshold.
+	OnFunctionStalled(ctx context.Context, ev StallEvent)
+}
+
+// StallEvent describes a function of a run stalled waiting for its inputs.
+type StallEvent struct {
+	// Name refers to the function, as in reports.
+	Name string
+	// Waiting holds the inputs of the function whose producers have not
+	// returned yet.
+	Waiting []reflect.Type
+	// Duration is the time the function has waited since the run started.
+	Duration time.Duration
+}
+
+// WithWatchdog watches the runs of the engine for the functions that wait for
+// their inputs longer than threshold, and notifies the registered listeners
+// implementing StallListener of each of them, with the inputs it is waiting
+// for. It tells why a run hangs until its context deadline. Deferred
+// functions only run once forced by a Lazy parameter, so they are not
+// watched until forced.
+func WithWatchdog(threshold time.Duration) Option {
+	return func(c *config) {
+		c.watchdog = threshold
+	}
+}
+
+// watch reports the functions of the run stalled longer than threshold until
+// the returned func is called.
+func (rs *runState) watch(threshold time.Duration) (stop func()) {
+	if threshold <= 0 || !rs.listeners.stallListened() {
+		return func() {}
+	}
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		started := time.Now()
+		reported := make([]bool, len(rs.schedule.funcs))
+		ticker := time.NewTicker(max(threshold/4, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			waited := time.Since(started)
+			if waited < threshold {
+				continue
+			}
+			for i, fn := range rs.schedule.funcs {
+				if reported[i] {
+					continue
+				}
+				if waiting := rs.waiting(i); len(waiting) > 0 {
+					reported[i] = true
+					rs.listeners.functionStalled(rs.ctx, StallEvent{Name: fn.name, Waiting: waiting, Duration: waited})
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// waiting returns the inputs of the function at position i whose producers
+// have not returned yet, none if the function started or is deferred and not
+// forced.
+func (rs *runState) waiting(i int) []reflect.Type {
+	s := rs.schedule
+	if rs.started[i].Load() || (s.deferred[i] && !rs.forced[i].Load()) {
+		return nil
+	}
+	var out []reflect.Type
+	for _, in := range s.funcs[i].ins {
+		if in.signal == -1 || in.lazy {
+			continue
+		}
+		if p := s.producer[in.signal]; p != -1 && !rs.resolved[p].Load() {
+			out = append(out, in.key)
+		}
+	}
+	return out
+}
+
+func (ls listeners) stallListened() bool {
+	for _, l := range ls {
+		if _, ok := l.(StallListener); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (ls listeners) functionStalled(ctx context.Context, ev StallEvent) {
+	for _, l := range ls {
+		if l, ok := l.(StallListener); ok {
+			l.OnFunctionStalled(ctx, ev)
+		}
+	}
+}
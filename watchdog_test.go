@@ -0,0 +1,78 @@
+package warp_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+type stallListener struct {
+	NopListener
+	mu     sync.Mutex
+	events []StallEvent
+}
+
+func (l *stallListener) OnFunctionStalled(_ context.Context, ev StallEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, ev)
+}
+
+func Test_WithWatchdog(t *testing.T) {
+	type (
+		in  string
+		a   string
+		b   string
+		out string
+	)
+
+	t.Run("should report the functions stalled waiting for their inputs", func(t *testing.T) {
+		t.Parallel()
+		l := &stallListener{}
+		ngn, err := Initialize(
+			func(i in) a { time.Sleep(100 * time.Millisecond); return a(i) },
+			func(i in) b { return b(i) },
+			func(a a, b b) out { return out(a) + out(b) },
+			WithWatchdog(20*time.Millisecond),
+			WithListener(l),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[out](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if assert.Len(t, l.events, 1) {
+			assert.Contains(t, l.events[0].Name, "Test_WithWatchdog")
+			assert.Equal(t, []reflect.Type{reflect.TypeOf(a(""))}, l.events[0].Waiting)
+			assert.GreaterOrEqual(t, l.events[0].Duration, 20*time.Millisecond)
+		}
+	})
+
+	t.Run("should not report the runs finishing within the threshold", func(t *testing.T) {
+		t.Parallel()
+		l := &stallListener{}
+		ngn, err := Initialize(
+			func(i in) a { return a(i) },
+			func(a a) out { return out(a) },
+			WithWatchdog(time.Second),
+			WithListener(l),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Run[out](context.Background(), ngn, in("<in>"))
+		assert.NoError(t, err)
+		assert.Empty(t, l.events)
+	})
+}
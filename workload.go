@@ -0,0 +1,106 @@
+package warp
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"slices"
+)
+
+// CPUBound marks fn as bound by computation. The CPU bound functions of all
+// the runs of the engine execute on a shared pool of runtime.GOMAXPROCS(0)
+// slots by default, so that wide graphs of compute heavy functions do not
+// oversubscribe the processors, while the IO bound functions keep executing
+// on their own pool, see IOBound and WithWorkloadPools.
+//
+// A function waiting for a slot starts by priority and critical path, see
+// Prioritized and Estimated, and the time it waits is reported in
+// FunctionEvent.Delay. It stops waiting once its run is cancelled, and is not
+// called. Functions that are not marked are not pooled.
+func CPUBound(fn any) *Provider {
+	p := asProvider(fn)
+	p.workload = cpuBound
+	return p
+}
+
+// IOBound marks fn as bound by IO, such as a call to a database or to a
+// remote service. The IO bound functions of all the runs of the engine
+// execute on a shared pool, of 16 slots per runtime.GOMAXPROCS(0) by default,
+// so that a burst of slow network calls neither starves the CPU bound
+// functions nor overwhelms the services called. See CPUBound.
+func IOBound(fn any) *Provider {
+	p := asProvider(fn)
+	p.workload = ioBound
+	return p
+}
+
+// WithWorkloadPools sets the number of slots of the pools executing the
+// CPUBound and the IOBound functions. A pool of zero or negative size keeps
+// its default size.
+func WithWorkloadPools(cpu, io int) Option {
+	return func(c *config) {
+		c.cpuPool, c.ioPool = cpu, io
+	}
+}
+
+// workload is the resource bounding the execution of a function.
+type workload uint8
+
+const (
+	unbound workload = iota
+	cpuBound
+	ioBound
+)
+
+// workloadPools are the gates of the CPUBound and IOBound functions, shared
+// by the runs of an engine. A nil workloadPools pools no function.
+type workloadPools struct {
+	cpu, io *gate
+}
+
+// newWorkloadPools returns the pools of the engine, nil if no provider is
+// marked.
+func newWorkloadPools(providers []*Provider, cfg *config) *workloadPools {
+	var marked bool
+	for _, p := range providers {
+		marked = marked || p.workload != unbound
+	}
+	if !marked {
+		return nil
+	}
+	cpu, io := runtime.GOMAXPROCS(0), 16*runtime.GOMAXPROCS(0)
+	if cfg.cpuPool > 0 {
+		cpu = cfg.cpuPool
+	}
+	if cfg.ioPool > 0 {
+		io = cfg.ioPool
+	}
+	return &workloadPools{cpu: newGate(cpu), io: newGate(io)}
+}
+
+// gate returns the gate of the functions of workload w, nil if they are not
+// pooled.
+func (p *workloadPools) gate(w workload) *gate {
+	switch {
+	case p == nil:
+		return nil
+	case w == cpuBound:
+		return p.cpu
+	case w == ioBound:
+		return p.io
+	}
+	return nil
+}
+
+// validateWorkloads returns an error per pooled provider running other runs
+// with the Invoker: waiting for them while holding its slot, it could starve
+// them of the slots of the pool.
+func validateWorkloads(providers []*Provider) []error {
+	var errs []error
+	for _, p := range providers {
+		if p.workload != unbound && slices.ContainsFunc(inputs(reflect.TypeOf(p.fn)), isType[Invoker]) {
+			errs = append(errs, wrapProviderValidationError(p, errors.New("function with an Invoker parameter can not be CPU or IO bound")))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,154 @@
+package warp_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/dezlitz/warp"
+)
+
+func Test_WorkloadPools(t *testing.T) {
+	type (
+		in  int
+		a   int
+		b   int
+		c   int
+		d   int
+		out int
+	)
+
+	t.Run("should bound the CPU bound functions of concurrent runs", func(t *testing.T) {
+		t.Parallel()
+		var active, peak atomic.Int32
+		work := func() {
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			active.Add(-1)
+		}
+		ngn, err := Initialize(
+			CPUBound(func(i in) a { work(); return a(i) }),
+			CPUBound(func(i in) b { work(); return b(i) }),
+			CPUBound(func(i in) c { work(); return c(i) }),
+			func(a a, b b, c c) out { return out(int(a) + int(b) + int(c)) },
+			WithWorkloadPools(2, 0),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := Run[out](context.Background(), ngn, in(1))
+				assert.NoError(t, err)
+				assert.Equal(t, out(3), res)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("should not make IO bound functions wait for the CPU pool", func(t *testing.T) {
+		t.Parallel()
+		fetched := make(chan struct{})
+		ngn, err := Initialize(
+			CPUBound(func(i in) (a, error) {
+				select {
+				case <-fetched:
+					return a(i), nil
+				case <-time.After(time.Second):
+					return 0, assert.AnError
+				}
+			}),
+			IOBound(func(i in) b { close(fetched); return b(i) }),
+			func(a a, b b) out { return out(int(a) + int(b)) },
+			WithWorkloadPools(1, 1),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Run[out](context.Background(), ngn, in(1))
+		assert.NoError(t, err)
+		assert.Equal(t, out(2), res)
+	})
+
+	t.Run("should free the slot of a function waiting for a lazy input", func(t *testing.T) {
+		t.Parallel()
+		ngn, err := Initialize(
+			CPUBound(func(i in) d { return d(i) }),
+			CPUBound(func(ctx context.Context, d Lazy[d]) (out, error) {
+				v, err := d.Get(ctx)
+				return out(v), err
+			}),
+			WithWorkloadPools(1, 0),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		res, err := Run[out](ctx, ngn, in(1))
+		assert.NoError(t, err)
+		assert.Equal(t, out(1), res)
+	})
+
+	t.Run("should stop waiting for a pool slot once the run is cancelled", func(t *testing.T) {
+		t.Parallel()
+		entered, release := make(chan struct{}), make(chan struct{})
+		ngn, err := Initialize(
+			IOBound(func(i in) out {
+				if i == 0 {
+					close(entered)
+					<-release
+				}
+				return out(i)
+			}),
+			WithWorkloadPools(0, 1),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := Run[out](context.Background(), ngn, in(0))
+			assert.NoError(t, err)
+		}()
+		<-entered
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = Run[out](ctx, ngn, in(1))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		<-done
+		res, err := Run[out](context.Background(), ngn, in(2))
+		assert.NoError(t, err)
+		assert.Equal(t, out(2), res)
+	})
+
+	t.Run("should return an error if a pooled function has an Invoker parameter", func(t *testing.T) {
+		t.Parallel()
+		_, err := Initialize(
+			IOBound(func(ctx context.Context, inv Invoker, i in) out { return out(i) }),
+		)
+		assertErrContains(t, err, "function with an Invoker parameter can not be CPU or IO bound")
+	})
+}